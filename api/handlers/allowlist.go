@@ -0,0 +1,105 @@
+package handlers
+
+import (
+	services "core/services/system"
+	"core/types"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func allowlistParams(c *fiber.Ctx) (types.AllowlistParams, error) {
+	var params types.AllowlistParams
+	if err := c.BodyParser(&params); err != nil {
+		return params, err
+	}
+
+	params.Context = c.Context()
+	bindOwner(c, &params.MerchantID, &params.DomainID)
+	return params, nil
+}
+
+func HandleAllowlistAdd(s *services.AllowlistService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := allowlistParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateAdd(); err != nil {
+			return Fail(c, err)
+		}
+
+		entry, err := s.Add(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(entry)
+	}
+}
+
+func HandleAllowlistRemove(s *services.AllowlistService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := allowlistParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateRemove(); err != nil {
+			return Fail(c, err)
+		}
+
+		if err := s.Remove(params); err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{"success": true})
+	}
+}
+
+func HandleAllowlistList(s *services.AllowlistService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := allowlistParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateList(); err != nil {
+			return Fail(c, err)
+		}
+
+		entries, cursor, err := s.List(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success":     true,
+			"addresses":   entries,
+			"next_cursor": cursor,
+		})
+	}
+}
+
+func HandleAllowlistSetEnabled(s *services.AllowlistService, enabled bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := allowlistParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateMerchant(); err != nil {
+			return Fail(c, err)
+		}
+
+		merchant, err := s.SetEnabled(params, enabled)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success":              true,
+			"withdrawal_allowlist": merchant.WithdrawalAllowlist,
+		})
+	}
+}
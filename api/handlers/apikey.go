@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"core/constants"
+	services "core/services/system"
+	"core/types"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type APIKeyHandler struct {
+	service *services.APIKeyService
+}
+
+func NewAPIKeyHandler(service *services.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service: service}
+}
+
+// apiKeyParams binds the body and takes the merchant from the session.
+func apiKeyParams(c *fiber.Ctx) (types.APIKeyParams, error) {
+	var params types.APIKeyParams
+	if err := c.BodyParser(&params); err != nil {
+		return params, err
+	}
+
+	merchantID, _ := c.Locals(constants.LOCAL_MERCHANT_ID).(uuid.UUID)
+	params.Context = c.Context()
+	params.MerchantID = &merchantID
+	return params, nil
+}
+
+// bindOwner overrides the merchant (and domain) of the body with the ones of
+// the authenticated session or API key, so callers only act on their own data.
+func bindOwner(c *fiber.Ctx, merchantID, domainID **string) {
+	if id, ok := c.Locals(constants.LOCAL_MERCHANT_ID).(uuid.UUID); ok {
+		merchant := id.String()
+		*merchantID = &merchant
+	}
+	if id, ok := c.Locals(constants.LOCAL_DOMAIN_ID).(uuid.UUID); ok {
+		domain := id.String()
+		*domainID = &domain
+	}
+}
+
+func HandleAPIKeyCreate(s *services.APIKeyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := apiKeyParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateCreate(); err != nil {
+			return Fail(c, err)
+		}
+
+		key, err := s.Create(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(key)
+	}
+}
+
+func HandleAPIKeyList(s *services.APIKeyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := apiKeyParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateList(); err != nil {
+			return Fail(c, err)
+		}
+
+		keys, err := s.List(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success":  true,
+			"api_keys": keys,
+		})
+	}
+}
+
+func HandleAPIKeyRotate(s *services.APIKeyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := apiKeyParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateKey(); err != nil {
+			return Fail(c, err)
+		}
+
+		key, err := s.Rotate(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(key)
+	}
+}
+
+func HandleAPIKeyRevoke(s *services.APIKeyService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := apiKeyParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateKey(); err != nil {
+			return Fail(c, err)
+		}
+
+		if err := s.Revoke(params); err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+		})
+	}
+}
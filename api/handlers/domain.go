@@ -8,39 +8,148 @@ import (
 )
 
 type DomainHandler struct {
-	service *services.WalletService
+	service *services.DomainService
 }
 
-func NewDomainHandler(service *services.WalletService) *DomainHandler {
+func NewDomainHandler(service *services.DomainService) *DomainHandler {
 	return &DomainHandler{service: service}
 }
 
-func HandleDomainCreate(s *services.WalletService) fiber.Handler {
+// domainParams binds the body and takes the merchant (and the domain, for
+// API key requests) from the authenticated caller.
+func domainParams(c *fiber.Ctx) (types.DomainParams, error) {
+	var params types.DomainParams
+	if err := c.BodyParser(&params); err != nil {
+		return params, err
+	}
+
+	params.Context = c.Context()
+	params.MerchantID = nil
+	bindOwner(c, &params.MerchantID, &params.DomainID)
+	return params, nil
+}
+
+func HandleDomainCreate(s *services.DomainService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var params types.WalletParams
-		if err := c.BodyParser(&params); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid JSON body: " + err.Error(),
-			})
+		params, err := domainParams(c)
+		if err != nil {
+			return FailBody(c, err)
 		}
 
-		params.Context = c.Context()
-
 		if err := params.Validate(); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"errors":  err,
-			})
+			return Fail(c, err)
 		}
 
-		wallet, err := s.Create(params)
+		domain, err := s.Create(params)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to create merchant: " + err.Error(),
-			})
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(domain)
+	}
+}
+
+func HandleDomainFetch(s *services.DomainService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := domainParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateLookup(); err != nil {
+			return Fail(c, err)
+		}
+
+		domain, err := s.Fetch(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(domain)
+	}
+}
+
+func HandleDomainList(s *services.DomainService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := domainParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateList(); err != nil {
+			return Fail(c, err)
+		}
+
+		domains, cursor, err := s.List(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success":     true,
+			"domains":     domains,
+			"next_cursor": cursor,
+		})
+	}
+}
+
+func HandleDomainUpdate(s *services.DomainService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := domainParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateUpdate(); err != nil {
+			return Fail(c, err)
+		}
+
+		domain, err := s.Update(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(domain)
+	}
+}
+
+func HandleDomainSetEnabled(s *services.DomainService, enabled bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := domainParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateLookup(); err != nil {
+			return Fail(c, err)
+		}
+
+		domain, err := s.SetEnabled(params, enabled)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(domain)
+	}
+}
+
+func HandleDomainDelete(s *services.DomainService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := domainParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateLookup(); err != nil {
+			return Fail(c, err)
+		}
+
+		if err := s.Delete(params); err != nil {
+			return Fail(c, err)
 		}
 
-		return c.Status(fiber.StatusCreated).JSON(wallet)
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+		})
 	}
 }
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"core/blockchain"
+	"core/pricing"
+	"core/repositories"
+	"core/types"
+	"errors"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type errorMapping struct {
+	target  error
+	status  int
+	code    types.ErrorCode
+	message string
+}
+
+// errorMappings translates repository and chain sentinels into API errors.
+// Order matters: the first match wins.
+var errorMappings = []errorMapping{
+	{repositories.ErrMerchantNotFound, fiber.StatusNotFound, types.ErrCodeMerchantNotFound, "merchant not found"},
+	{repositories.ErrMerchantExists, fiber.StatusConflict, types.ErrCodeMerchantExists, "merchant with this email already exists"},
+	{repositories.ErrInvalidCredentials, fiber.StatusUnauthorized, types.ErrCodeInvalidLogin, "invalid email or password"},
+	{repositories.ErrAccountLocked, fiber.StatusTooManyRequests, types.ErrCodeAccountLocked, "too many failed attempts, try again later"},
+	{repositories.ErrSessionNotFound, fiber.StatusUnauthorized, types.ErrCodeUnauthorized, "unauthorized"},
+	{repositories.ErrSessionExpired, fiber.StatusUnauthorized, types.ErrCodeSessionExpired, "session expired"},
+	{repositories.ErrInvalidResetToken, fiber.StatusBadRequest, types.ErrCodeInvalidToken, "invalid or expired reset token"},
+	{repositories.ErrTwoFactorRequired, fiber.StatusUnauthorized, types.ErrCodeTwoFactorNeeded, "two-factor code required"},
+	{repositories.ErrInvalidTwoFactor, fiber.StatusUnauthorized, types.ErrCodeInvalidTwoFactor, "invalid two-factor code"},
+	{repositories.ErrTwoFactorEnabled, fiber.StatusConflict, types.ErrCodeTwoFactorState, "two-factor authentication already enabled"},
+	{repositories.ErrTwoFactorNotEnabled, fiber.StatusConflict, types.ErrCodeTwoFactorState, "two-factor authentication not enabled"},
+	{repositories.ErrTwoFactorLocked, fiber.StatusTooManyRequests, types.ErrCodeTwoFactorLocked, "too many invalid two-factor codes, try again later"},
+	{repositories.ErrDomainNotFound, fiber.StatusNotFound, types.ErrCodeDomainNotFound, "domain not found"},
+	{repositories.ErrDomainExists, fiber.StatusConflict, types.ErrCodeDomainExists, "domain with this webhook already exists"},
+	{repositories.ErrDomainDisabled, fiber.StatusForbidden, types.ErrCodeDomainDisabled, "domain disabled"},
+	{repositories.ErrAPIKeyNotFound, fiber.StatusNotFound, types.ErrCodeAPIKeyNotFound, "api key not found"},
+	{repositories.ErrAPIKeyRevoked, fiber.StatusUnauthorized, types.ErrCodeAPIKeyInactive, "api key revoked or expired"},
+	{repositories.ErrInvalidAPIKey, fiber.StatusUnauthorized, types.ErrCodeUnauthorized, "invalid api key"},
+	{repositories.ErrAPIKeyEnvironment, fiber.StatusForbidden, types.ErrCodeAPIKeyEnvironment, "api key environment not accepted by this gateway"},
+	{repositories.ErrForbiddenScope, fiber.StatusForbidden, types.ErrCodeForbiddenScope, "api key scope does not allow this command"},
+	{repositories.ErrWalletNotFound, fiber.StatusNotFound, types.ErrCodeWalletNotFound, "wallet not found"},
+	{repositories.ErrPaymentRequestNotFound, fiber.StatusNotFound, types.ErrCodePaymentNotFound, "payment request not found"},
+	{repositories.ErrPaymentRequestExists, fiber.StatusConflict, types.ErrCodePaymentExists, "payment request with this order id already exists"},
+	{repositories.ErrWithdrawalNotFound, fiber.StatusNotFound, types.ErrCodeWithdrawalNotFound, "withdrawal not found"},
+	{repositories.ErrWithdrawalState, fiber.StatusConflict, types.ErrCodeWithdrawalState, "withdrawal cannot move to this state"},
+	{repositories.ErrTxNotReplaceable, fiber.StatusConflict, types.ErrCodeTxNotReplaceable, "no pending transaction that can be replaced"},
+	{repositories.ErrIdempotencyConflict, fiber.StatusConflict, types.ErrCodeIdempotency, "idempotency key reused with different parameters"},
+	{repositories.ErrDestinationNotAllowed, fiber.StatusForbidden, types.ErrCodeDestinationDenied, "destination is not allowlisted or still cooling off"},
+	{repositories.ErrAllowlistNotFound, fiber.StatusNotFound, types.ErrCodeAllowlistNotFound, "allowlisted address not found"},
+	{repositories.ErrAllowlistExists, fiber.StatusConflict, types.ErrCodeAllowlistExists, "address already allowlisted"},
+	{repositories.ErrInsufficientBalance, fiber.StatusUnprocessableEntity, types.ErrCodeInsufficientFunds, "insufficient balance"},
+	{repositories.ErrFeesUnsupported, fiber.StatusBadRequest, types.ErrCodeFeesUnsupported, "fee estimates not supported on this chain"},
+	{pricing.ErrPriceUnavailable, fiber.StatusServiceUnavailable, types.ErrCodePriceUnavailable, "price unavailable for this asset and currency"},
+	{blockchain.ErrChainNotFound, fiber.StatusServiceUnavailable, types.ErrCodeChainUnavailable, "chain unavailable"},
+	{blockchain.ErrChainUnavailable, fiber.StatusServiceUnavailable, types.ErrCodeChainUnavailable, "chain unavailable"},
+}
+
+func ToAPIError(err error) *types.APIError {
+	if apiErr, ok := types.AsAPIError(err); ok {
+		return apiErr
+	}
+
+	for _, m := range errorMappings {
+		if errors.Is(err, m.target) {
+			return types.NewAPIError(m.status, m.code, m.message)
+		}
+	}
+
+	// Unknown errors may carry driver or chain internals, keep them in the log only.
+	log.Printf("[api] internal error: %v\n", err)
+	return types.NewAPIError(fiber.StatusInternalServerError, types.ErrCodeInternal, "internal server error")
+}
+
+func Fail(c *fiber.Ctx, err error) error {
+	apiErr := ToAPIError(err)
+	return c.Status(apiErr.Status).JSON(apiErr.Response())
+}
+
+func FailBody(c *fiber.Ctx, err error) error {
+	apiErr := types.NewAPIError(fiber.StatusBadRequest, types.ErrCodeInvalidRequest, "invalid JSON body: "+err.Error())
+	return c.Status(apiErr.Status).JSON(apiErr.Response())
+}
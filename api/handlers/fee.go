@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	services "core/services/system"
+	"core/types"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func HandleFeeEstimate(s *services.FeeService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var params types.FeeParams
+		if err := c.BodyParser(&params); err != nil {
+			return FailBody(c, err)
+		}
+		params.Context = c.Context()
+
+		if err := params.ValidateEstimate(); err != nil {
+			return Fail(c, err)
+		}
+
+		quote, err := s.Estimate(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+			"fees":    quote,
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	services "core/services/system"
+	"core/types"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ledgerParams(c *fiber.Ctx) (types.LedgerParams, error) {
+	var params types.LedgerParams
+	if err := c.BodyParser(&params); err != nil {
+		return params, err
+	}
+
+	params.Context = c.Context()
+	bindOwner(c, &params.MerchantID, &params.DomainID)
+	return params, nil
+}
+
+func HandleLedgerBalances(s *services.LedgerService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := ledgerParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateBalances(); err != nil {
+			return Fail(c, err)
+		}
+
+		balances, err := s.Balances(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success":  true,
+			"balances": balances,
+		})
+	}
+}
+
+func HandleLedgerStatement(s *services.LedgerService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := ledgerParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateStatement(); err != nil {
+			return Fail(c, err)
+		}
+
+		postings, cursor, err := s.Statement(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success":     true,
+			"postings":    postings,
+			"next_cursor": cursor,
+		})
+	}
+}
@@ -1,11 +1,15 @@
 package handlers
 
 import (
+	"core/constants"
+	"core/models"
+	"core/repositories"
 	services "core/services/system"
 	"core/types"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
 )
 
 type MerchantHandler struct {
@@ -20,10 +24,7 @@ func HandleMerchantCreate(s *services.MerchantService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var params types.MerchantParams
 		if err := c.BodyParser(&params); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid JSON body: " + err.Error(),
-			})
+			return FailBody(c, err)
 		}
 
 		params.Context = c.Context()
@@ -40,114 +41,162 @@ func HandleMerchantCreate(s *services.MerchantService) fiber.Handler {
 			emailRepeat := strings.ToLower(*params.EmailRepeat)
 			params.EmailRepeat = &emailRepeat
 		}
-		if params.Password != nil {
-			password := strings.ToLower(*params.Password)
-			params.Password = &password
-		}
-		if params.PasswordRepeat != nil {
-			passwordRepeat := strings.ToLower(*params.PasswordRepeat)
-			params.PasswordRepeat = &passwordRepeat
-		}
 
 		if err := params.Validate(); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"errors":  err,
-			})
+			return Fail(c, err)
 		}
 
 		merchant, err := s.Create(params)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to create merchant: " + err.Error(),
-			})
+			return Fail(c, err)
 		}
 
 		return c.Status(fiber.StatusCreated).JSON(merchant)
 	}
 }
 
+// merchantParams binds the body and takes the merchant from the session, so
+// a merchant only reads or deletes its own account.
+func merchantParams(c *fiber.Ctx) (types.MerchantParams, error) {
+	var params types.MerchantParams
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&params); err != nil {
+			return params, err
+		}
+	}
+
+	merchantID, _ := c.Locals(constants.LOCAL_MERCHANT_ID).(uuid.UUID)
+	params.Context = c.Context()
+	params.ID = &merchantID
+	return params, nil
+}
+
+// ownMerchantByEmail is the session's merchant when params names its email,
+// any other email is reported as not found.
+func ownMerchantByEmail(s *services.MerchantService, params types.MerchantParams) (*models.Merchant, error) {
+	if err := params.ValidateEmail(); err != nil {
+		return nil, err
+	}
+	merchant, err := s.FindByID(params)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.EqualFold(merchant.Email, *params.Email) {
+		return nil, repositories.ErrMerchantNotFound
+	}
+	return merchant, nil
+}
+
+// HandleMerchantFindById must run behind middleware.Session; it returns the
+// session's merchant.
 func HandleMerchantFindById(s *services.MerchantService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var params types.MerchantParams
-		if err := c.BodyParser(&params); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid JSON body: " + err.Error(),
-			})
+		params, err := merchantParams(c)
+		if err != nil {
+			return FailBody(c, err)
 		}
 
-		params.Context = c.Context()
 		if err := params.ValidateID(); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"errors":  err,
-			})
+			return Fail(c, err)
 		}
 
 		merchant, err := s.FindByID(params)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to find merchant by id: " + err.Error(),
-			})
+			return Fail(c, err)
 		}
 
-		return c.Status(fiber.StatusCreated).JSON(merchant)
+		return c.Status(fiber.StatusOK).JSON(merchant)
 	}
 }
 
+// HandleMerchantFindByEmail must run behind middleware.Session; only the
+// session's own email is found.
 func HandleMerchantFindByEmail(s *services.MerchantService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var params types.MerchantParams
-		if err := c.BodyParser(&params); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid JSON body: " + err.Error(),
-			})
+		params, err := merchantParams(c)
+		if err != nil {
+			return FailBody(c, err)
 		}
 
-		params.Context = c.Context()
-		if err := params.ValidateEmail(); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"errors":  err,
-			})
+		merchant, err := ownMerchantByEmail(s, params)
+		if err != nil {
+			return Fail(c, err)
 		}
 
-		merchant, err := s.FindByID(params)
+		return c.Status(fiber.StatusOK).JSON(merchant)
+	}
+}
+
+// HandleMerchantDeleteById must run behind middleware.Session; it deletes
+// the session's merchant.
+func HandleMerchantDeleteById(s *services.MerchantService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := merchantParams(c)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to find merchant by id: " + err.Error(),
-			})
+			return FailBody(c, err)
 		}
 
-		return c.Status(fiber.StatusCreated).JSON(merchant)
+		if err := params.ValidateID(); err != nil {
+			return Fail(c, err)
+		}
+
+		err = s.DeleteByID(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+		})
 	}
 }
 
-func HandleMerchantDeleteById(s *services.MerchantService) fiber.Handler {
+// HandleMerchantDeleteByEmail must run behind middleware.Session; only the
+// session's own email is deleted.
+func HandleMerchantDeleteByEmail(s *services.MerchantService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := merchantParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if _, err := ownMerchantByEmail(s, params); err != nil {
+			return Fail(c, err)
+		}
+
+		err = s.DeleteByID(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+		})
+	}
+}
+
+// HandleMerchantPasswordChange must run behind middleware.Session; the
+// merchant is taken from the session, never from the body.
+func HandleMerchantPasswordChange(s *services.MerchantService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var params types.MerchantParams
 		if err := c.BodyParser(&params); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid JSON body: " + err.Error(),
-			})
+			return FailBody(c, err)
 		}
 
+		merchantID, _ := c.Locals(constants.LOCAL_MERCHANT_ID).(uuid.UUID)
+		sessionID, _ := c.Locals(constants.LOCAL_SESSION_ID).(uuid.UUID)
+
 		params.Context = c.Context()
-		if err := params.ValidateID(); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"errors":  err,
-			})
+		params.ID = &merchantID
+		params.SessionID = &sessionID
+
+		if err := params.ValidatePasswordChange(); err != nil {
+			return Fail(c, err)
 		}
 
-		err := s.DeleteByID(params)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to delete merchant by id: " + err.Error(),
-			})
+		if err := s.ChangePassword(params); err != nil {
+			return Fail(c, err)
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -156,60 +205,46 @@ func HandleMerchantDeleteById(s *services.MerchantService) fiber.Handler {
 	}
 }
 
-func HandleMerchantDeleteByEmail(s *services.MerchantService) fiber.Handler {
+func HandleMerchantPasswordReset(s *services.MerchantService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var params types.MerchantParams
 		if err := c.BodyParser(&params); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid JSON body: " + err.Error(),
-			})
+			return FailBody(c, err)
 		}
 
 		params.Context = c.Context()
 		if err := params.ValidateEmail(); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"errors":  err,
-			})
+			return Fail(c, err)
 		}
 
-		err := s.DeleteByEmail(params)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to delete merchant by email: " + err.Error(),
-			})
+		if err := s.RequestPasswordReset(params); err != nil {
+			return Fail(c, err)
 		}
 
-		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
 			"success": true,
 		})
 	}
 }
 
-func HandleMerchantFetch(s *services.MerchantService) fiber.Handler {
+func HandleMerchantPasswordResetConfirm(s *services.MerchantService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		var params types.MerchantParams
 		if err := c.BodyParser(&params); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid JSON body: " + err.Error(),
-			})
+			return FailBody(c, err)
 		}
 
 		params.Context = c.Context()
+		if err := params.ValidatePasswordReset(); err != nil {
+			return Fail(c, err)
+		}
 
-		merchants, cursor, err := s.Fetch(params)
-		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to fetch merchants: " + err.Error(),
-			})
+		if err := s.ResetPassword(params); err != nil {
+			return Fail(c, err)
 		}
 
 		return c.Status(fiber.StatusOK).JSON(fiber.Map{
-			"success":   false,
-			"merchants": merchants,
-			"cursor":    cursor,
+			"success": true,
 		})
 	}
 }
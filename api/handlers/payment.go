@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	services "core/services/system"
+	"core/types"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// paymentParams binds the body and takes the merchant (and the domain, for
+// API key requests) from the authenticated caller.
+func paymentParams(c *fiber.Ctx) (types.PaymentRequestParams, error) {
+	var params types.PaymentRequestParams
+	if err := c.BodyParser(&params); err != nil {
+		return params, err
+	}
+
+	params.Context = c.Context()
+	params.MerchantID = nil
+	bindOwner(c, &params.MerchantID, &params.DomainID)
+	return params, nil
+}
+
+func HandlePaymentRequestCreate(s *services.PaymentRequestService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := paymentParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateCreate(); err != nil {
+			return Fail(c, err)
+		}
+
+		request, err := s.Create(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(request)
+	}
+}
+
+func HandlePaymentRequestFetch(s *services.PaymentRequestService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := paymentParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateLookup(); err != nil {
+			return Fail(c, err)
+		}
+
+		request, err := s.Fetch(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(request)
+	}
+}
+
+func HandlePaymentRequestList(s *services.PaymentRequestService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := paymentParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateList(); err != nil {
+			return Fail(c, err)
+		}
+
+		requests, cursor, err := s.List(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success":          true,
+			"payment_requests": requests,
+			"next_cursor":      cursor,
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"core/constants"
+	services "core/services/system"
+	"core/types"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type SessionHandler struct {
+	service *services.SessionService
+}
+
+func NewSessionHandler(service *services.SessionService) *SessionHandler {
+	return &SessionHandler{service: service}
+}
+
+func HandleMerchantLogin(s *services.SessionService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var params types.SessionParams
+		if err := c.BodyParser(&params); err != nil {
+			return FailBody(c, err)
+		}
+
+		params.Context = c.Context()
+		params.IP = c.IP()
+		params.UserAgent = c.Get(fiber.HeaderUserAgent)
+
+		if err := params.ValidateLogin(); err != nil {
+			return Fail(c, err)
+		}
+
+		tokens, err := s.Login(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(tokens)
+	}
+}
+
+func HandleMerchantTokenRefresh(s *services.SessionService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		var params types.SessionParams
+		if err := c.BodyParser(&params); err != nil {
+			return FailBody(c, err)
+		}
+
+		params.Context = c.Context()
+		if err := params.ValidateRefresh(); err != nil {
+			return Fail(c, err)
+		}
+
+		tokens, err := s.Refresh(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(tokens)
+	}
+}
+
+// HandleMerchantLogout must run behind middleware.Session.
+func HandleMerchantLogout(s *services.SessionService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		sessionID, _ := c.Locals(constants.LOCAL_SESSION_ID).(uuid.UUID)
+
+		params := types.SessionParams{
+			Context:   c.Context(),
+			SessionID: &sessionID,
+		}
+
+		if err := s.Logout(params); err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"core/constants"
+	services "core/services/system"
+	"core/types"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+type TwoFactorHandler struct {
+	service *services.TwoFactorService
+}
+
+func NewTwoFactorHandler(service *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{service: service}
+}
+
+// twoFactorParams binds the body and takes the merchant from the session.
+func twoFactorParams(c *fiber.Ctx) (types.TwoFactorParams, error) {
+	var params types.TwoFactorParams
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&params); err != nil {
+			return params, err
+		}
+	}
+
+	merchantID, _ := c.Locals(constants.LOCAL_MERCHANT_ID).(uuid.UUID)
+	params.Context = c.Context()
+	params.MerchantID = &merchantID
+	return params, nil
+}
+
+func HandleTwoFactorEnroll(s *services.TwoFactorService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := twoFactorParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		enrollment, err := s.Enroll(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(enrollment)
+	}
+}
+
+func HandleTwoFactorConfirm(s *services.TwoFactorService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := twoFactorParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.Validate(); err != nil {
+			return Fail(c, err)
+		}
+
+		codes, err := s.Confirm(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(codes)
+	}
+}
+
+func HandleTwoFactorDisable(s *services.TwoFactorService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := twoFactorParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.Validate(); err != nil {
+			return Fail(c, err)
+		}
+
+		if err := s.Disable(params); err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success": true,
+		})
+	}
+}
+
+func HandleTwoFactorRecoveryRegenerate(s *services.TwoFactorService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := twoFactorParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.Validate(); err != nil {
+			return Fail(c, err)
+		}
+
+		codes, err := s.RegenerateRecoveryCodes(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(codes)
+	}
+}
@@ -15,32 +15,124 @@ func NewWalletHandler(service *services.WalletService) *WalletHandler {
 	return &WalletHandler{service: service}
 }
 
+// walletParams binds the body and takes the merchant (and the domain, for
+// API key requests) from the authenticated caller.
+func walletParams(c *fiber.Ctx) (types.WalletParams, error) {
+	var params types.WalletParams
+	if err := c.BodyParser(&params); err != nil {
+		return params, err
+	}
+
+	params.Context = c.Context()
+	params.MerchantId = nil
+	bindOwner(c, &params.MerchantId, &params.DomainId)
+	return params, nil
+}
+
 func HandleWalletCreate(s *services.WalletService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		var params types.WalletParams
-		if err := c.BodyParser(&params); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"error":   "Invalid JSON body: " + err.Error(),
-			})
+		params, err := walletParams(c)
+		if err != nil {
+			return FailBody(c, err)
 		}
 
-		params.Context = c.Context()
-
 		if err := params.Validate(); err != nil {
-			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-				"success": false,
-				"errors":  err,
-			})
+			return Fail(c, err)
 		}
 
 		wallet, err := s.Create(params)
 		if err != nil {
-			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error": "Failed to create merchant: " + err.Error(),
-			})
+			return Fail(c, err)
 		}
 
 		return c.Status(fiber.StatusCreated).JSON(wallet)
 	}
 }
+
+func HandleWalletDeriveAddresses(s *services.WalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := walletParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateDerive(); err != nil {
+			return Fail(c, err)
+		}
+
+		wallet, err := s.DeriveAddresses(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(wallet)
+	}
+}
+
+func HandleWalletList(s *services.WalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := walletParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateList(); err != nil {
+			return Fail(c, err)
+		}
+
+		wallets, cursor, err := s.List(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success":     true,
+			"wallets":     wallets,
+			"next_cursor": cursor,
+		})
+	}
+}
+
+func HandleWalletFindByAddress(s *services.WalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := walletParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateAddressLookup(); err != nil {
+			return Fail(c, err)
+		}
+
+		wallet, err := s.FindByAddress(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(wallet)
+	}
+}
+
+func HandleWalletHistory(s *services.WalletService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := walletParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateHistory(); err != nil {
+			return Fail(c, err)
+		}
+
+		transactions, cursor, err := s.History(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success":      true,
+			"transactions": transactions,
+			"next_cursor":  cursor,
+		})
+	}
+}
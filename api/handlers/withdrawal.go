@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"core/constants"
+	services "core/services/system"
+	"core/types"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+// withdrawalParams binds the body, the owner and the caller recorded in the
+// withdrawal audit log.
+func withdrawalParams(c *fiber.Ctx) (types.WithdrawalParams, error) {
+	var params types.WithdrawalParams
+	if err := c.BodyParser(&params); err != nil {
+		return params, err
+	}
+
+	params.Context = c.Context()
+	bindOwner(c, &params.MerchantID, &params.DomainID)
+
+	if id, ok := c.Locals(constants.LOCAL_API_KEY_ID).(uuid.UUID); ok {
+		params.Actor, params.ActorID = constants.ACTOR_API_KEY, &id
+	} else if id, ok := c.Locals(constants.LOCAL_SESSION_ID).(uuid.UUID); ok {
+		params.Actor, params.ActorID = constants.ACTOR_SESSION, &id
+	}
+	return params, nil
+}
+
+func HandleWithdrawalCreate(s *services.WithdrawalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := withdrawalParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateCreate(); err != nil {
+			return Fail(c, err)
+		}
+
+		withdrawal, err := s.Create(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusCreated).JSON(withdrawal)
+	}
+}
+
+func HandleWithdrawalFetch(s *services.WithdrawalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := withdrawalParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateLookup(); err != nil {
+			return Fail(c, err)
+		}
+
+		withdrawal, err := s.Fetch(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(withdrawal)
+	}
+}
+
+func HandleWithdrawalList(s *services.WithdrawalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := withdrawalParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateList(); err != nil {
+			return Fail(c, err)
+		}
+
+		withdrawals, cursor, err := s.List(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"success":     true,
+			"withdrawals": withdrawals,
+			"next_cursor": cursor,
+		})
+	}
+}
+
+func HandleWithdrawalApprove(s *services.WithdrawalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := withdrawalParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateLookup(); err != nil {
+			return Fail(c, err)
+		}
+
+		withdrawal, err := s.Approve(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(withdrawal)
+	}
+}
+
+func HandleWithdrawalReject(s *services.WithdrawalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := withdrawalParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateReject(); err != nil {
+			return Fail(c, err)
+		}
+
+		withdrawal, err := s.Reject(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(withdrawal)
+	}
+}
+
+func HandleWithdrawalSpeedUp(s *services.WithdrawalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := withdrawalParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateLookup(); err != nil {
+			return Fail(c, err)
+		}
+
+		withdrawal, err := s.SpeedUp(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(withdrawal)
+	}
+}
+
+func HandleWithdrawalCancel(s *services.WithdrawalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := withdrawalParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateLookup(); err != nil {
+			return Fail(c, err)
+		}
+
+		withdrawal, err := s.Cancel(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(withdrawal)
+	}
+}
+
+func HandleWithdrawalThresholdSet(s *services.WithdrawalService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		params, err := withdrawalParams(c)
+		if err != nil {
+			return FailBody(c, err)
+		}
+
+		if err := params.ValidateThreshold(); err != nil {
+			return Fail(c, err)
+		}
+
+		threshold, err := s.SetThreshold(params)
+		if err != nil {
+			return Fail(c, err)
+		}
+
+		return c.Status(fiber.StatusOK).JSON(threshold)
+	}
+}
@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"core/api/handlers"
+	"core/constants"
+	"core/repositories"
+	services "core/services/system"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+// APIKey authenticates a domain API key and rejects the request when the key
+// scopes do not grant the routed action. The merchant, domain and key ids are
+// exposed through c.Locals.
+func APIKey(s *services.APIKeyService) Middleware {
+	return func(next fiber.Handler) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			apiKey := c.Get(HeaderAPIKey)
+			if apiKey == "" {
+				return handlers.Fail(c, repositories.ErrInvalidAPIKey)
+			}
+
+			key, err := s.Authenticate(c.Context(), apiKey)
+			if err != nil {
+				return handlers.Fail(c, err)
+			}
+
+			action, _ := c.Locals(constants.LOCAL_ACTION).(constants.CommandType)
+			if !constants.ScopesAllow(key.Scopes, action) {
+				return handlers.Fail(c, repositories.ErrForbiddenScope)
+			}
+
+			c.Locals(constants.LOCAL_MERCHANT_ID, key.MerchantID)
+			c.Locals(constants.LOCAL_DOMAIN_ID, key.DomainID)
+			c.Locals(constants.LOCAL_API_KEY_ID, key.ID)
+
+			return next(c)
+		}
+	}
+}
+
+// SessionOrAPIKey runs the API key middleware when the request carries an
+// X-API-Key header and the session middleware otherwise.
+func SessionOrAPIKey(session, apiKey Middleware) Middleware {
+	return func(next fiber.Handler) fiber.Handler {
+		viaSession := session(next)
+		viaAPIKey := apiKey(next)
+		return func(c *fiber.Ctx) error {
+			if c.Get(HeaderAPIKey) != "" {
+				return viaAPIKey(c)
+			}
+			return viaSession(c)
+		}
+	}
+}
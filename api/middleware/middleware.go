@@ -3,3 +3,13 @@ package middleware
 import "github.com/gofiber/fiber/v2"
 
 type Middleware func(fiber.Handler) fiber.Handler
+
+// Chain runs mws in order, the first one outermost, as one middleware.
+func Chain(mws ...Middleware) Middleware {
+	return func(next fiber.Handler) fiber.Handler {
+		for i := len(mws) - 1; i >= 0; i-- {
+			next = mws[i](next)
+		}
+		return next
+	}
+}
@@ -0,0 +1,46 @@
+package middleware
+
+import (
+	"core/api/handlers"
+	"core/constants"
+	"core/repositories"
+	services "core/services/system"
+	"core/types"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Session authenticates the bearer access token of a merchant session and
+// exposes the merchant and session ids through c.Locals.
+func Session(s *services.SessionService) Middleware {
+	return func(next fiber.Handler) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			token := bearerToken(c)
+			if token == "" {
+				return handlers.Fail(c, repositories.ErrSessionNotFound)
+			}
+
+			session, err := s.Authenticate(types.SessionParams{
+				Context:     c.Context(),
+				AccessToken: &token,
+			})
+			if err != nil {
+				return handlers.Fail(c, err)
+			}
+
+			c.Locals(constants.LOCAL_MERCHANT_ID, session.MerchantID)
+			c.Locals(constants.LOCAL_SESSION_ID, session.ID)
+
+			return next(c)
+		}
+	}
+}
+
+func bearerToken(c *fiber.Ctx) string {
+	header := c.Get(fiber.HeaderAuthorization)
+	if len(header) > 7 && strings.EqualFold(header[:7], "bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
@@ -0,0 +1,39 @@
+package middleware
+
+import (
+	"core/api/handlers"
+	"core/constants"
+	"core/repositories"
+	services "core/services/system"
+	"core/types"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+)
+
+const HeaderOTP = "X-OTP"
+
+// TwoFactor guards sensitive commands. It must run after Session and expects
+// the TOTP or recovery code in the X-OTP header when the merchant enrolled 2FA.
+func TwoFactor(s *services.TwoFactorService) Middleware {
+	return func(next fiber.Handler) fiber.Handler {
+		return func(c *fiber.Ctx) error {
+			merchantID, ok := c.Locals(constants.LOCAL_MERCHANT_ID).(uuid.UUID)
+			if !ok {
+				return handlers.Fail(c, repositories.ErrSessionNotFound)
+			}
+
+			code := c.Get(HeaderOTP)
+			err := s.Verify(types.TwoFactorParams{
+				Context:    c.Context(),
+				MerchantID: &merchantID,
+				Code:       &code,
+			})
+			if err != nil {
+				return handlers.Fail(c, err)
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -3,16 +3,31 @@ package router
 import (
 	middleware "core/api/middleware"
 	"core/constants"
+	"core/types"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
 type Route struct {
+	Action      constants.CommandType
 	Handler     fiber.Handler
 	Middlewares []middleware.Middleware
 }
 
+// Chain wraps the handler with the route middlewares, first registered runs first.
+// The action is exposed through c.Locals so middlewares can authorize it.
+func (r Route) Chain() fiber.Handler {
+	handler := r.Handler
+	for i := len(r.Middlewares) - 1; i >= 0; i-- {
+		handler = r.Middlewares[i](handler)
+	}
+	return func(c *fiber.Ctx) error {
+		c.Locals(constants.LOCAL_ACTION, r.Action)
+		return handler(c)
+	}
+}
+
 type ActionRouter struct {
 	routes       map[constants.CommandType]Route
 	defaultRoute fiber.Handler
@@ -29,6 +44,7 @@ func NewActionRouter(db *gorm.DB) *ActionRouter {
 // Register
 func (ar *ActionRouter) Register(action constants.CommandType, handler fiber.Handler, mws ...middleware.Middleware) {
 	ar.routes[action] = Route{
+		Action:      action,
 		Handler:     handler,
 		Middlewares: mws,
 	}
@@ -46,16 +62,11 @@ func (ar *ActionRouter) Resolve(c *fiber.Ctx) error {
 		if ar.defaultRoute != nil {
 			return ar.defaultRoute(c)
 		}
-		return c.Status(fiber.StatusBadRequest).SendString("Unknown action")
-	}
-
-	// Middleware zincirini uygula
-	handler := route.Handler
-	for i := len(route.Middlewares) - 1; i >= 0; i-- {
-		handler = route.Middlewares[i](handler)
+		apiErr := types.NewAPIError(fiber.StatusBadRequest, types.ErrCodeUnknownAction, "unknown action")
+		return c.Status(apiErr.Status).JSON(apiErr.Response())
 	}
 
-	return handler(c)
+	return route.Chain()(c)
 }
 func (ar *ActionRouter) GetHandler(action string) (Route, bool) {
 	route, ok := ar.routes[constants.CommandType(action)]
@@ -3,13 +3,16 @@ package routes
 
 import (
 	"core/api/handlers"
+	"core/api/middleware"
 	"core/api/router"
 	configurations "core/application/configuration"
 	"core/asset"
 	"core/blockchain"
 	"core/constants"
+	"core/pricing"
 	"core/repositories"
 	services "core/services/system"
+	"core/types"
 	"fmt"
 	"strings"
 
@@ -29,15 +32,35 @@ type Router struct {
 	db            *gorm.DB
 	blockchains   *blockchain.ChainFactory
 	assetRegistry *asset.Registry
+	priceOracle   *pricing.Oracle
+	nonces        *blockchain.NonceManager
 
-	MerchantRepo    *repositories.MerchantRepo
-	DomainRepo      *repositories.DomainRepo
-	WalletRepo      *repositories.WalletRepo
-	ChainStateRepo  *repositories.ChainStateRepo
-	TransactionRepo *repositories.TransactionRepo
-	MerchantService *services.MerchantService
-	WalletService   *services.WalletService
-	DomainService   *services.DomainService
+	MerchantRepo      *repositories.MerchantRepo
+	DomainRepo        *repositories.DomainRepo
+	WalletRepo        *repositories.WalletRepo
+	ChainStateRepo    *repositories.ChainStateRepo
+	TransactionRepo   *repositories.TransactionRepo
+	LedgerRepo        *repositories.LedgerRepo
+	WithdrawalRepo    *repositories.WithdrawalRepo
+	AllowlistRepo     *repositories.AllowlistRepo
+	FeeRepo           *repositories.FeeRepo
+	SweepRepo         *repositories.SweepRepo
+	OutgoingTxRepo    *repositories.OutgoingTxRepo
+	SessionRepo       *repositories.SessionRepo
+	TwoFactorRepo     *repositories.TwoFactorRepo
+	APIKeyRepo        *repositories.APIKeyRepo
+	PaymentRepo       *repositories.PaymentRequestRepo
+	MerchantService   *services.MerchantService
+	WalletService     *services.WalletService
+	DomainService     *services.DomainService
+	SessionService    *services.SessionService
+	TwoFactorService  *services.TwoFactorService
+	APIKeyService     *services.APIKeyService
+	PaymentService    *services.PaymentRequestService
+	LedgerService     *services.LedgerService
+	WithdrawalService *services.WithdrawalService
+	AllowlistService  *services.AllowlistService
+	FeeService        *services.FeeService
 }
 
 func NewRouter(db *gorm.DB) *Router {
@@ -50,17 +73,21 @@ func NewRouter(db *gorm.DB) *Router {
 		}),
 		assetRegistry: configurations.NewAssetRegistry(),
 		blockchains:   configurations.NewChainFactory(),
+		priceOracle:   configurations.NewPriceOracle(),
+		nonces:        blockchain.NewNonceManager(db),
 	}
+	r.blockchains.SetNonceManager(r.nonces)
 
 	r.fiber.Use(cors.New(cors.Config{
 		AllowOrigins:     "*",
 		AllowCredentials: false,
 		AllowMethods:     "POST,GET,OPTIONS,PUT,DELETE",
-		AllowHeaders:     "Accept,Authorization,authorization,Content-Type,Content-Length,X-CSRF-Token,Token,session,Origin,Host,Connection,Accept-Encoding,Accept-Language,X-Requested-With",
+		AllowHeaders:     "Accept,Authorization,authorization,Content-Type,Content-Length,X-CSRF-Token,Token,session,Origin,Host,Connection,Accept-Encoding,Accept-Language,X-Requested-With,X-API-Key,X-OTP",
 	}))
 
 	r.ChainStateRepo = repositories.NewChainStateRepo(r.db)
-	r.TransactionRepo = repositories.NewTransactionRepo(r.db)
+	r.LedgerRepo = repositories.NewLedgerRepo(r.db)
+	r.TransactionRepo = repositories.NewTransactionRepo(r.db, r.LedgerRepo)
 	r.MerchantRepo = repositories.NewMerchantRepo(r.db, r.blockchains)
 	r.MerchantService = services.NewMerchantService(r.MerchantRepo)
 
@@ -70,22 +97,105 @@ func NewRouter(db *gorm.DB) *Router {
 	r.WalletRepo = repositories.NewWalletRepo(r.DomainRepo)
 	r.WalletService = services.NewWalletService(r.WalletRepo)
 
-	r.fiber.Post(constants.CMD_MERCHANT_CREATE.String(), handlers.HandleMerchantCreate(r.MerchantService))
-	r.fiber.Post(constants.CMD_MERCHANT_FETCH.String(), handlers.HandleMerchantFetch(r.MerchantService))
+	r.SessionRepo = repositories.NewSessionRepo(r.MerchantRepo)
+	r.SessionService = services.NewSessionService(r.SessionRepo)
+	session := middleware.Session(r.SessionService)
 
-	r.fiber.Post(constants.CMD_MERCHANT_CREATE.String(), handlers.HandleWalletCreate(r.WalletService))
+	r.TwoFactorRepo = repositories.NewTwoFactorRepo(r.MerchantRepo)
+	r.TwoFactorService = services.NewTwoFactorService(r.TwoFactorRepo)
+	twoFactor := middleware.TwoFactor(r.TwoFactorService)
 
-	r.fiber.Post(constants.CMD_MERCHANT_FETCH_BY_ID.String(), handlers.HandleMerchantFindById(r.MerchantService))
-	r.fiber.Post(constants.CMD_MERCHANT_FETCH_BY_EMAIL.String(), handlers.HandleMerchantFindByEmail(r.MerchantService))
+	r.register(constants.CMD_MERCHANT_CREATE, handlers.HandleMerchantCreate(r.MerchantService))
 
-	r.fiber.Post(constants.CMD_MERCHANT_FETCH_BY_ID.String(), handlers.HandleMerchantFindById(r.MerchantService))
-	r.fiber.Post(constants.CMD_MERCHANT_FETCH_BY_EMAIL.String(), handlers.HandleMerchantFindByEmail(r.MerchantService))
+	r.register(constants.CMD_MERCHANT_LOGIN, handlers.HandleMerchantLogin(r.SessionService))
+	r.register(constants.CMD_MERCHANT_TOKEN_REFRESH, handlers.HandleMerchantTokenRefresh(r.SessionService))
+	r.register(constants.CMD_MERCHANT_LOGOUT, handlers.HandleMerchantLogout(r.SessionService), session)
+	r.register(constants.CMD_MERCHANT_PASSWORD_CHANGE, handlers.HandleMerchantPasswordChange(r.MerchantService), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_PASSWORD_RESET, handlers.HandleMerchantPasswordReset(r.MerchantService))
+	r.register(constants.CMD_MERCHANT_PASSWORD_RESET_CONFIRM, handlers.HandleMerchantPasswordResetConfirm(r.MerchantService))
 
-	r.fiber.Post(constants.CMD_MERCHANT_DELETE_BY_ID.String(), handlers.HandleMerchantDeleteById(r.MerchantService))
-	r.fiber.Post(constants.CMD_MERCHANT_DELETE_BY_EMAIL.String(), handlers.HandleMerchantDeleteByEmail(r.MerchantService))
+	r.register(constants.CMD_MERCHANT_2FA_ENROLL, handlers.HandleTwoFactorEnroll(r.TwoFactorService), session)
+	r.register(constants.CMD_MERCHANT_2FA_CONFIRM, handlers.HandleTwoFactorConfirm(r.TwoFactorService), session)
+	r.register(constants.CMD_MERCHANT_2FA_DISABLE, handlers.HandleTwoFactorDisable(r.TwoFactorService), session)
+	r.register(constants.CMD_MERCHANT_2FA_RECOVERY_CODES, handlers.HandleTwoFactorRecoveryRegenerate(r.TwoFactorService), session)
 
-	r.fiber.Post(constants.CMD_MERCHANT_WALLET_CREATE.String(), handlers.HandleWalletCreate(r.WalletService))
+	r.register(constants.CMD_MERCHANT_FETCH_BY_ID, handlers.HandleMerchantFindById(r.MerchantService), session)
+	r.register(constants.CMD_MERCHANT_FETCH_BY_EMAIL, handlers.HandleMerchantFindByEmail(r.MerchantService), session)
 
+	r.register(constants.CMD_MERCHANT_DELETE_BY_ID, handlers.HandleMerchantDeleteById(r.MerchantService), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_DELETE_BY_EMAIL, handlers.HandleMerchantDeleteByEmail(r.MerchantService), session, twoFactor)
+
+	r.APIKeyRepo = repositories.NewAPIKeyRepo(r.DomainRepo)
+	r.APIKeyService = services.NewAPIKeyService(r.APIKeyRepo)
+	apiKey := middleware.APIKey(r.APIKeyService)
+
+	r.register(constants.CMD_MERCHANT_APIKEY_CREATE, handlers.HandleAPIKeyCreate(r.APIKeyService), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_APIKEY_LIST, handlers.HandleAPIKeyList(r.APIKeyService), session)
+	r.register(constants.CMD_MERCHANT_APIKEY_ROTATE, handlers.HandleAPIKeyRotate(r.APIKeyService), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_APIKEY_REVOKE, handlers.HandleAPIKeyRevoke(r.APIKeyService), session, twoFactor)
+
+	sessionOrAPIKey := middleware.SessionOrAPIKey(session, apiKey)
+
+	r.register(constants.CMD_MERCHANT_DOMAIN_CREATE, handlers.HandleDomainCreate(r.DomainService), session)
+	r.register(constants.CMD_MERCHANT_DOMAIN_FETCH, handlers.HandleDomainFetch(r.DomainService), sessionOrAPIKey)
+	r.register(constants.CMD_MERCHANT_DOMAIN_LIST, handlers.HandleDomainList(r.DomainService), session)
+	r.register(constants.CMD_MERCHANT_DOMAIN_UPDATE, handlers.HandleDomainUpdate(r.DomainService), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_DOMAIN_ENABLE, handlers.HandleDomainSetEnabled(r.DomainService, true), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_DOMAIN_DISABLE, handlers.HandleDomainSetEnabled(r.DomainService, false), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_DOMAIN_DELETE, handlers.HandleDomainDelete(r.DomainService), session, twoFactor)
+
+	r.register(constants.CMD_MERCHANT_WALLET_CREATE, handlers.HandleWalletCreate(r.WalletService), apiKey)
+	r.register(constants.CMD_MERCHANT_WALLET_DERIVE, handlers.HandleWalletDeriveAddresses(r.WalletService), sessionOrAPIKey)
+	r.register(constants.CMD_MERCHANT_WALLET_LIST, handlers.HandleWalletList(r.WalletService), sessionOrAPIKey)
+	r.register(constants.CMD_MERCHANT_WALLET_FETCH_BY_ADDR, handlers.HandleWalletFindByAddress(r.WalletService), sessionOrAPIKey)
+	r.register(constants.CMD_MERCHANT_WALLET_HISTORY, handlers.HandleWalletHistory(r.WalletService), sessionOrAPIKey)
+
+	r.PaymentRepo = repositories.NewPaymentRequestRepo(r.WalletRepo, r.assetRegistry, r.priceOracle)
+	r.PaymentService = services.NewPaymentRequestService(r.PaymentRepo)
+
+	r.register(constants.CMD_MERCHANT_PAYMENT_CREATE, handlers.HandlePaymentRequestCreate(r.PaymentService), sessionOrAPIKey)
+	r.register(constants.CMD_MERCHANT_PAYMENT_FETCH, handlers.HandlePaymentRequestFetch(r.PaymentService), sessionOrAPIKey)
+	r.register(constants.CMD_MERCHANT_PAYMENT_LIST, handlers.HandlePaymentRequestList(r.PaymentService), sessionOrAPIKey)
+
+	r.LedgerService = services.NewLedgerService(r.LedgerRepo)
+
+	r.register(constants.CMD_MERCHANT_BALANCE_LIST, handlers.HandleLedgerBalances(r.LedgerService), sessionOrAPIKey)
+	r.register(constants.CMD_MERCHANT_LEDGER_STATEMENT, handlers.HandleLedgerStatement(r.LedgerService), sessionOrAPIKey)
+
+	r.WithdrawalRepo = repositories.NewWithdrawalRepo(r.MerchantRepo, r.LedgerRepo, r.assetRegistry)
+	r.WithdrawalService = services.NewWithdrawalService(r.WithdrawalRepo)
+
+	// A session moves funds only with the second factor, like every other
+	// withdrawal decision. API keys are scoped and carry no OTP.
+	sessionTwoFactorOrAPIKey := middleware.SessionOrAPIKey(middleware.Chain(session, twoFactor), apiKey)
+
+	r.register(constants.CMD_WITHDRAW, handlers.HandleWithdrawalCreate(r.WithdrawalService), sessionTwoFactorOrAPIKey)
+	r.register(constants.CMD_MERCHANT_WITHDRAWAL_FETCH, handlers.HandleWithdrawalFetch(r.WithdrawalService), sessionOrAPIKey)
+	r.register(constants.CMD_MERCHANT_WITHDRAWAL_LIST, handlers.HandleWithdrawalList(r.WithdrawalService), sessionOrAPIKey)
+	r.register(constants.CMD_MERCHANT_WITHDRAWAL_APPROVE, handlers.HandleWithdrawalApprove(r.WithdrawalService), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_WITHDRAWAL_REJECT, handlers.HandleWithdrawalReject(r.WithdrawalService), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_WITHDRAWAL_THRESHOLD, handlers.HandleWithdrawalThresholdSet(r.WithdrawalService), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_WITHDRAWAL_SPEED_UP, handlers.HandleWithdrawalSpeedUp(r.WithdrawalService), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_WITHDRAWAL_CANCEL, handlers.HandleWithdrawalCancel(r.WithdrawalService), session, twoFactor)
+
+	r.AllowlistRepo = repositories.NewAllowlistRepo(r.MerchantRepo)
+	r.AllowlistService = services.NewAllowlistService(r.AllowlistRepo)
+
+	r.register(constants.CMD_MERCHANT_ALLOWLIST_ADD, handlers.HandleAllowlistAdd(r.AllowlistService), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_ALLOWLIST_REMOVE, handlers.HandleAllowlistRemove(r.AllowlistService), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_ALLOWLIST_LIST, handlers.HandleAllowlistList(r.AllowlistService), sessionOrAPIKey)
+	r.register(constants.CMD_MERCHANT_ALLOWLIST_ENABLE, handlers.HandleAllowlistSetEnabled(r.AllowlistService, true), session, twoFactor)
+	r.register(constants.CMD_MERCHANT_ALLOWLIST_DISABLE, handlers.HandleAllowlistSetEnabled(r.AllowlistService, false), session, twoFactor)
+
+	r.FeeRepo = repositories.NewFeeRepo(r.MerchantRepo)
+	r.FeeService = services.NewFeeService(r.FeeRepo)
+
+	r.register(constants.CMD_FEES, handlers.HandleFeeEstimate(r.FeeService), sessionOrAPIKey)
+
+	r.SweepRepo = repositories.NewSweepRepo(r.MerchantRepo, r.LedgerRepo, r.assetRegistry)
+	r.OutgoingTxRepo = repositories.NewOutgoingTxRepo(r.MerchantRepo)
+
+	r.fiber.All("/packet", r.handlePacket)
 	r.fiber.All("/docs/*", swagger.HandlerDefault)     // http://localhost:3000/docs/index.html
 	GenerateFakeActionRoutesSwagger(r.fiber, r.action) // Fake routes
 	return r
@@ -95,7 +205,7 @@ func (r *Router) handlePacket(c *fiber.Ctx) error {
 	var action string
 	c.Set("Access-Control-Allow-Origin", "*")
 	c.Set("Access-Control-Allow-Methods", "GET,POST,PUT,DELETE,OPTIONS")
-	c.Set("Access-Control-Allow-Headers", "Accept,Authorization,Content-Type,X-CSRF-Token,Token,session,Origin,Host,Connection,Accept-Encoding,Accept-Language,X-Requested-With")
+	c.Set("Access-Control-Allow-Headers", "Accept,Authorization,Content-Type,X-CSRF-Token,Token,session,Origin,Host,Connection,Accept-Encoding,Accept-Language,X-Requested-With,X-API-Key,X-OTP")
 	if c.Method() == fiber.MethodOptions {
 		return c.SendStatus(fiber.StatusNoContent)
 	}
@@ -114,7 +224,7 @@ func (r *Router) handlePacket(c *fiber.Ctx) error {
 				Action string `json:"action"`
 			}
 			if err := c.BodyParser(&packet); err != nil {
-				return c.Status(fiber.StatusBadRequest).SendString("invalid JSON body")
+				return handlers.FailBody(c, err)
 			}
 			action = packet.Action
 		} else {
@@ -131,16 +241,18 @@ func (r *Router) handlePacket(c *fiber.Ctx) error {
 
 	route, ok := r.action.GetHandler(action)
 	if !ok {
-		return c.Status(fiber.StatusBadRequest).SendString("Unknown action")
+		apiErr := types.NewAPIError(fiber.StatusBadRequest, types.ErrCodeUnknownAction, "unknown action")
+		return c.Status(apiErr.Status).JSON(apiErr.Response())
 	}
 
-	// Middleware zincirini uygula (Fiber middleware olduğu varsayımıyla)
-	handler := route.Handler
-	for i := len(route.Middlewares) - 1; i >= 0; i-- {
-		handler = route.Middlewares[i](handler)
-	}
+	return route.Chain()(c)
+}
 
-	return handler(c)
+// register exposes the command both on the action router (/packet) and as its own POST path.
+func (r *Router) register(cmd constants.CommandType, handler fiber.Handler, mws ...middleware.Middleware) {
+	r.action.Register(cmd, handler, mws...)
+	route, _ := r.action.GetHandler(cmd.String())
+	r.fiber.Post(cmd.String(), route.Chain())
 }
 
 func (r *Router) GetFiber() *fiber.App {
@@ -151,6 +263,14 @@ func (r *Router) Blockchains() *blockchain.ChainFactory {
 	return r.blockchains
 }
 
+func (r *Router) Nonces() *blockchain.NonceManager {
+	return r.nonces
+}
+
 func (r *Router) AssetRegistry() *asset.Registry {
 	return r.assetRegistry
 }
+
+func (r *Router) PriceOracle() *pricing.Oracle {
+	return r.priceOracle
+}
@@ -6,6 +6,14 @@ import (
 )
 
 func NewChainFactory() *blockchain.ChainFactory {
+	factory := newChains()
+	ApplyFeePolicies(factory)
+	ApplySigner(factory)
+	return factory
+}
+
+// newChains registers the chains without a signer or fee policies.
+func newChains() *blockchain.ChainFactory {
 	factory := blockchain.NewChainFactory()
 
 	factory.RegisterChain("solana", chains.NewSolanaChain())
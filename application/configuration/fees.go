@@ -0,0 +1,54 @@
+package application
+
+import (
+	"core/blockchain"
+	"core/helpers"
+	"core/types"
+	"encoding/json"
+	"log"
+	"os"
+)
+
+// ApplyFeePolicies reads the JSON file named by FEE_CONFIG_FILE, a list
+// like [{"chain": "ethereum", "legacy": false, "max_fee": "300"}] with
+// max_fee in gwei, and sets the fee policy of each chain. Chains not listed
+// keep their defaults.
+func ApplyFeePolicies(factory *blockchain.ChainFactory) {
+	path := os.Getenv("FEE_CONFIG_FILE")
+	if path == "" {
+		return
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("[fees] policies not loaded: %v\n", err)
+		return
+	}
+
+	var configs []types.FeeConfig
+	if err := json.Unmarshal(data, &configs); err != nil {
+		log.Printf("[fees] policies not loaded: %v\n", err)
+		return
+	}
+
+	for _, config := range configs {
+		if err := config.Validate(); err != nil {
+			log.Printf("[fees] skipping policy %s: %v\n", config.Chain, err)
+			continue
+		}
+		chain, err := factory.GetChain(config.Chain)
+		if err != nil {
+			log.Printf("[fees] skipping policy %s: %v\n", config.Chain, err)
+			continue
+		}
+
+		policy := blockchain.FeePolicy{Legacy: config.Legacy}
+		if config.MaxFee != "" {
+			if policy.MaxFee, err = helpers.ParseUnits(config.MaxFee, 9); err != nil {
+				log.Printf("[fees] skipping policy %s: invalid max_fee: %v\n", config.Chain, err)
+				continue
+			}
+		}
+		chain.SetFeePolicy(policy)
+	}
+}
@@ -0,0 +1,136 @@
+package application
+
+import (
+	"bytes"
+	"context"
+	"core/blockchain"
+	"core/constants"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// RunKeystoreCommand manages the seed keystore at KEYSTORE_FILE:
+//
+//	create  seals a new seed and prints its mnemonic once, for an offline backup
+//	import  seals the seed of a mnemonic or hex seed read from stdin
+//	export  prints the hex seed of the keystore
+//	watch   writes the watch-only keys of the keystore to WATCH_ONLY_KEYS_FILE
+//
+// The passphrase comes from KEYSTORE_PASSWORD_FILE or the terminal.
+func RunKeystoreCommand(command string) error {
+	path := os.Getenv("KEYSTORE_FILE")
+	if path == "" {
+		return errors.New("KEYSTORE_FILE not set")
+	}
+
+	switch command {
+	case "create":
+		mnemonic, err := blockchain.NewMnemonic()
+		if err != nil {
+			return err
+		}
+		seed, err := blockchain.ParseSeed(mnemonic)
+		if err != nil {
+			return err
+		}
+		defer clear(seed)
+		if err := sealKeystore(path, seed); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Keystore written to %s. Write down its mnemonic, it is not shown again:\n", path)
+		fmt.Println(mnemonic)
+		return nil
+
+	case "import":
+		input, err := readSecret("Mnemonic or hex seed: ")
+		if err != nil {
+			return err
+		}
+		defer clear(input)
+		seed, err := blockchain.ParseSeed(string(input))
+		if err != nil {
+			return err
+		}
+		defer clear(seed)
+		if err := sealKeystore(path, seed); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Keystore written to %s\n", path)
+		return nil
+
+	case "export":
+		keystore, err := blockchain.ReadKeystore(path)
+		if err != nil {
+			return err
+		}
+		passphrase, err := readPassphrase("Keystore passphrase: ")
+		if err != nil {
+			return err
+		}
+		defer clear(passphrase)
+		seed, err := keystore.DecryptSeed(passphrase)
+		if err != nil {
+			return err
+		}
+		defer clear(seed)
+		fmt.Println(hex.EncodeToString(seed))
+		return nil
+
+	case "watch":
+		output := os.Getenv("WATCH_ONLY_KEYS_FILE")
+		if output == "" {
+			return errors.New("WATCH_ONLY_KEYS_FILE not set")
+		}
+		signer, err := UnlockSigner()
+		if err != nil {
+			return err
+		}
+		defer signer.Close()
+		keys, err := blockchain.ExportWatchOnly(context.Background(), signer, newChains(),
+			constants.WATCH_ONLY_POOL_ACCOUNTS, constants.WATCH_ONLY_POOL_ADDRESSES)
+		if err != nil {
+			return err
+		}
+		if err := keys.Write(output); err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Watch-only keys written to %s\n", output)
+		return nil
+	}
+	return fmt.Errorf("unknown keystore command %q, expected create, import, export or watch", command)
+}
+
+// sealKeystore writes a new keystore of seed under a passphrase that is
+// asked for twice on the terminal, or read from KEYSTORE_PASSWORD_FILE.
+func sealKeystore(path string, seed []byte) error {
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("%s already exists", path)
+	}
+
+	passphrase, err := readPassphrase("New keystore passphrase: ")
+	if err != nil {
+		return err
+	}
+	defer clear(passphrase)
+	if len(passphrase) < constants.KEYSTORE_PASSPHRASE_MIN {
+		return fmt.Errorf("passphrase shorter than %d characters", constants.KEYSTORE_PASSPHRASE_MIN)
+	}
+	if os.Getenv("KEYSTORE_PASSWORD_FILE") == "" {
+		repeat, err := readSecret("Repeat passphrase: ")
+		if err != nil {
+			return err
+		}
+		defer clear(repeat)
+		if !bytes.Equal(passphrase, repeat) {
+			return errors.New("passphrases do not match")
+		}
+	}
+
+	keystore, err := blockchain.EncryptSeed(seed, passphrase)
+	if err != nil {
+		return err
+	}
+	return keystore.Write(path)
+}
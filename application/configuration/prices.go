@@ -0,0 +1,44 @@
+package application
+
+import (
+	"core/constants"
+	"core/pricing"
+	"log"
+	"os"
+	"strings"
+)
+
+// NewPriceOracle builds the oracle from PRICE_PROVIDERS, a comma separated
+// list tried in order (default "coinbase,coingecko"). PRICE_STATIC_FILE,
+// when set, is consulted before any of them.
+func NewPriceOracle() *pricing.Oracle {
+	var providers []pricing.Provider
+
+	if path := os.Getenv("PRICE_STATIC_FILE"); path != "" {
+		static, err := pricing.LoadStaticProvider(path)
+		if err != nil {
+			log.Printf("[pricing] static prices not loaded: %v\n", err)
+		} else {
+			providers = append(providers, static)
+		}
+	}
+
+	names := os.Getenv("PRICE_PROVIDERS")
+	if names == "" {
+		names = "coinbase,coingecko"
+	}
+
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(name) {
+		case "coinbase":
+			providers = append(providers, pricing.NewCoinbaseProvider())
+		case "coingecko":
+			providers = append(providers, pricing.NewCoinGeckoProvider(os.Getenv("COINGECKO_API_KEY")))
+		case "":
+		default:
+			log.Printf("[pricing] unknown provider %q\n", name)
+		}
+	}
+
+	return pricing.NewOracle(constants.PRICE_QUOTE_TTL, providers...)
+}
@@ -0,0 +1,106 @@
+package application
+
+import (
+	"bufio"
+	"bytes"
+	"core/blockchain"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// ApplySigner unlocks the seed keystore named by KEYSTORE_FILE and has the
+// chains derive their HD wallets from it and sign with it. With
+// WATCH_ONLY_KEYS_FILE set the chains derive addresses from the watch-only
+// keys in that file instead, and cannot sign. Without either the chains
+// have no signer and every HD wallet operation fails.
+func ApplySigner(factory *blockchain.ChainFactory) {
+	if path := os.Getenv("WATCH_ONLY_KEYS_FILE"); path != "" {
+		signer, err := loadWatchOnlySigner(path)
+		if err != nil {
+			log.Printf("[signer] HD wallets unavailable: %v\n", err)
+			return
+		}
+		factory.SetSigner(signer)
+		return
+	}
+
+	signer, err := UnlockSigner()
+	if err != nil {
+		log.Printf("[signer] HD wallets unavailable: %v\n", err)
+		return
+	}
+	factory.SetSigner(signer)
+}
+
+// UnlockSigner opens the keystore named by KEYSTORE_FILE with the
+// passphrase in the key file named by KEYSTORE_PASSWORD_FILE, or asked for
+// on the terminal.
+func UnlockSigner() (*blockchain.LocalSigner, error) {
+	keystore, err := readKeystore()
+	if err != nil {
+		return nil, err
+	}
+	passphrase, err := readPassphrase("Keystore passphrase: ")
+	if err != nil {
+		return nil, err
+	}
+	defer clear(passphrase)
+
+	seed, err := keystore.DecryptSeed(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(seed)
+	return blockchain.NewLocalSigner(seed)
+}
+
+func loadWatchOnlySigner(path string) (*blockchain.WatchOnlySigner, error) {
+	keys, err := blockchain.ReadWatchOnlyKeys(path)
+	if err != nil {
+		return nil, err
+	}
+	return blockchain.NewWatchOnlySigner(*keys)
+}
+
+func readKeystore() (*blockchain.Keystore, error) {
+	path := os.Getenv("KEYSTORE_FILE")
+	if path == "" {
+		return nil, errors.New("KEYSTORE_FILE not set")
+	}
+	return blockchain.ReadKeystore(path)
+}
+
+// readPassphrase reads the key file named by KEYSTORE_PASSWORD_FILE, less
+// a trailing newline, or asks on the terminal.
+func readPassphrase(prompt string) ([]byte, error) {
+	if path := os.Getenv("KEYSTORE_PASSWORD_FILE"); path != "" {
+		passphrase, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		return bytes.TrimRight(passphrase, "\r\n"), nil
+	}
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return nil, errors.New("no KEYSTORE_PASSWORD_FILE and no terminal to ask for the passphrase")
+	}
+	return readSecret(prompt)
+}
+
+// readSecret reads a line from stdin, without echo on a terminal.
+func readSecret(prompt string) ([]byte, error) {
+	fmt.Fprint(os.Stderr, prompt)
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		return secret, err
+	}
+	line, err := bufio.NewReader(os.Stdin).ReadBytes('\n')
+	if err != nil && len(line) == 0 {
+		return nil, err
+	}
+	return bytes.TrimRight(line, "\r\n"), nil
+}
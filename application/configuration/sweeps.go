@@ -0,0 +1,40 @@
+package application
+
+import (
+	"core/types"
+	"encoding/json"
+	"log"
+	"os"
+)
+
+// NewSweepPolicies reads the JSON file named by SWEEP_CONFIG_FILE, a list
+// like [{"chain": "ethereum", "asset": "USDT", "treasury": "0x..", "dust":
+// "5"}]. Without it nothing is swept.
+func NewSweepPolicies() []types.SweepPolicy {
+	path := os.Getenv("SWEEP_CONFIG_FILE")
+	if path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("[sweeper] policies not loaded: %v\n", err)
+		return nil
+	}
+
+	var policies []types.SweepPolicy
+	if err := json.Unmarshal(data, &policies); err != nil {
+		log.Printf("[sweeper] policies not loaded: %v\n", err)
+		return nil
+	}
+
+	valid := policies[:0]
+	for _, policy := range policies {
+		if err := policy.Validate(); err != nil {
+			log.Printf("[sweeper] skipping policy %s/%s: %v\n", policy.Chain, policy.Asset, err)
+			continue
+		}
+		valid = append(valid, policy)
+	}
+	return valid
+}
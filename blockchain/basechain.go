@@ -4,27 +4,134 @@ import (
 	"context"
 	"core/constants"
 	"core/models"
-	"encoding/hex"
 	"errors"
 	"fmt"
-	"os"
-
-	"github.com/okx/go-wallet-sdk/crypto/go-bip32"
-	"github.com/okx/go-wallet-sdk/crypto/go-bip39"
+	"math/big"
 )
 
+// WalletDetails is a wallet of the gateway, whose key the chain's Signer
+// holds at DerivationPath. Only standalone wallets from Create carry their
+// PrivateKey, they are not derived from the gateway's seed.
 type WalletDetails struct {
 	Address        string
 	PrivateKey     string
-	MnemonicPhrase string
+	DerivationPath string
 }
 
 type TransactionResult struct {
 	TxHash  string
 	Success bool
 	Error   error
+	// Fee is the network fee the transaction may spend at most, in base
+	// units of the native coin. Nil when the chain does not report it.
+	Fee *big.Int
+	// Sent is what was broadcast, set by chains that can replace their
+	// transactions while they are pending.
+	Sent *SentTx
+}
+
+// SentTx is a broadcast transaction of an account-based chain, what it
+// takes to replace it with the same nonce. GasPrice is the fee cap of
+// EIP-1559 transactions, whose GasTipCap is set, and the gas price of
+// legacy ones.
+type SentTx struct {
+	From      string
+	To        string
+	Nonce     uint64
+	Value     *big.Int
+	Data      []byte
+	GasLimit  uint64
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+}
+
+// TxInclusion is the block a transaction was mined in.
+type TxInclusion struct {
+	BlockNumber uint64
+	Success     bool
+}
+
+// SweepRequest moves funds of one asset out of a deposit wallet. A nil
+// Token sweeps the native coin, a nil Amount sweeps the whole balance (less
+// the network fee for the native coin).
+type SweepRequest struct {
+	ToAddress string
+	Token     *string
+	Amount    *big.Int
+	// Fee pins the gas of the transaction, e.g. to what a gas top-up paid
+	// for. Nil lets the chain price it when the sweep is sent.
+	Fee *SweepFee
 }
 
+// SweepFee is the gas of a sweep: GasLimit units at GasPrice each, in base
+// units of the native coin. Balance is the native balance of the sender.
+type SweepFee struct {
+	GasLimit uint64
+	GasPrice *big.Int
+	Balance  *big.Int
+}
+
+func (f *SweepFee) Total() *big.Int {
+	return new(big.Int).Mul(f.GasPrice, new(big.Int).SetUint64(f.GasLimit))
+}
+
+// Shortfall is the native amount the sender lacks to pay the fee, zero when
+// it holds enough.
+func (f *SweepFee) Shortfall() *big.Int {
+	shortfall := f.Total()
+	if f.Balance != nil {
+		shortfall.Sub(shortfall, f.Balance)
+	}
+	if shortfall.Sign() < 0 {
+		return new(big.Int)
+	}
+	return shortfall
+}
+
+// FeePolicy is how a chain prices its transactions. Legacy chains only
+// take a gas price. MaxFee caps the fee per gas, nil leaves it uncapped.
+type FeePolicy struct {
+	Legacy bool
+	MaxFee *big.Int
+}
+
+// FeeEstimate is the fee per gas to offer for a strategy, in base units of
+// the native coin. MaxFee and MaxPriorityFee are the EIP-1559 fee cap and
+// tip, BaseFee the base fee of the next block. Legacy estimates carry the
+// gas price in MaxFee only.
+type FeeEstimate struct {
+	Strategy       string
+	Legacy         bool
+	BaseFee        *big.Int
+	MaxPriorityFee *big.Int
+	MaxFee         *big.Int
+}
+
+// Expected is the fee per gas a transaction is likely to pay: the base fee
+// plus the tip, at most MaxFee.
+func (e *FeeEstimate) Expected() *big.Int {
+	if e.Legacy || e.BaseFee == nil || e.MaxPriorityFee == nil {
+		return new(big.Int).Set(e.MaxFee)
+	}
+	expected := new(big.Int).Add(e.BaseFee, e.MaxPriorityFee)
+	if expected.Cmp(e.MaxFee) > 0 {
+		expected.Set(e.MaxFee)
+	}
+	return expected
+}
+
+var (
+	ErrNotImplemented  = errors.New("not implemented")
+	ErrInsufficientGas = errors.New("insufficient native balance to pay the network fee")
+	ErrNothingToSweep  = errors.New("nothing to sweep")
+	ErrNonceUsed       = errors.New("nonce already used by a mined transaction")
+	ErrFeeCapReached   = errors.New("replacement fee above the chain's max fee")
+	// ErrSendUnknown wraps the errors of a transaction that was signed and
+	// may have been broadcast, e.g. an RPC timeout. It must not be retried
+	// or given up on before it is looked up on chain.
+	ErrSendUnknown = errors.New("transaction signed, broadcast unknown")
+)
+
 type Worker interface {
 	Start() error
 	Stop() error
@@ -38,10 +145,22 @@ type Chain interface {
 	RPCs() []string
 	Create(ctx context.Context) (*WalletDetails, error)
 	CreateHDWallet(ctx context.Context, hdAccountId, hdWalletId int) (*WalletDetails, error)
+	DerivationPath(hdAccountId, hdWalletId int) string
 
 	Deposit(ctx context.Context, wallet WalletDetails, amount float64, toAddress string) (*TransactionResult, error)
-	Withdraw(ctx context.Context, wallet WalletDetails, amount float64, toAddress string) (*TransactionResult, error)
-	Sweep(ctx context.Context, wallet WalletDetails) (*TransactionResult, error)
+	// Withdraw pays amount base units of the native coin, or of token, from
+	// the wallet to toAddress. Chains that cannot send return
+	// ErrNotImplemented.
+	Withdraw(ctx context.Context, wallet WalletDetails, amount *big.Int, token *string, toAddress string) (*TransactionResult, error)
+	Sweep(ctx context.Context, wallet WalletDetails, request SweepRequest) (*TransactionResult, error)
+	EstimateSweep(ctx context.Context, fromAddress string, request SweepRequest) (*SweepFee, error)
+	EstimateFees(ctx context.Context, strategy string) (*FeeEstimate, error)
+	SetFeePolicy(policy FeePolicy)
+	BlockNumber(ctx context.Context) (uint64, error)
+	TxInclusion(ctx context.Context, txHash string) (*TxInclusion, error)
+	ReplaceTx(ctx context.Context, wallet WalletDetails, sent SentTx, cancel bool) (*TransactionResult, error)
+	// CanSend is whether Withdraw and Sweep sign and broadcast transfers.
+	CanSend() bool
 	ValidateAddress(address string) bool
 
 	AddWorker(listener Worker) error
@@ -57,6 +176,9 @@ type BaseChain struct {
 	ExplorerURL string
 	RPCHttp     []string
 	WebSockets  []string
+	Fees        FeePolicy
+	Nonces      *NonceManager
+	Signer      Signer
 
 	Workers []Worker
 
@@ -96,50 +218,72 @@ func (b *BaseChain) Deposit(ctx context.Context, wallet WalletDetails, amount fl
 	return nil, errors.New("not implemented")
 }
 
-func (b *BaseChain) Withdraw(ctx context.Context, wallet WalletDetails, amount float64, toAddress string) (*TransactionResult, error) {
-	return nil, errors.New("not implemented")
+func (b *BaseChain) Withdraw(ctx context.Context, wallet WalletDetails, amount *big.Int, token *string, toAddress string) (*TransactionResult, error) {
+	return nil, ErrNotImplemented
 }
 
-func (b *BaseChain) Sweep(ctx context.Context, wallet WalletDetails) (*TransactionResult, error) {
-	return nil, errors.New("not implemented")
+func (b *BaseChain) Sweep(ctx context.Context, wallet WalletDetails, request SweepRequest) (*TransactionResult, error) {
+	return nil, ErrNotImplemented
 }
 
-func (f *BaseChain) GenerateMnemonicPhrase() (string, error) {
-	entropy, err := bip39.NewEntropy(256)
-	if err != nil {
-		return "", err
-	}
-	mnemonic, err := bip39.NewMnemonic(entropy)
-	if !bip39.IsMnemonicValid(mnemonic) {
-		return "", errors.New("Invalid Mnemonic")
-	}
-	return mnemonic, err
+func (b *BaseChain) EstimateSweep(ctx context.Context, fromAddress string, request SweepRequest) (*SweepFee, error) {
+	return nil, ErrNotImplemented
 }
 
-func (f *BaseChain) GetMnemonic() (string, error) {
-	mnemonic := os.Getenv("MNEMONIC_PHRASE")
-	if !bip39.IsMnemonicValid(mnemonic) {
-		return "", errors.New("Invalid Mnemonic")
-	}
-	return mnemonic, nil
+func (b *BaseChain) EstimateFees(ctx context.Context, strategy string) (*FeeEstimate, error) {
+	return nil, ErrNotImplemented
 }
 
-func (f *BaseChain) GetDerivedPath(purpose, coin, account, change, index int) string {
-	return fmt.Sprintf("m/%d'/%d'/%d'/%d/%d", purpose, coin, account, change, index)
+func (b *BaseChain) SetFeePolicy(policy FeePolicy) {
+	b.Fees = policy
 }
 
-func (f *BaseChain) GetDerivedPrivateKey(mnemonic string, hdPath string) (string, error) {
-	seed := bip39.NewSeed(mnemonic, "")
-	rp, err := bip32.NewMasterKey(seed)
-	if err != nil {
-		return "", err
+func (b *BaseChain) SetNonceManager(nonces *NonceManager) {
+	b.Nonces = nonces
+}
+
+func (b *BaseChain) SetSigner(signer Signer) {
+	b.Signer = signer
+}
+
+// PublicKey is the key at path of the chain's signer.
+func (b *BaseChain) PublicKey(ctx context.Context, scheme SignatureScheme, path string) ([]byte, error) {
+	if b.Signer == nil {
+		return nil, ErrNoSigner
 	}
-	c, err := rp.NewChildKeyByPathString(hdPath)
-	if err != nil {
-		return "", err
+	return b.Signer.PublicKey(ctx, scheme, path)
+}
+
+// Sign signs payload with the key at path of the chain's signer.
+func (b *BaseChain) Sign(ctx context.Context, scheme SignatureScheme, path string, payload []byte) ([]byte, error) {
+	if b.Signer == nil {
+		return nil, ErrNoSigner
 	}
-	childPrivateKey := hex.EncodeToString(c.Key)
-	return childPrivateKey, nil
+	return b.Signer.Sign(ctx, scheme, path, payload)
+}
+
+func (b *BaseChain) BlockNumber(ctx context.Context) (uint64, error) {
+	return 0, ErrNotImplemented
+}
+
+// TxInclusion returns nil without an error while the transaction is not
+// mined.
+func (b *BaseChain) TxInclusion(ctx context.Context, txHash string) (*TxInclusion, error) {
+	return nil, ErrNotImplemented
+}
+
+func (b *BaseChain) CanSend() bool {
+	return false
+}
+
+// ReplaceTx sends sent again with the same nonce and higher fees, or with
+// cancel a transfer of nothing to the sender itself in its place.
+func (b *BaseChain) ReplaceTx(ctx context.Context, wallet WalletDetails, sent SentTx, cancel bool) (*TransactionResult, error) {
+	return nil, ErrNotImplemented
+}
+
+func (f *BaseChain) GetDerivedPath(purpose, coin, account, change, index int) string {
+	return fmt.Sprintf("m/%d'/%d'/%d'/%d/%d", purpose, coin, account, change, index)
 }
 
 func (b *BaseChain) AddWorker(listener Worker) error {
@@ -23,17 +23,20 @@ import (
 
 type AvalancheChain struct {
 	blockchain.BaseChain
+	fees *evmFeeOracle
 }
 
 func NewAvalancheChain() *AvalancheChain {
-	return &AvalancheChain{
-		blockchain.BaseChain{
+	chain := &AvalancheChain{
+		BaseChain: blockchain.BaseChain{
 			ID:          constants.Avalanche,
 			ChainName:   "avalanche",
 			ExplorerURL: "https://snowscan.xyz/",
 			RPCHttp:     []string{"https://api.avax.network/ext/bc/C/rpc"},
 			WebSockets:  []string{"wss://avalanche.drpc.org"},
 		}}
+	chain.fees = newEVMFeeOracle(&chain.BaseChain)
+	return chain
 }
 
 func (e *AvalancheChain) Name() string {
@@ -76,16 +79,11 @@ func (s *AvalancheChain) ValidateAddress(address string) bool {
 func (s *AvalancheChain) Create(ctx context.Context) (*blockchain.WalletDetails, error) {
 	fmt.Printf("[%s]: Creating wallet\n", s.Name())
 
-	mnemonic, err := s.BaseChain.GenerateMnemonicPhrase()
-	if err != nil {
-		return nil, err
-	}
-
-	hdPath := s.BaseChain.GetDerivedPath(44, 60, 0, 0, 1)
-	privateKey, err := s.BaseChain.GetDerivedPrivateKey(mnemonic, hdPath)
+	key, err := crypto.GenerateKey()
 	if err != nil {
 		return nil, err
 	}
+	privateKey := hex.EncodeToString(crypto.FromECDSA(key))
 	address, err := s.NewAddress(privateKey)
 	if err != nil {
 		log.Printf("[%s] NewAddress error:%s \n", s.BaseChain.Name(), err.Error())
@@ -97,29 +95,24 @@ func (s *AvalancheChain) Create(ctx context.Context) (*blockchain.WalletDetails,
 	}
 
 	return &blockchain.WalletDetails{
-		Address:        address,
-		PrivateKey:     privateKey,
-		MnemonicPhrase: mnemonic,
+		Address:    address,
+		PrivateKey: privateKey,
 	}, nil
 }
 
+func (s *AvalancheChain) DerivationPath(hdAccountId, hdWalletId int) string {
+	return s.BaseChain.GetDerivedPath(44, 60, int(s.ChainID()), hdAccountId, hdWalletId)
+}
+
 func (s *AvalancheChain) CreateHDWallet(ctx context.Context, hdAccountId, hdWalletId int) (*blockchain.WalletDetails, error) {
 	fmt.Printf("[%s]: Creating HD wallet\n", s.Name())
 
-	mnemonic, err := s.BaseChain.GetMnemonic()
+	hdPath := s.DerivationPath(hdAccountId, hdWalletId)
+	key, err := newEVMKey(ctx, &s.BaseChain, hdPath)
 	if err != nil {
 		return nil, err
 	}
-
-	hdPath := s.BaseChain.GetDerivedPath(44, 60, int(s.ChainID()), hdAccountId, hdWalletId)
-	privateKey, err := s.BaseChain.GetDerivedPrivateKey(mnemonic, hdPath)
-	if err != nil {
-		return nil, err
-	}
-	address, err := s.NewAddress(privateKey)
-	if err != nil {
-		log.Printf("[%s] NewAddress error:%s \n", s.BaseChain.Name(), err.Error())
-	}
+	address := key.address.Hex()
 
 	if !s.ValidateAddress(address) {
 		return nil, errors.New("invalid ethereum address format")
@@ -130,8 +123,7 @@ func (s *AvalancheChain) CreateHDWallet(ctx context.Context, hdAccountId, hdWall
 
 	return &blockchain.WalletDetails{
 		Address:        address,
-		PrivateKey:     privateKey,
-		MnemonicPhrase: mnemonic,
+		DerivationPath: hdPath,
 	}, nil
 }
 
@@ -140,14 +132,42 @@ func (s *AvalancheChain) Deposit(ctx context.Context, wallet blockchain.WalletDe
 	return &blockchain.TransactionResult{TxHash: "DepositTxHash", Success: true}, nil
 }
 
-func (s *AvalancheChain) Withdraw(ctx context.Context, wallet blockchain.WalletDetails, amount float64, toAddress string) (*blockchain.TransactionResult, error) {
-	fmt.Printf("[%s]: Withdrawing %f from %s\n", s.Name(), amount, wallet.Address)
-	return &blockchain.TransactionResult{TxHash: "WithdrawTxHash", Success: true}, nil
+func (s *AvalancheChain) CanSend() bool {
+	return true
+}
+
+// Withdraw sends amount of the native coin, or of the ERC-20 token, from
+// the wallet to toAddress.
+func (s *AvalancheChain) Withdraw(ctx context.Context, wallet blockchain.WalletDetails, amount *big.Int, token *string, toAddress string) (*blockchain.TransactionResult, error) {
+	return evmWithdraw(ctx, s.fees, wallet, amount, token, toAddress)
+}
+
+func (s *AvalancheChain) Sweep(ctx context.Context, wallet blockchain.WalletDetails, request blockchain.SweepRequest) (*blockchain.TransactionResult, error) {
+	return evmSweep(ctx, s.fees, wallet, request)
+}
+
+func (s *AvalancheChain) EstimateSweep(ctx context.Context, fromAddress string, request blockchain.SweepRequest) (*blockchain.SweepFee, error) {
+	return evmEstimateSweep(ctx, s.fees, fromAddress, request)
+}
+
+func (s *AvalancheChain) EstimateFees(ctx context.Context, strategy string) (*blockchain.FeeEstimate, error) {
+	return s.fees.Estimate(ctx, strategy)
+}
+
+func (s *AvalancheChain) PendingNonce(ctx context.Context, address string) (uint64, error) {
+	return evmPendingNonce(ctx, s.RPCs(), address)
+}
+
+func (s *AvalancheChain) BlockNumber(ctx context.Context) (uint64, error) {
+	return evmBlockNumber(ctx, s.RPCs())
+}
+
+func (s *AvalancheChain) TxInclusion(ctx context.Context, txHash string) (*blockchain.TxInclusion, error) {
+	return evmTxInclusion(ctx, s.RPCs(), txHash)
 }
 
-func (s *AvalancheChain) Sweep(ctx context.Context, wallet blockchain.WalletDetails) (*blockchain.TransactionResult, error) {
-	fmt.Printf("[%s]: Sweeping wallet", s.Name())
-	return &blockchain.TransactionResult{TxHash: "SweepTxHash", Success: true}, nil
+func (s *AvalancheChain) ReplaceTx(ctx context.Context, wallet blockchain.WalletDetails, sent blockchain.SentTx, cancel bool) (*blockchain.TransactionResult, error) {
+	return evmReplace(ctx, s.fees, wallet, sent, cancel)
 }
 
 const AVALANCHE_SYMBOL = "AVAX"
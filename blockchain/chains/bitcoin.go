@@ -11,6 +11,7 @@ import (
 	"errors"
 	"fmt"
 	"log"
+	"math/big"
 	"net/http"
 	"sync"
 	"time"
@@ -110,6 +111,27 @@ func (b *BitcoinChain) NewAddress(prvHex string) (string, error) {
 	return address.EncodeAddress(), nil
 }
 
+// hdAddress is the native segwit address of the signer's key at path.
+func (b *BitcoinChain) hdAddress(ctx context.Context, path string) (string, error) {
+	public, err := b.BaseChain.PublicKey(ctx, blockchain.Secp256k1, path)
+	if err != nil {
+		return "", err
+	}
+	pubKey, err := btcec.ParsePubKey(public)
+	if err != nil {
+		return "", err
+	}
+
+	pubKeyHash := btcutil.Hash160(pubKey.SerializeCompressed())
+
+	address, err := btcutil.NewAddressWitnessPubKeyHash(pubKeyHash, b.Params)
+	if err != nil {
+		return "", err
+	}
+
+	return address.EncodeAddress(), nil
+}
+
 func (b *BitcoinChain) ValidateAddress(address string) bool {
 	_, err := btcutil.DecodeAddress(address, b.Params)
 	return err == nil
@@ -118,16 +140,11 @@ func (b *BitcoinChain) ValidateAddress(address string) bool {
 func (b *BitcoinChain) Create(ctx context.Context) (*blockchain.WalletDetails, error) {
 	fmt.Printf("[%s]: Creating wallet\n", b.Name())
 
-	mnemonic, err := b.BaseChain.GenerateMnemonicPhrase()
-	if err != nil {
-		return nil, err
-	}
-
-	hdPath := b.BaseChain.GetDerivedPath(int(Taproot), 0, 0, 0, 1)
-	privateKeyHex, err := b.BaseChain.GetDerivedPrivateKey(mnemonic, hdPath)
+	key, err := btcec.NewPrivateKey()
 	if err != nil {
 		return nil, err
 	}
+	privateKeyHex := hex.EncodeToString(key.Serialize())
 
 	address, err := b.NewAddress(privateKeyHex)
 	if err != nil {
@@ -140,27 +157,20 @@ func (b *BitcoinChain) Create(ctx context.Context) (*blockchain.WalletDetails, e
 	}
 
 	return &blockchain.WalletDetails{
-		Address:        address,
-		PrivateKey:     privateKeyHex,
-		MnemonicPhrase: mnemonic,
+		Address:    address,
+		PrivateKey: privateKeyHex,
 	}, nil
 }
 
+func (b *BitcoinChain) DerivationPath(hdAccountId, hdWalletId int) string {
+	return b.BaseChain.GetDerivedPath(int(Taproot), 0, 0, hdAccountId, hdWalletId)
+}
+
 func (b *BitcoinChain) CreateHDWallet(ctx context.Context, hdAccountId, hdWalletId int) (*blockchain.WalletDetails, error) {
 	fmt.Printf("[%s]: Creating wallet\n", b.Name())
 
-	mnemonic, err := b.BaseChain.GetMnemonic()
-	if err != nil {
-		return nil, err
-	}
-
-	hdPath := b.BaseChain.GetDerivedPath(int(Taproot), 0, 0, hdAccountId, hdWalletId)
-	privateKeyHex, err := b.BaseChain.GetDerivedPrivateKey(mnemonic, hdPath)
-	if err != nil {
-		return nil, err
-	}
-
-	address, err := b.NewAddress(privateKeyHex)
+	hdPath := b.DerivationPath(hdAccountId, hdWalletId)
+	address, err := b.hdAddress(ctx, hdPath)
 	if err != nil {
 		log.Printf("[%s] NewAddress error: %s\n", b.Name(), err.Error())
 		return nil, err
@@ -172,8 +182,7 @@ func (b *BitcoinChain) CreateHDWallet(ctx context.Context, hdAccountId, hdWallet
 
 	return &blockchain.WalletDetails{
 		Address:        address,
-		PrivateKey:     privateKeyHex,
-		MnemonicPhrase: mnemonic,
+		DerivationPath: hdPath,
 	}, nil
 }
 
@@ -185,20 +194,21 @@ func (b *BitcoinChain) Deposit(ctx context.Context, wallet blockchain.WalletDeta
 	}, nil
 }
 
-func (b *BitcoinChain) Withdraw(ctx context.Context, wallet blockchain.WalletDetails, amount float64, toAddress string) (*blockchain.TransactionResult, error) {
-	fmt.Printf("[%s]: Withdrawing %f BTC from %s\n", b.Name(), amount, wallet.Address)
-	return &blockchain.TransactionResult{
-		TxHash:  "WithdrawTxHash",
-		Success: true,
-	}, nil
+// Withdraw is not supported: the gateway builds no Bitcoin transactions.
+func (b *BitcoinChain) Withdraw(ctx context.Context, wallet blockchain.WalletDetails, amount *big.Int, token *string, toAddress string) (*blockchain.TransactionResult, error) {
+	return nil, blockchain.ErrNotImplemented
 }
 
-func (b *BitcoinChain) Sweep(ctx context.Context, wallet blockchain.WalletDetails) (*blockchain.TransactionResult, error) {
-	fmt.Printf("[%s]: Sweeping wallet\n", b.Name())
-	return &blockchain.TransactionResult{
-		TxHash:  "SweepTxHash",
-		Success: true,
-	}, nil
+// Sweep is not supported, see Withdraw.
+func (b *BitcoinChain) Sweep(ctx context.Context, wallet blockchain.WalletDetails, request blockchain.SweepRequest) (*blockchain.TransactionResult, error) {
+	return nil, blockchain.ErrNotImplemented
+}
+
+// ReplaceTx would bump a stuck transaction by replace-by-fee. It is out of
+// scope until the chain can send: with no UTXO source and no transaction
+// builder there is nothing broadcast to replace.
+func (b *BitcoinChain) ReplaceTx(ctx context.Context, wallet blockchain.WalletDetails, sent blockchain.SentTx, cancel bool) (*blockchain.TransactionResult, error) {
+	return nil, blockchain.ErrNotImplemented
 }
 
 func (e *BitcoinChain) BatchBalances(ctx context.Context, addresses []string, workers int) []models.BalanceResult {
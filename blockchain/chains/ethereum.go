@@ -23,17 +23,20 @@ import (
 
 type EthereumChain struct {
 	blockchain.BaseChain
+	fees *evmFeeOracle
 }
 
 func NewEthereumChain() *EthereumChain {
-	return &EthereumChain{
-		blockchain.BaseChain{
+	chain := &EthereumChain{
+		BaseChain: blockchain.BaseChain{
 			ID:          constants.Ethereum,
 			ChainName:   "ethereum",
 			ExplorerURL: "https://etherscan.io",
 			RPCHttp:     []string{"https://eth.drpc.org", "https://mainnet.infura.io/v3/ac1242cf6a134cc3a77530953a7b65d5", "https://ethereum-rpc.publicnode.com", "https://1rpc.io/eth", "https://1rpc.io/eth"},
 			WebSockets:  []string{"wss://mainnet.infura.io/ws/v3/ac1242cf6a134cc3a77530953a7b65d5", "wss://ethereum-rpc.publicnode.com"},
 		}}
+	chain.fees = newEVMFeeOracle(&chain.BaseChain)
+	return chain
 }
 
 func (e *EthereumChain) Name() string {
@@ -76,16 +79,11 @@ func (s *EthereumChain) ValidateAddress(address string) bool {
 func (s *EthereumChain) Create(ctx context.Context) (*blockchain.WalletDetails, error) {
 	fmt.Printf("[%s]: Creating wallet\n", s.Name())
 
-	mnemonic, err := s.BaseChain.GenerateMnemonicPhrase()
-	if err != nil {
-		return nil, err
-	}
-
-	hdPath := s.BaseChain.GetDerivedPath(44, 60, 0, 0, 1)
-	privateKey, err := s.BaseChain.GetDerivedPrivateKey(mnemonic, hdPath)
+	key, err := crypto.GenerateKey()
 	if err != nil {
 		return nil, err
 	}
+	privateKey := hex.EncodeToString(crypto.FromECDSA(key))
 	address, err := s.NewAddress(privateKey)
 	if err != nil {
 		log.Printf("[%s] NewAddress error:%s \n", s.BaseChain.Name(), err.Error())
@@ -97,29 +95,24 @@ func (s *EthereumChain) Create(ctx context.Context) (*blockchain.WalletDetails,
 	}
 
 	return &blockchain.WalletDetails{
-		Address:        address,
-		PrivateKey:     privateKey,
-		MnemonicPhrase: mnemonic,
+		Address:    address,
+		PrivateKey: privateKey,
 	}, nil
 }
 
+func (s *EthereumChain) DerivationPath(hdAccountId, hdWalletId int) string {
+	return s.BaseChain.GetDerivedPath(44, 60, int(s.ChainID()), hdAccountId, hdWalletId)
+}
+
 func (s *EthereumChain) CreateHDWallet(ctx context.Context, hdAccountId, hdWalletId int) (*blockchain.WalletDetails, error) {
 	fmt.Printf("[%s]: Creating HD wallet\n", s.Name())
 
-	mnemonic, err := s.BaseChain.GetMnemonic()
+	hdPath := s.DerivationPath(hdAccountId, hdWalletId)
+	key, err := newEVMKey(ctx, &s.BaseChain, hdPath)
 	if err != nil {
 		return nil, err
 	}
-
-	hdPath := s.BaseChain.GetDerivedPath(44, 60, int(s.ChainID()), hdAccountId, hdWalletId)
-	privateKey, err := s.BaseChain.GetDerivedPrivateKey(mnemonic, hdPath)
-	if err != nil {
-		return nil, err
-	}
-	address, err := s.NewAddress(privateKey)
-	if err != nil {
-		log.Printf("[%s] NewAddress error:%s \n", s.BaseChain.Name(), err.Error())
-	}
+	address := key.address.Hex()
 
 	if !s.ValidateAddress(address) {
 		return nil, errors.New("invalid ethereum address format")
@@ -129,8 +122,7 @@ func (s *EthereumChain) CreateHDWallet(ctx context.Context, hdAccountId, hdWalle
 
 	return &blockchain.WalletDetails{
 		Address:        address,
-		PrivateKey:     privateKey,
-		MnemonicPhrase: mnemonic,
+		DerivationPath: hdPath,
 	}, nil
 }
 
@@ -139,14 +131,42 @@ func (s *EthereumChain) Deposit(ctx context.Context, wallet blockchain.WalletDet
 	return &blockchain.TransactionResult{TxHash: "DepositTxHash", Success: true}, nil
 }
 
-func (s *EthereumChain) Withdraw(ctx context.Context, wallet blockchain.WalletDetails, amount float64, toAddress string) (*blockchain.TransactionResult, error) {
-	fmt.Printf("[%s]: Withdrawing %f from %s\n", s.Name(), amount, wallet.Address)
-	return &blockchain.TransactionResult{TxHash: "WithdrawTxHash", Success: true}, nil
+func (s *EthereumChain) CanSend() bool {
+	return true
+}
+
+// Withdraw sends amount of the native coin, or of the ERC-20 token, from
+// the wallet to toAddress.
+func (s *EthereumChain) Withdraw(ctx context.Context, wallet blockchain.WalletDetails, amount *big.Int, token *string, toAddress string) (*blockchain.TransactionResult, error) {
+	return evmWithdraw(ctx, s.fees, wallet, amount, token, toAddress)
+}
+
+func (s *EthereumChain) Sweep(ctx context.Context, wallet blockchain.WalletDetails, request blockchain.SweepRequest) (*blockchain.TransactionResult, error) {
+	return evmSweep(ctx, s.fees, wallet, request)
+}
+
+func (s *EthereumChain) EstimateSweep(ctx context.Context, fromAddress string, request blockchain.SweepRequest) (*blockchain.SweepFee, error) {
+	return evmEstimateSweep(ctx, s.fees, fromAddress, request)
+}
+
+func (s *EthereumChain) EstimateFees(ctx context.Context, strategy string) (*blockchain.FeeEstimate, error) {
+	return s.fees.Estimate(ctx, strategy)
+}
+
+func (s *EthereumChain) PendingNonce(ctx context.Context, address string) (uint64, error) {
+	return evmPendingNonce(ctx, s.RPCs(), address)
+}
+
+func (s *EthereumChain) BlockNumber(ctx context.Context) (uint64, error) {
+	return evmBlockNumber(ctx, s.RPCs())
+}
+
+func (s *EthereumChain) TxInclusion(ctx context.Context, txHash string) (*blockchain.TxInclusion, error) {
+	return evmTxInclusion(ctx, s.RPCs(), txHash)
 }
 
-func (s *EthereumChain) Sweep(ctx context.Context, wallet blockchain.WalletDetails) (*blockchain.TransactionResult, error) {
-	fmt.Printf("[%s]: Sweeping wallet", s.Name())
-	return &blockchain.TransactionResult{TxHash: "SweepTxHash", Success: true}, nil
+func (s *EthereumChain) ReplaceTx(ctx context.Context, wallet blockchain.WalletDetails, sent blockchain.SentTx, cancel bool) (*blockchain.TransactionResult, error) {
+	return evmReplace(ctx, s.fees, wallet, sent, cancel)
 }
 
 const MULTICALL3_ADDRESS = "0xcA11bde05977b3631167028862bE2a173976CA11"
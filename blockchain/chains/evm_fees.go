@@ -0,0 +1,161 @@
+package chains
+
+import (
+	"context"
+	blockchain "core/blockchain"
+	"core/constants"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// evmFeeBackend is the part of the node API the fee oracle samples.
+type evmFeeBackend interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+}
+
+// evmFeeSample is what the oracle learned from the node: the next base fee
+// and the priority fee per strategy, or only a gas price on legacy chains.
+type evmFeeSample struct {
+	baseFee  *big.Int
+	rewards  map[string]*big.Int
+	gasPrice *big.Int
+	at       time.Time
+}
+
+// evmFeeOracle prices transactions of an EVM chain from eth_feeHistory,
+// falling back to eth_gasPrice on legacy chains and nodes without
+// EIP-1559. Samples are reused for FEE_ORACLE_TTL.
+type evmFeeOracle struct {
+	chain *blockchain.BaseChain
+
+	mu     sync.Mutex
+	sample *evmFeeSample
+}
+
+func newEVMFeeOracle(chain *blockchain.BaseChain) *evmFeeOracle {
+	return &evmFeeOracle{chain: chain}
+}
+
+func (o *evmFeeOracle) Estimate(ctx context.Context, strategy string) (*blockchain.FeeEstimate, error) {
+	if !constants.IsFeeStrategy(strategy) {
+		return nil, fmt.Errorf("unknown fee strategy %q", strategy)
+	}
+	if sample := o.cached(); sample != nil {
+		return priceFees(o.chain.Fees, sample, strategy), nil
+	}
+
+	client, err := dialEVM(ctx, o.chain.RPCHttp)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+	return o.quote(ctx, client, strategy)
+}
+
+// quote is Estimate on an already dialed node.
+func (o *evmFeeOracle) quote(ctx context.Context, backend evmFeeBackend, strategy string) (*blockchain.FeeEstimate, error) {
+	sample := o.cached()
+	if sample == nil {
+		var err error
+		if sample, err = o.sampleFees(ctx, backend); err != nil {
+			return nil, err
+		}
+		o.mu.Lock()
+		o.sample = sample
+		o.mu.Unlock()
+	}
+	return priceFees(o.chain.Fees, sample, strategy), nil
+}
+
+// cached is the last sample while it is fresh.
+func (o *evmFeeOracle) cached() *evmFeeSample {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.sample == nil || time.Since(o.sample.at) >= constants.FEE_ORACLE_TTL {
+		return nil
+	}
+	return o.sample
+}
+
+// priceFees turns a sample into the fee to offer for the strategy, capped
+// by the chain's policy.
+func priceFees(policy blockchain.FeePolicy, sample *evmFeeSample, strategy string) *blockchain.FeeEstimate {
+	estimate := &blockchain.FeeEstimate{Strategy: strategy}
+
+	if sample.baseFee == nil {
+		estimate.Legacy = true
+		estimate.MaxFee = new(big.Int).Mul(sample.gasPrice, big.NewInt(constants.FeeGasPricePercent[strategy]))
+		estimate.MaxFee.Quo(estimate.MaxFee, big.NewInt(100))
+	} else {
+		estimate.BaseFee = new(big.Int).Set(sample.baseFee)
+		estimate.MaxPriorityFee = new(big.Int).Set(sample.rewards[strategy])
+		estimate.MaxFee = new(big.Int).Mul(sample.baseFee, big.NewInt(constants.FEE_BASE_FEE_MULTIPLIER))
+		estimate.MaxFee.Add(estimate.MaxFee, estimate.MaxPriorityFee)
+	}
+
+	if policy.MaxFee != nil && estimate.MaxFee.Cmp(policy.MaxFee) > 0 {
+		estimate.MaxFee.Set(policy.MaxFee)
+	}
+	if estimate.MaxPriorityFee != nil && estimate.MaxPriorityFee.Cmp(estimate.MaxFee) > 0 {
+		estimate.MaxPriorityFee.Set(estimate.MaxFee)
+	}
+	return estimate
+}
+
+func (o *evmFeeOracle) sampleFees(ctx context.Context, backend evmFeeBackend) (*evmFeeSample, error) {
+	sample := &evmFeeSample{at: time.Now()}
+
+	if !o.chain.Fees.Legacy {
+		percentiles := make([]float64, len(constants.FeeStrategies))
+		for i, strategy := range constants.FeeStrategies {
+			percentiles[i] = constants.FeeRewardPercentiles[strategy]
+		}
+
+		history, err := backend.FeeHistory(ctx, constants.FEE_HISTORY_BLOCKS, nil, percentiles)
+		switch {
+		case err != nil:
+			log.Printf("[%s] fee history unavailable, using gas price: %v\n", o.chain.ChainName, err)
+		case len(history.BaseFee) == 0 || history.BaseFee[len(history.BaseFee)-1].Sign() == 0:
+			// Pre-London chain answering with empty base fees.
+		default:
+			// BaseFee has one entry more than the sampled blocks, the base
+			// fee of the block after them.
+			sample.baseFee = history.BaseFee[len(history.BaseFee)-1]
+			sample.rewards = make(map[string]*big.Int, len(constants.FeeStrategies))
+			for i, strategy := range constants.FeeStrategies {
+				sample.rewards[strategy] = medianReward(history.Reward, i)
+			}
+			return sample, nil
+		}
+	}
+
+	gasPrice, err := backend.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	sample.gasPrice = gasPrice
+	return sample, nil
+}
+
+// medianReward is the median over the sampled blocks of the priority fee
+// at percentile index i.
+func medianReward(rewards [][]*big.Int, i int) *big.Int {
+	var values []*big.Int
+	for _, block := range rewards {
+		if i < len(block) && block[i] != nil {
+			values = append(values, block[i])
+		}
+	}
+	if len(values) == 0 {
+		return new(big.Int)
+	}
+	slices.SortFunc(values, func(a, b *big.Int) int { return a.Cmp(b) })
+	return new(big.Int).Set(values[len(values)/2])
+}
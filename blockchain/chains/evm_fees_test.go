@@ -0,0 +1,63 @@
+package chains
+
+import (
+	"context"
+	"core/blockchain"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+type fakeFeeBackend struct {
+	history  *ethereum.FeeHistory
+	err      error
+	gasPrice int64
+}
+
+func (b *fakeFeeBackend) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return b.history, b.err
+}
+
+func (b *fakeFeeBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(b.gasPrice), nil
+}
+
+func Test_EVMFeeOracleStrategies(t *testing.T) {
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000)) }
+	rewards := func(slow, normal, fast int64) []*big.Int { return []*big.Int{gwei(slow), gwei(normal), gwei(fast)} }
+
+	backend := &fakeFeeBackend{
+		history: &ethereum.FeeHistory{
+			BaseFee: []*big.Int{gwei(8), gwei(9), gwei(10), gwei(20)},
+			Reward:  [][]*big.Int{rewards(1, 2, 3), rewards(1, 3, 9), rewards(2, 4, 5)},
+		},
+		gasPrice: 5_000_000_000,
+	}
+	chain := NewEthereumChain()
+	chain.SetFeePolicy(blockchain.FeePolicy{MaxFee: gwei(42)})
+
+	fast, err := chain.fees.quote(context.Background(), backend, "fast")
+	if err != nil {
+		t.Fatalf("quote: %v", err)
+	}
+	// 2 × 20 base fee + 5 tip is over the 42 gwei cap.
+	if fast.Legacy || fast.BaseFee.Cmp(gwei(20)) != 0 || fast.MaxPriorityFee.Cmp(gwei(5)) != 0 || fast.MaxFee.Cmp(gwei(42)) != 0 {
+		t.Fatalf("unexpected fast estimate %+v", fast)
+	}
+	if fast.Expected().Cmp(gwei(25)) != 0 {
+		t.Fatalf("Expected = %s", fast.Expected())
+	}
+
+	// Nodes without fee history are priced off the gas price.
+	chain = NewEthereumChain()
+	backend.err = errors.New("method not found")
+	slow, err := chain.fees.quote(context.Background(), backend, "slow")
+	if err != nil {
+		t.Fatalf("quote: %v", err)
+	}
+	if !slow.Legacy || slow.MaxPriorityFee != nil || slow.MaxFee.Int64() != 4_500_000_000 {
+		t.Fatalf("unexpected legacy estimate %+v", slow)
+	}
+}
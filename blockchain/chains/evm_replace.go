@@ -0,0 +1,125 @@
+package chains
+
+import (
+	"context"
+	blockchain "core/blockchain"
+	"core/constants"
+	"errors"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func evmBlockNumber(ctx context.Context, rpcs []string) (uint64, error) {
+	client, err := dialEVM(ctx, rpcs)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+	return client.BlockNumber(ctx)
+}
+
+func evmTxInclusion(ctx context.Context, rpcs []string, txHash string) (*blockchain.TxInclusion, error) {
+	client, err := dialEVM(ctx, rpcs)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	receipt, err := client.TransactionReceipt(ctx, common.HexToHash(txHash))
+	if errors.Is(err, ethereum.NotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &blockchain.TxInclusion{
+		BlockNumber: receipt.BlockNumber.Uint64(),
+		Success:     receipt.Status == 1,
+	}, nil
+}
+
+// evmReplace sends a transaction with the nonce of sent that outbids it: its
+// fees are raised by TX_REPLACEMENT_BUMP_PERCENT, or to the fast estimate
+// when that is higher. A cancellation sends nothing to the sender itself.
+func evmReplace(ctx context.Context, fees *evmFeeOracle, wallet blockchain.WalletDetails, sent blockchain.SentTx, cancel bool) (*blockchain.TransactionResult, error) {
+	key, err := evmWalletKey(ctx, fees.chain, wallet)
+	if err != nil {
+		return nil, err
+	}
+	from := key.address
+	if !strings.EqualFold(from.Hex(), sent.From) {
+		return nil, errors.New("wallet did not send " + sent.From + "'s transaction")
+	}
+
+	client, err := dialEVM(ctx, fees.chain.RPCHttp)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	mined, err := client.NonceAt(ctx, from, nil)
+	if err != nil {
+		return nil, err
+	}
+	if mined > sent.Nonce {
+		return nil, blockchain.ErrNonceUsed
+	}
+
+	estimate, err := fees.quote(ctx, client, constants.FEE_STRATEGY_FAST)
+	if err != nil {
+		return nil, err
+	}
+
+	call := &evmCall{
+		to:    common.HexToAddress(sent.To),
+		value: sent.Value,
+		data:  sent.Data,
+		fee:   blockchain.SweepFee{GasLimit: sent.GasLimit},
+	}
+	if cancel {
+		call.to = from
+		call.value = new(big.Int)
+		call.data = nil
+		call.fee.GasLimit = constants.EVM_NATIVE_TRANSFER_GAS
+	}
+
+	call.fee.GasPrice = bumpFee(sent.GasPrice, estimate.MaxFee)
+	if sent.GasTipCap != nil {
+		tip := estimate.MaxPriorityFee
+		if tip == nil {
+			tip = estimate.MaxFee
+		}
+		call.tip = bumpFee(sent.GasTipCap, tip)
+		if call.tip.Cmp(call.fee.GasPrice) > 0 {
+			call.tip = call.fee.GasPrice
+		}
+	}
+	if fees.chain.Fees.MaxFee != nil && call.fee.GasPrice.Cmp(fees.chain.Fees.MaxFee) > 0 {
+		return nil, blockchain.ErrFeeCapReached
+	}
+
+	result, err := sendEVMCall(ctx, client, key, sent.Nonce, call)
+	if result != nil && fees.chain.Nonces != nil {
+		// The reservation follows the latest attempt using the nonce.
+		nonce := fees.chain.Nonces.Lookup(fees.chain.ID, from.Hex(), sent.Nonce)
+		if err := nonce.Broadcast(ctx, result.TxHash); err != nil {
+			log.Printf("[%s] nonce %d not recorded: %v\n", fees.chain.ChainName, sent.Nonce, err)
+		}
+	}
+	return result, err
+}
+
+// bumpFee is the larger of previous raised by TX_REPLACEMENT_BUMP_PERCENT
+// and current.
+func bumpFee(previous, current *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(previous, big.NewInt(constants.TX_REPLACEMENT_BUMP_PERCENT))
+	bumped.Quo(bumped, big.NewInt(100))
+	if current != nil && current.Cmp(bumped) > 0 {
+		return new(big.Int).Set(current)
+	}
+	return bumped
+}
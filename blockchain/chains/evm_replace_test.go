@@ -0,0 +1,96 @@
+package chains
+
+import (
+	"context"
+	"core/blockchain"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func Test_EVMReplaceCancelsWithSameNonce(t *testing.T) {
+	chain := NewEthereumChain()
+	chain.SetSigner(newFakeSigner())
+	wallet, err := chain.CreateHDWallet(context.Background(), 0, 1)
+	if err != nil {
+		t.Fatalf("CreateHDWallet: %v", err)
+	}
+	from := common.HexToAddress(wallet.Address)
+	gwei := func(n int64) *big.Int { return new(big.Int).Mul(big.NewInt(n), big.NewInt(1_000_000_000)) }
+
+	minedNonce := uint64(7)
+	var sent *types.Transaction
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		var result interface{}
+		switch req.Method {
+		case "eth_chainId":
+			result = "0x1"
+		case "eth_getTransactionCount":
+			result = hexutil.Uint64(minedNonce)
+		case "eth_feeHistory":
+			result = map[string]interface{}{
+				"oldestBlock":   "0x1",
+				"baseFeePerGas": []string{hexutil.EncodeBig(gwei(10)), hexutil.EncodeBig(gwei(10))},
+				"reward":        [][]string{{hexutil.EncodeBig(gwei(1)), hexutil.EncodeBig(gwei(1)), hexutil.EncodeBig(gwei(2))}},
+				"gasUsedRatio":  []float64{0.5},
+			}
+		case "eth_sendRawTransaction":
+			var raw string
+			_ = json.Unmarshal(req.Params[0], &raw)
+			sent = new(types.Transaction)
+			if err := sent.UnmarshalBinary(hexutil.MustDecode(raw)); err != nil {
+				t.Errorf("undecodable transaction: %v", err)
+			}
+			result = sent.Hash().Hex()
+		default:
+			t.Errorf("unexpected call %s", req.Method)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer server.Close()
+
+	chain.RPCHttp = []string{server.URL}
+	stuck := blockchain.SentTx{
+		From:      from.Hex(),
+		To:        "0x00000000000000000000000000000000000000aa",
+		Nonce:     7,
+		Value:     big.NewInt(1),
+		GasLimit:  65_000,
+		GasPrice:  gwei(40),
+		GasTipCap: gwei(2),
+	}
+
+	result, err := chain.ReplaceTx(context.Background(), *wallet, stuck, true)
+	if err != nil {
+		t.Fatalf("ReplaceTx: %v", err)
+	}
+	if result.TxHash != sent.Hash().Hex() || sent.Nonce() != 7 || *sent.To() != from || sent.Value().Sign() != 0 || sent.Gas() != 21_000 {
+		t.Fatalf("not a cancellation of nonce 7: %+v", sent)
+	}
+	if signer, err := types.Sender(types.LatestSignerForChainID(big.NewInt(1)), sent); err != nil || signer != from {
+		t.Fatalf("transaction not signed by the wallet: %v", err)
+	}
+	// The 2 gwei fast tip and 22 gwei fee cap are below the 25% bump.
+	if sent.GasFeeCap().Cmp(gwei(50)) != 0 || sent.GasTipCap().Cmp(big.NewInt(2_500_000_000)) != 0 {
+		t.Fatalf("fee cap %s tip %s", sent.GasFeeCap(), sent.GasTipCap())
+	}
+
+	minedNonce = 8
+	if _, err := chain.ReplaceTx(context.Background(), *wallet, stuck, false); !errors.Is(err, blockchain.ErrNonceUsed) {
+		t.Fatalf("expected ErrNonceUsed, got %v", err)
+	}
+}
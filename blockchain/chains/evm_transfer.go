@@ -0,0 +1,352 @@
+package chains
+
+import (
+	"context"
+	blockchain "core/blockchain"
+	"core/constants"
+	"core/contracts/erc20"
+	"core/helpers"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// evmCall is the transaction a sweep sends and the gas it pays. A non-nil
+// tip makes it an EIP-1559 transaction with fee.GasPrice as its fee cap.
+type evmCall struct {
+	to    common.Address
+	value *big.Int
+	data  []byte
+	fee   blockchain.SweepFee
+	tip   *big.Int
+}
+
+// evmKey is a key of the chain's signer and the account it controls.
+type evmKey struct {
+	chain   *blockchain.BaseChain
+	path    string
+	address common.Address
+}
+
+func newEVMKey(ctx context.Context, chain *blockchain.BaseChain, path string) (*evmKey, error) {
+	public, err := chain.PublicKey(ctx, blockchain.Secp256k1, path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := crypto.UnmarshalPubkey(public)
+	if err != nil {
+		return nil, err
+	}
+	return &evmKey{chain: chain, path: path, address: crypto.PubkeyToAddress(*key)}, nil
+}
+
+// evmWalletKey is the key of the wallet, which must control its address.
+func evmWalletKey(ctx context.Context, chain *blockchain.BaseChain, wallet blockchain.WalletDetails) (*evmKey, error) {
+	key, err := newEVMKey(ctx, chain, wallet.DerivationPath)
+	if err != nil {
+		return nil, err
+	}
+	if wallet.Address != "" && !strings.EqualFold(key.address.Hex(), wallet.Address) {
+		return nil, fmt.Errorf("key at %s does not control %s", wallet.DerivationPath, wallet.Address)
+	}
+	return key, nil
+}
+
+func (k *evmKey) signTx(ctx context.Context, tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signer := types.LatestSignerForChainID(chainID)
+	signature, err := k.chain.Sign(ctx, blockchain.Secp256k1, k.path, signer.Hash(tx).Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return tx.WithSignature(signer, signature)
+}
+
+// dialEVM returns a client for the first RPC endpoint that answers.
+func dialEVM(ctx context.Context, rpcs []string) (*ethclient.Client, error) {
+	var errs []error
+	for _, rpc := range rpcs {
+		client, err := ethclient.DialContext(ctx, rpc)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if _, err := client.ChainID(ctx); err != nil {
+			client.Close()
+			errs = append(errs, err)
+			continue
+		}
+		return client, nil
+	}
+	return nil, fmt.Errorf("%w: %v", blockchain.ErrChainUnavailable, errors.Join(errs...))
+}
+
+// prepareEVMSweep builds the native or ERC-20 transfer of a sweep from the
+// given address. Without a pinned fee the gas is estimated and priced by
+// the fee oracle at the normal strategy. A pinned gas price caps the fee
+// cap and the tip.
+func prepareEVMSweep(ctx context.Context, client *ethclient.Client, fees *evmFeeOracle, from common.Address, request blockchain.SweepRequest) (*evmCall, error) {
+	if !common.IsHexAddress(request.ToAddress) {
+		return nil, fmt.Errorf("invalid sweep destination %q", request.ToAddress)
+	}
+	to := common.HexToAddress(request.ToAddress)
+
+	native, err := client.BalanceAt(ctx, from, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	call := &evmCall{fee: blockchain.SweepFee{Balance: native}}
+	if request.Fee != nil {
+		call.fee.GasLimit = request.Fee.GasLimit
+		call.fee.GasPrice = request.Fee.GasPrice
+	}
+
+	estimate, err := fees.quote(ctx, client, constants.FEE_STRATEGY_NORMAL)
+	if err != nil {
+		return nil, err
+	}
+	if call.fee.GasPrice == nil {
+		call.fee.GasPrice = estimate.MaxFee
+	}
+	if !estimate.Legacy {
+		call.tip = estimate.MaxPriorityFee
+		if call.tip.Cmp(call.fee.GasPrice) > 0 {
+			call.tip = call.fee.GasPrice
+		}
+	}
+
+	if request.Token == nil {
+		if call.fee.GasLimit == 0 {
+			call.fee.GasLimit = constants.EVM_NATIVE_TRANSFER_GAS
+		}
+		amount := request.Amount
+		if amount == nil {
+			amount = new(big.Int).Sub(native, call.fee.Total())
+		}
+		if amount.Sign() <= 0 {
+			return nil, blockchain.ErrNothingToSweep
+		}
+
+		call.to = to
+		call.value = amount
+		return call, nil
+	}
+
+	token := common.HexToAddress(*request.Token)
+	amount := request.Amount
+	if amount == nil {
+		caller, err := erc20.NewERC20Caller(token, client)
+		if err != nil {
+			return nil, err
+		}
+		if amount, err = caller.BalanceOf(&bind.CallOpts{Context: ctx}, from); err != nil {
+			return nil, err
+		}
+	}
+	if amount.Sign() <= 0 {
+		return nil, blockchain.ErrNothingToSweep
+	}
+
+	erc20ABI, err := erc20.ERC20MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	if call.data, err = erc20ABI.Pack("transfer", to, amount); err != nil {
+		return nil, err
+	}
+
+	if call.fee.GasLimit == 0 {
+		// Estimation does not check the sender can pay for gas, so it works
+		// for token-only addresses too.
+		call.fee.GasLimit, err = client.EstimateGas(ctx, ethereum.CallMsg{From: from, To: &token, Data: call.data})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	call.to = token
+	call.value = big.NewInt(0)
+	return call, nil
+}
+
+// evmEstimateSweep prices the sweep without sending it.
+func evmEstimateSweep(ctx context.Context, fees *evmFeeOracle, fromAddress string, request blockchain.SweepRequest) (*blockchain.SweepFee, error) {
+	if !common.IsHexAddress(fromAddress) {
+		return nil, fmt.Errorf("invalid sweep source %q", fromAddress)
+	}
+
+	client, err := dialEVM(ctx, fees.chain.RPCHttp)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	call, err := prepareEVMSweep(ctx, client, fees, common.HexToAddress(fromAddress), request)
+	if err != nil {
+		return nil, err
+	}
+	return &call.fee, nil
+}
+
+// evmSweep signs and broadcasts a transfer of the native coin or an ERC-20
+// token from the wallet to request.ToAddress.
+func evmSweep(ctx context.Context, fees *evmFeeOracle, wallet blockchain.WalletDetails, request blockchain.SweepRequest) (*blockchain.TransactionResult, error) {
+	key, err := evmWalletKey(ctx, fees.chain, wallet)
+	if err != nil {
+		return nil, err
+	}
+	from := key.address
+
+	client, err := dialEVM(ctx, fees.chain.RPCHttp)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	call, err := prepareEVMSweep(ctx, client, fees, from, request)
+	if err != nil {
+		return nil, err
+	}
+	fee := call.fee.Total()
+	if new(big.Int).Add(call.value, fee).Cmp(call.fee.Balance) > 0 {
+		return nil, blockchain.ErrInsufficientGas
+	}
+
+	nonce, err := reserveEVMNonce(ctx, fees.chain, client, from)
+	if err != nil {
+		return nil, err
+	}
+	result, err := sendEVMCall(ctx, client, key, nonce.value, call)
+	nonce.settle(ctx, result, err)
+	return result, err
+}
+
+// evmWithdraw pays amount of the native coin or an ERC-20 token from the
+// wallet, priced like a sweep.
+func evmWithdraw(ctx context.Context, fees *evmFeeOracle, wallet blockchain.WalletDetails, amount *big.Int, token *string, toAddress string) (*blockchain.TransactionResult, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, helpers.ErrInvalidAmount
+	}
+	return evmSweep(ctx, fees, wallet, blockchain.SweepRequest{ToAddress: toAddress, Token: token, Amount: amount})
+}
+
+// evmNonce is the nonce a transaction is sent with, reserved with the
+// chain's nonce manager when it has one.
+type evmNonce struct {
+	chain    *blockchain.BaseChain
+	value    uint64
+	reserved *blockchain.Nonce
+}
+
+func reserveEVMNonce(ctx context.Context, chain *blockchain.BaseChain, client *ethclient.Client, from common.Address) (*evmNonce, error) {
+	pending, err := client.PendingNonceAt(ctx, from)
+	if err != nil {
+		return nil, err
+	}
+	if chain.Nonces == nil {
+		return &evmNonce{chain: chain, value: pending}, nil
+	}
+
+	reserved, err := chain.Nonces.Reserve(ctx, chain.ID, chain.ChainName, from.Hex(), pending)
+	if err != nil {
+		return nil, err
+	}
+	return &evmNonce{chain: chain, value: reserved.Value, reserved: reserved}, nil
+}
+
+// settle records the nonce as broadcast, or releases it when sending
+// failed before the transaction could reach the network. A transaction
+// whose broadcast is unknown keeps its nonce, it may still be mined.
+func (n *evmNonce) settle(ctx context.Context, result *blockchain.TransactionResult, sendErr error) {
+	if n.reserved == nil {
+		return
+	}
+	var err error
+	if sendErr != nil && !errors.Is(sendErr, blockchain.ErrSendUnknown) {
+		err = n.reserved.Release(ctx)
+	} else {
+		err = n.reserved.Broadcast(ctx, result.TxHash)
+	}
+	if err != nil {
+		log.Printf("[%s] nonce %d not recorded: %v\n", n.chain.ChainName, n.value, err)
+	}
+}
+
+func evmPendingNonce(ctx context.Context, rpcs []string, address string) (uint64, error) {
+	if !common.IsHexAddress(address) {
+		return 0, fmt.Errorf("invalid address %q", address)
+	}
+	client, err := dialEVM(ctx, rpcs)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+	return client.PendingNonceAt(ctx, common.HexToAddress(address))
+}
+
+// sendEVMCall signs call with the given nonce and broadcasts it. When the
+// broadcast fails the transaction may still have reached the network, the
+// result of the signed transaction is returned with an ErrSendUnknown.
+func sendEVMCall(ctx context.Context, client *ethclient.Client, key *evmKey, nonce uint64, call *evmCall) (*blockchain.TransactionResult, error) {
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var tx *types.Transaction
+	if call.tip != nil {
+		tx = types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainID,
+			Nonce:     nonce,
+			To:        &call.to,
+			Value:     call.value,
+			Gas:       call.fee.GasLimit,
+			GasFeeCap: call.fee.GasPrice,
+			GasTipCap: call.tip,
+			Data:      call.data,
+		})
+	} else {
+		tx = types.NewTx(&types.LegacyTx{
+			Nonce:    nonce,
+			To:       &call.to,
+			Value:    call.value,
+			Gas:      call.fee.GasLimit,
+			GasPrice: call.fee.GasPrice,
+			Data:     call.data,
+		})
+	}
+
+	signed, err := key.signTx(ctx, tx, chainID)
+	if err != nil {
+		return nil, err
+	}
+	result := &blockchain.TransactionResult{
+		TxHash:  signed.Hash().Hex(),
+		Success: true,
+		Fee:     call.fee.Total(),
+		Sent: &blockchain.SentTx{
+			From:      key.address.Hex(),
+			To:        call.to.Hex(),
+			Nonce:     nonce,
+			Value:     call.value,
+			Data:      call.data,
+			GasLimit:  call.fee.GasLimit,
+			GasPrice:  call.fee.GasPrice,
+			GasTipCap: call.tip,
+		},
+	}
+	if err := client.SendTransaction(ctx, signed); err != nil {
+		result.Success = false
+		return result, fmt.Errorf("%w: %s: %v", blockchain.ErrSendUnknown, result.TxHash, err)
+	}
+	return result, nil
+}
@@ -0,0 +1,96 @@
+package chains
+
+import (
+	"context"
+	"core/blockchain"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/gagliardetto/solana-go"
+)
+
+// fakeSigner is an in-process Signer with a fresh key per path. It records
+// the paths it signed for.
+type fakeSigner struct {
+	mu     sync.Mutex
+	secp   map[string]*ecdsa.PrivateKey
+	ed     map[string]ed25519.PrivateKey
+	signed []string
+}
+
+func newFakeSigner() *fakeSigner {
+	return &fakeSigner{secp: map[string]*ecdsa.PrivateKey{}, ed: map[string]ed25519.PrivateKey{}}
+}
+
+func (f *fakeSigner) PublicKey(ctx context.Context, scheme blockchain.SignatureScheme, path string) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch scheme {
+	case blockchain.Secp256k1:
+		return crypto.FromECDSAPub(&f.secpKey(path).PublicKey), nil
+	case blockchain.Ed25519:
+		return f.edKey(path).Public().(ed25519.PublicKey), nil
+	}
+	return nil, fmt.Errorf("unknown scheme %q", scheme)
+}
+
+func (f *fakeSigner) Sign(ctx context.Context, scheme blockchain.SignatureScheme, path string, payload []byte) ([]byte, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.signed = append(f.signed, path)
+	switch scheme {
+	case blockchain.Secp256k1:
+		return crypto.Sign(payload, f.secpKey(path))
+	case blockchain.Ed25519:
+		return ed25519.Sign(f.edKey(path), payload), nil
+	}
+	return nil, fmt.Errorf("unknown scheme %q", scheme)
+}
+
+func (f *fakeSigner) secpKey(path string) *ecdsa.PrivateKey {
+	if f.secp[path] == nil {
+		f.secp[path], _ = crypto.GenerateKey()
+	}
+	return f.secp[path]
+}
+
+func (f *fakeSigner) edKey(path string) ed25519.PrivateKey {
+	if f.ed[path] == nil {
+		_, f.ed[path], _ = ed25519.GenerateKey(nil)
+	}
+	return f.ed[path]
+}
+
+func Test_HDWalletsComeFromTheSigner(t *testing.T) {
+	signer := newFakeSigner()
+	ethereum, tron, sol := NewEthereumChain(), NewTronChain(), NewSolanaChain()
+	for _, chain := range []interface{ SetSigner(blockchain.Signer) }{ethereum, tron, sol} {
+		chain.SetSigner(signer)
+	}
+	ctx := context.Background()
+
+	wallet, err := ethereum.CreateHDWallet(ctx, 3, 4)
+	if err != nil || wallet.PrivateKey != "" || wallet.Address != crypto.PubkeyToAddress(signer.secp[wallet.DerivationPath].PublicKey).Hex() {
+		t.Fatalf("ethereum wallet %+v: %v", wallet, err)
+	}
+
+	wallet, err = tron.CreateHDWallet(ctx, 3, 4)
+	if err != nil || wallet.PrivateKey != "" || wallet.Address != base58.CheckEncode(crypto.PubkeyToAddress(signer.secp[wallet.DerivationPath].PublicKey).Bytes(), tronAddressPrefix) {
+		t.Fatalf("tron wallet %+v: %v", wallet, err)
+	}
+
+	wallet, err = sol.CreateHDWallet(ctx, 3, 4)
+	if err != nil || wallet.PrivateKey != "" || wallet.Address != solana.PrivateKey(signer.ed[wallet.DerivationPath]).PublicKey().String() {
+		t.Fatalf("solana wallet %+v: %v", wallet, err)
+	}
+
+	if _, err := NewEthereumChain().CreateHDWallet(ctx, 3, 4); !errors.Is(err, blockchain.ErrNoSigner) {
+		t.Fatalf("expected ErrNoSigner, got %v", err)
+	}
+}
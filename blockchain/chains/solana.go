@@ -5,10 +5,8 @@ import (
 	"context"
 	blockchain "core/blockchain"
 	"core/constants"
+	"core/helpers"
 	"core/models"
-	"crypto/ed25519"
-	"crypto/hmac"
-	"crypto/sha512"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -17,29 +15,12 @@ import (
 	"sync"
 	"time"
 
-	"github.com/btcsuite/btcd/btcutil/base58"
 	"github.com/gagliardetto/solana-go"
-	solanaGO "github.com/gagliardetto/solana-go"
-	"golang.org/x/crypto/pbkdf2"
+	"github.com/gagliardetto/solana-go/rpc"
 
 	solanaSDK "github.com/okx/go-wallet-sdk/coins/solana"
 )
 
-const hardened uint32 = 0x80000000
-
-func derive(key []byte, chainCode []byte, segment uint32) ([]byte, []byte) {
-	buf := []byte{0}
-	buf = append(buf, key...)
-	buf = append(buf, big.NewInt(int64(segment)).Bytes()...)
-	h := hmac.New(sha512.New, chainCode)
-	h.Write(buf)
-	I := h.Sum(nil)
-	IL := I[:32]
-	IR := I[32:]
-
-	return IL, IR
-}
-
 type SolanaChain struct {
 	blockchain.BaseChain
 }
@@ -51,6 +32,7 @@ func NewSolanaChain() *SolanaChain {
 			ID:          constants.Solana,
 			ChainName:   "solana",
 			ExplorerURL: "https://explorer.solana.com/",
+			RPCHttp:     []string{"https://api.mainnet-beta.solana.com"},
 		},
 	}
 }
@@ -81,39 +63,38 @@ func (s *SolanaChain) ValidateAddress(address string) bool {
 func (s *SolanaChain) Create(ctx context.Context) (*blockchain.WalletDetails, error) {
 	fmt.Printf("[%s]: Creating wallet\n", s.Name())
 
-	mnemonic, err := s.BaseChain.GenerateMnemonicPhrase()
+	key, err := solana.NewRandomPrivateKey()
 	if err != nil {
 		return nil, err
 	}
 
-	wallet, err := s.GenerateWalletFromMnemonicSeed(mnemonic, "")
-
-	privateKey := wallet.PrivateKey.String()
-	address := wallet.PublicKey().String()
+	privateKey := key.String()
+	address := key.PublicKey().String()
 
 	if !s.ValidateAddress(address) {
 		return nil, errors.New("invalid solana address format")
 	}
 
 	return &blockchain.WalletDetails{
-		Address:        address,
-		PrivateKey:     privateKey,
-		MnemonicPhrase: mnemonic,
+		Address:    address,
+		PrivateKey: privateKey,
 	}, nil
 }
 
+// DerivationPath follows SLIP-0010, every segment is hardened for ed25519.
+func (s *SolanaChain) DerivationPath(hdAccountId, hdWalletId int) string {
+	return fmt.Sprintf("m/44'/501'/%d'/%d'", hdAccountId, hdWalletId)
+}
+
 func (s *SolanaChain) CreateHDWallet(ctx context.Context, hdAccountId, hdWalletId int) (*blockchain.WalletDetails, error) {
 	fmt.Printf("[%s]: Creating HD wallet\n", s.Name())
 
-	mnemonic, err := s.BaseChain.GetMnemonic()
+	hdPath := s.DerivationPath(hdAccountId, hdWalletId)
+	public, err := s.BaseChain.PublicKey(ctx, blockchain.Ed25519, hdPath)
 	if err != nil {
 		return nil, err
 	}
-
-	wallet, err := s.GenerateHDWalletFromMnemonicSeed(mnemonic, "", hdAccountId, hdWalletId)
-
-	privateKey := wallet.PrivateKey.String()
-	address := wallet.PublicKey().String()
+	address := solana.PublicKeyFromBytes(public).String()
 
 	if !s.ValidateAddress(address) {
 		return nil, errors.New("invalid solana address format")
@@ -121,82 +102,137 @@ func (s *SolanaChain) CreateHDWallet(ctx context.Context, hdAccountId, hdWalletI
 
 	return &blockchain.WalletDetails{
 		Address:        address,
-		PrivateKey:     privateKey,
-		MnemonicPhrase: mnemonic,
+		DerivationPath: hdPath,
 	}, nil
 }
 
-func (s *SolanaChain) GenerateWalletFromMnemonicSeed(mnemonic, password string) (*solana.Wallet, error) {
-	pass := []byte("mnemonic")
-	if password != "" {
-		pass = []byte(password)
-	}
-	seed := pbkdf2.Key([]byte(mnemonic), pass, 2048, 64, sha512.New)
-	h := hmac.New(sha512.New, []byte("ed25519 seed"))
-	h.Write(seed)
-	sum := h.Sum(nil)
-
-	derivedSeed := sum[:32]
-	chain := sum[32:]
+func (s *SolanaChain) Deposit(ctx context.Context, wallet blockchain.WalletDetails, amount float64, toAddress string) (*blockchain.TransactionResult, error) {
+	fmt.Printf("[%s]: Depositing %f to %s\n", s.Name(), amount, toAddress)
+	return &blockchain.TransactionResult{TxHash: "DepositTxHash", Success: true}, nil
+}
 
-	path := []uint32{hardened + uint32(44), hardened + uint32(501), hardened + uint32(0), hardened + uint32(1)}
+func (s *SolanaChain) CanSend() bool {
+	return true
+}
 
-	for _, segment := range path {
-		derivedSeed, chain = derive(derivedSeed, chain, segment)
+// Withdraw sends amount lamports, or amount of the SPL token, from the
+// wallet to toAddress.
+func (s *SolanaChain) Withdraw(ctx context.Context, wallet blockchain.WalletDetails, amount *big.Int, token *string, toAddress string) (*blockchain.TransactionResult, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, helpers.ErrInvalidAmount
 	}
+	return s.Sweep(ctx, wallet, blockchain.SweepRequest{ToAddress: toAddress, Token: token, Amount: amount})
+}
 
-	key := ed25519.NewKeyFromSeed(derivedSeed)
+// BlockNumber is the latest confirmed slot.
+func (s *SolanaChain) BlockNumber(ctx context.Context) (uint64, error) {
+	client, err := dialSolana(ctx, s.RPCHttp)
+	if err != nil {
+		return 0, err
+	}
+	defer client.Close()
+	return client.GetSlot(ctx, rpc.CommitmentConfirmed)
+}
 
-	wallet, err := solanaGO.WalletFromPrivateKeyBase58(base58.Encode(key))
+// TxInclusion reports the slot of a confirmed transaction.
+func (s *SolanaChain) TxInclusion(ctx context.Context, txHash string) (*blockchain.TxInclusion, error) {
+	signature, err := solana.SignatureFromBase58(txHash)
 	if err != nil {
 		return nil, err
 	}
+	client, err := dialSolana(ctx, s.RPCHttp)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
 
-	return wallet, nil
+	statuses, err := client.GetSignatureStatuses(ctx, true, signature)
+	if err != nil {
+		return nil, err
+	}
+	if len(statuses.Value) == 0 || statuses.Value[0] == nil ||
+		statuses.Value[0].ConfirmationStatus == rpc.ConfirmationStatusProcessed {
+		return nil, nil
+	}
+	return &blockchain.TxInclusion{
+		BlockNumber: statuses.Value[0].Slot,
+		Success:     statuses.Value[0].Err == nil,
+	}, nil
 }
 
-func (s *SolanaChain) GenerateHDWalletFromMnemonicSeed(mnemonic, password string, hdAccountId, hdWalletId int) (*solana.Wallet, error) {
-	pass := []byte("mnemonic")
-	if password != "" {
-		pass = []byte(password)
+// Sweep sends SOL or an SPL token and waits until the transfer is
+// confirmed. request.Fee is not used, the fee is priced right before
+// sending.
+func (s *SolanaChain) Sweep(ctx context.Context, wallet blockchain.WalletDetails, request blockchain.SweepRequest) (*blockchain.TransactionResult, error) {
+	public, err := s.BaseChain.PublicKey(ctx, blockchain.Ed25519, wallet.DerivationPath)
+	if err != nil {
+		return nil, err
+	}
+	from := solana.PublicKeyFromBytes(public)
+	if wallet.Address != "" && wallet.Address != from.String() {
+		return nil, fmt.Errorf("key at %s does not control %s", wallet.DerivationPath, wallet.Address)
+	}
+	transfer, err := s.transfer(from.String(), request)
+	if err != nil {
+		return nil, err
 	}
-	seed := pbkdf2.Key([]byte(mnemonic), pass, 2048, 64, sha512.New)
-	h := hmac.New(sha512.New, []byte("ed25519 seed"))
-	h.Write(seed)
-	sum := h.Sum(nil)
 
-	derivedSeed := sum[:32]
-	chain := sum[32:]
+	client, err := dialSolana(ctx, s.RPCHttp)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
 
-	path := []uint32{hardened + uint32(44), hardened + uint32(501), hardened + uint32(hdAccountId), hardened + uint32(hdWalletId)}
+	return sendSolanaTransfer(ctx, client, &s.BaseChain, wallet.DerivationPath, *transfer)
+}
 
-	for _, segment := range path {
-		derivedSeed, chain = derive(derivedSeed, chain, segment)
+// EstimateSweep quotes the lamports the sender needs for the sweep: the
+// network fee, the rent of a destination token account it creates and, when
+// the sender would not be emptied, the rent-exempt minimum it must keep.
+// Solana fees are reported in lamports at a unit gas price.
+func (s *SolanaChain) EstimateSweep(ctx context.Context, fromAddress string, request blockchain.SweepRequest) (*blockchain.SweepFee, error) {
+	transfer, err := s.transfer(fromAddress, request)
+	if err != nil {
+		return nil, err
 	}
 
-	key := ed25519.NewKeyFromSeed(derivedSeed)
-
-	wallet, err := solanaGO.WalletFromPrivateKeyBase58(base58.Encode(key))
+	client, err := dialSolana(ctx, s.RPCHttp)
 	if err != nil {
 		return nil, err
 	}
+	defer client.Close()
 
-	return wallet, nil
-}
+	plan, err := planSolanaTransfer(ctx, client, *transfer)
+	if err != nil {
+		return nil, err
+	}
 
-func (s *SolanaChain) Deposit(ctx context.Context, wallet blockchain.WalletDetails, amount float64, toAddress string) (*blockchain.TransactionResult, error) {
-	fmt.Printf("[%s]: Depositing %f to %s\n", s.Name(), amount, toAddress)
-	return &blockchain.TransactionResult{TxHash: "DepositTxHash", Success: true}, nil
+	needed := plan.cost()
+	if transfer.mint != nil && plan.balance != needed {
+		needed += plan.rentExemptMin
+	}
+	return &blockchain.SweepFee{GasLimit: needed, GasPrice: big.NewInt(1), Balance: new(big.Int).SetUint64(plan.balance)}, nil
 }
 
-func (s *SolanaChain) Withdraw(ctx context.Context, wallet blockchain.WalletDetails, amount float64, toAddress string) (*blockchain.TransactionResult, error) {
-	fmt.Printf("[%s]: Withdrawing %f from %s\n", s.Name(), amount, wallet.Address)
-	return &blockchain.TransactionResult{TxHash: "WithdrawTxHash", Success: true}, nil
-}
+func (s *SolanaChain) transfer(fromAddress string, request blockchain.SweepRequest) (*solanaTransfer, error) {
+	from, err := solana.PublicKeyFromBase58(fromAddress)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sweep source %q", fromAddress)
+	}
+	to, err := solana.PublicKeyFromBase58(request.ToAddress)
+	if err != nil || !s.ValidateAddress(request.ToAddress) {
+		return nil, fmt.Errorf("invalid sweep destination %q", request.ToAddress)
+	}
 
-func (s *SolanaChain) Sweep(ctx context.Context, wallet blockchain.WalletDetails) (*blockchain.TransactionResult, error) {
-	fmt.Printf("[%s]: Sweeping wallet", s.Name())
-	return &blockchain.TransactionResult{TxHash: "SweepTxHash", Success: true}, nil
+	transfer := &solanaTransfer{from: from, to: to, amount: request.Amount}
+	if request.Token != nil {
+		mint, err := solana.PublicKeyFromBase58(*request.Token)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token mint %q", *request.Token)
+		}
+		transfer.mint = &mint
+	}
+	return transfer, nil
 }
 
 func (e *SolanaChain) BatchBalances(ctx context.Context, addresses []string, workers int) []models.BalanceResult {
@@ -0,0 +1,294 @@
+package chains
+
+import (
+	"context"
+	blockchain "core/blockchain"
+	"core/constants"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"slices"
+	"time"
+
+	"github.com/gagliardetto/solana-go"
+	associatedtokenaccount "github.com/gagliardetto/solana-go/programs/associated-token-account"
+	computebudget "github.com/gagliardetto/solana-go/programs/compute-budget"
+	"github.com/gagliardetto/solana-go/programs/system"
+	"github.com/gagliardetto/solana-go/programs/token"
+	"github.com/gagliardetto/solana-go/rpc"
+)
+
+// solanaTransfer is a SOL (nil mint) or SPL token transfer. A nil amount
+// moves the whole balance, less the fee for SOL.
+type solanaTransfer struct {
+	from   solana.PublicKey
+	to     solana.PublicKey
+	mint   *solana.PublicKey
+	amount *big.Int
+}
+
+// solanaPlan is a transfer ready to be signed, with what it costs the
+// sender in lamports.
+type solanaPlan struct {
+	tx            *solana.Transaction
+	lastValid     uint64 // block height after which the blockhash expires
+	amount        *big.Int
+	balance       uint64 // SOL balance of the sender
+	fee           uint64 // network fee, priority fee included
+	rent          uint64 // rent of a destination token account it creates
+	rentExemptMin uint64 // least a system account that is not emptied must keep
+}
+
+// cost is all the lamports the transfer takes from the sender besides the
+// SOL it moves.
+func (p *solanaPlan) cost() uint64 {
+	return p.fee + p.rent
+}
+
+// dialSolana returns a client for the first RPC endpoint that answers.
+func dialSolana(ctx context.Context, rpcs []string) (*rpc.Client, error) {
+	var errs []error
+	for _, endpoint := range rpcs {
+		client := rpc.New(endpoint)
+		if _, err := client.GetHealth(ctx); err != nil {
+			client.Close()
+			errs = append(errs, err)
+			continue
+		}
+		return client, nil
+	}
+	return nil, fmt.Errorf("%w: %v", blockchain.ErrChainUnavailable, errors.Join(errs...))
+}
+
+// planSolanaTransfer builds the transfer on the latest blockhash. SPL
+// transfers go between associated token accounts of the classic token
+// program and create the destination's when it does not exist yet.
+func planSolanaTransfer(ctx context.Context, client *rpc.Client, transfer solanaTransfer) (*solanaPlan, error) {
+	balance, err := client.GetBalance(ctx, transfer.from, rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	plan := &solanaPlan{amount: transfer.amount, balance: balance.Value}
+
+	if plan.rentExemptMin, err = client.GetMinimumBalanceForRentExemption(ctx, 0, rpc.CommitmentConfirmed); err != nil {
+		return nil, err
+	}
+
+	units := uint32(constants.SOLANA_NATIVE_TRANSFER_UNITS)
+	writable := solana.PublicKeySlice{transfer.from, transfer.to}
+	var transferInstructions []solana.Instruction
+	var build func(amount uint64) solana.Instruction
+
+	if transfer.mint == nil {
+		if plan.amount == nil {
+			// Sized with the whole balance, the fee is taken off below.
+			plan.amount = new(big.Int).SetUint64(plan.balance)
+		}
+		build = func(amount uint64) solana.Instruction {
+			return system.NewTransferInstruction(amount, transfer.from, transfer.to).Build()
+		}
+	} else {
+		units = constants.SOLANA_TOKEN_TRANSFER_UNITS
+		mint := *transfer.mint
+
+		source, _, err := solana.FindAssociatedTokenAddress(transfer.from, mint)
+		if err != nil {
+			return nil, err
+		}
+		destination, _, err := solana.FindAssociatedTokenAddress(transfer.to, mint)
+		if err != nil {
+			return nil, err
+		}
+		writable = solana.PublicKeySlice{transfer.from, source, destination}
+
+		if _, err := client.GetAccountInfo(ctx, source); errors.Is(err, rpc.ErrNotFound) {
+			return nil, blockchain.ErrNothingToSweep
+		} else if err != nil {
+			return nil, err
+		}
+		held, err := client.GetTokenAccountBalance(ctx, source, rpc.CommitmentConfirmed)
+		if err != nil {
+			return nil, err
+		}
+		if plan.amount == nil {
+			var ok bool
+			if plan.amount, ok = new(big.Int).SetString(held.Value.Amount, 10); !ok {
+				return nil, fmt.Errorf("solana: invalid token balance %q", held.Value.Amount)
+			}
+		}
+
+		if _, err := client.GetAccountInfo(ctx, destination); errors.Is(err, rpc.ErrNotFound) {
+			if plan.rent, err = client.GetMinimumBalanceForRentExemption(ctx, constants.SOLANA_TOKEN_ACCOUNT_SIZE, rpc.CommitmentConfirmed); err != nil {
+				return nil, err
+			}
+			transferInstructions = append(transferInstructions, associatedtokenaccount.NewCreateInstruction(transfer.from, transfer.to, mint).Build())
+		} else if err != nil {
+			return nil, err
+		}
+
+		decimals := held.Value.Decimals
+		build = func(amount uint64) solana.Instruction {
+			return token.NewTransferCheckedInstruction(amount, decimals, source, mint, destination, transfer.from, nil).Build()
+		}
+	}
+
+	if plan.amount.Sign() <= 0 {
+		return nil, blockchain.ErrNothingToSweep
+	}
+	if !plan.amount.IsUint64() {
+		return nil, fmt.Errorf("solana: amount %s out of range", plan.amount)
+	}
+
+	instructions := []solana.Instruction{
+		computebudget.NewSetComputeUnitLimitInstruction(units).Build(),
+		computebudget.NewSetComputeUnitPriceInstruction(solanaPriorityFee(ctx, client, writable)).Build(),
+	}
+	instructions = append(instructions, transferInstructions...)
+
+	blockhash, err := client.GetLatestBlockhash(ctx, rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	plan.lastValid = blockhash.Value.LastValidBlockHeight
+
+	newTx := func(amount uint64) (*solana.Transaction, error) {
+		return solana.NewTransaction(append(slices.Clone(instructions), build(amount)), blockhash.Value.Blockhash, solana.TransactionPayer(transfer.from))
+	}
+	if plan.tx, err = newTx(plan.amount.Uint64()); err != nil {
+		return nil, err
+	}
+
+	message, err := plan.tx.Message.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	fee, err := client.GetFeeForMessage(ctx, base64.StdEncoding.EncodeToString(message), rpc.CommitmentConfirmed)
+	if err != nil {
+		return nil, err
+	}
+	if fee.Value == nil {
+		return nil, errors.New("solana: blockhash expired while pricing the transfer")
+	}
+	plan.fee = *fee.Value
+
+	if transfer.mint == nil && transfer.amount == nil {
+		if plan.balance <= plan.cost() {
+			return nil, blockchain.ErrNothingToSweep
+		}
+		plan.amount.SetUint64(plan.balance - plan.cost())
+		if plan.tx, err = newTx(plan.amount.Uint64()); err != nil {
+			return nil, err
+		}
+	}
+
+	return plan, nil
+}
+
+// solanaPriorityFee is the median fee recent transactions touching the same
+// accounts paid per compute unit, capped at SOLANA_MAX_PRIORITY_FEE.
+func solanaPriorityFee(ctx context.Context, client *rpc.Client, accounts solana.PublicKeySlice) uint64 {
+	recent, err := client.GetRecentPrioritizationFees(ctx, accounts)
+	if err != nil {
+		log.Printf("[solana] priority fee lookup failed: %v\n", err)
+		return 0
+	}
+	if len(recent) == 0 {
+		return 0
+	}
+
+	fees := make([]uint64, len(recent))
+	for i, r := range recent {
+		fees[i] = r.PrioritizationFee
+	}
+	slices.Sort(fees)
+	return min(fees[len(fees)/2], constants.SOLANA_MAX_PRIORITY_FEE)
+}
+
+// sendSolanaTransfer signs the transfer with the signer's key at path,
+// sends it and polls until it is confirmed. When the blockhash expires
+// first the transfer cannot land anymore and is sent again on a fresh one.
+// A transfer that may have been sent is returned with an ErrSendUnknown.
+func sendSolanaTransfer(ctx context.Context, client *rpc.Client, signer blockchain.Signer, path string, transfer solanaTransfer) (*blockchain.TransactionResult, error) {
+	for attempt := 1; ; attempt++ {
+		plan, err := planSolanaTransfer(ctx, client, transfer)
+		if err != nil {
+			return nil, err
+		}
+
+		needed := plan.cost()
+		if transfer.mint == nil {
+			needed += plan.amount.Uint64()
+		}
+		if left := int64(plan.balance) - int64(needed); left < 0 || (left > 0 && uint64(left) < plan.rentExemptMin) {
+			return nil, blockchain.ErrInsufficientGas
+		}
+
+		// The sender pays the fee, the only signature the transfer needs.
+		message, err := plan.tx.Message.MarshalBinary()
+		if err != nil {
+			return nil, err
+		}
+		signed, err := signer.Sign(ctx, blockchain.Ed25519, path, message)
+		if err != nil {
+			return nil, err
+		}
+		plan.tx.Signatures = []solana.Signature{solana.SignatureFromBytes(signed)}
+
+		result := &blockchain.TransactionResult{
+			TxHash: plan.tx.Signatures[0].String(),
+			Fee:    new(big.Int).SetUint64(plan.cost()),
+		}
+		signature, err := client.SendTransactionWithOpts(ctx, plan.tx, rpc.TransactionOpts{PreflightCommitment: rpc.CommitmentConfirmed})
+		if err != nil {
+			return result, fmt.Errorf("%w: %s: %v", blockchain.ErrSendUnknown, result.TxHash, err)
+		}
+
+		err = awaitSolanaSignature(ctx, client, signature, plan.lastValid)
+		if errors.Is(err, errSolanaBlockhashExpired) && attempt < constants.SOLANA_SEND_ATTEMPTS {
+			log.Printf("[solana] %s expired before landing, resending\n", signature)
+			continue
+		}
+		if errors.Is(err, errSolanaBlockhashExpired) || errors.Is(err, errSolanaFailed) {
+			return nil, err
+		}
+		if err != nil {
+			// Sent, but not seen confirmed yet.
+			return result, fmt.Errorf("%w: %s: %v", blockchain.ErrSendUnknown, signature, err)
+		}
+
+		result.Success = true
+		return result, nil
+	}
+}
+
+var (
+	errSolanaBlockhashExpired = errors.New("solana: blockhash expired before the transaction landed")
+	errSolanaFailed           = errors.New("solana: transaction failed")
+)
+
+// awaitSolanaSignature polls getSignatureStatuses until the transaction is
+// confirmed, failed, or its blockhash is past lastValid.
+func awaitSolanaSignature(ctx context.Context, client *rpc.Client, signature solana.Signature, lastValid uint64) error {
+	for {
+		statuses, err := client.GetSignatureStatuses(ctx, false, signature)
+		if err == nil && len(statuses.Value) > 0 && statuses.Value[0] != nil {
+			status := statuses.Value[0]
+			if status.Err != nil {
+				return fmt.Errorf("%w: %s: %v", errSolanaFailed, signature, status.Err)
+			}
+			if status.ConfirmationStatus == rpc.ConfirmationStatusConfirmed || status.ConfirmationStatus == rpc.ConfirmationStatusFinalized {
+				return nil
+			}
+		} else if height, err := client.GetBlockHeight(ctx, rpc.CommitmentConfirmed); err == nil && height > lastValid {
+			return errSolanaBlockhashExpired
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(constants.SOLANA_CONFIRM_POLL):
+		}
+	}
+}
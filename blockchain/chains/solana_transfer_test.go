@@ -0,0 +1,117 @@
+package chains
+
+import (
+	"context"
+	"core/blockchain"
+	"encoding/binary"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gagliardetto/solana-go"
+)
+
+func Test_SolanaTokenSweepCreatesAccountAndResendsOnExpiry(t *testing.T) {
+	chain := NewSolanaChain()
+	chain.SetSigner(newFakeSigner())
+	wallet, err := chain.CreateHDWallet(context.Background(), 0, 1)
+	if err != nil {
+		t.Fatalf("CreateHDWallet: %v", err)
+	}
+	owner := solana.NewWallet().PublicKey()
+	mint := solana.NewWallet().PublicKey()
+	source, _, _ := solana.FindAssociatedTokenAddress(solana.MustPublicKeyFromBase58(wallet.Address), mint)
+
+	var sent []*solana.Transaction
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage   `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		slot := map[string]interface{}{"slot": 1}
+		var result interface{}
+		switch req.Method {
+		case "getHealth":
+			result = "ok"
+		case "getBalance":
+			result = map[string]interface{}{"context": slot, "value": 3_000_000}
+		case "getMinimumBalanceForRentExemption":
+			result = 890_880
+			if string(req.Params[0]) == "165" {
+				result = 2_039_280
+			}
+		case "getAccountInfo":
+			var account string
+			_ = json.Unmarshal(req.Params[0], &account)
+			var value interface{}
+			if account == source.String() {
+				value = map[string]interface{}{"data": []string{"", "base64"}, "lamports": 2_039_280, "owner": solana.TokenProgramID.String()}
+			}
+			result = map[string]interface{}{"context": slot, "value": value}
+		case "getTokenAccountBalance":
+			result = map[string]interface{}{"context": slot, "value": map[string]interface{}{"amount": "2500000", "decimals": 6}}
+		case "getRecentPrioritizationFees":
+			result = []map[string]interface{}{{"slot": 1, "prioritizationFee": 100}, {"slot": 2, "prioritizationFee": 300}, {"slot": 3, "prioritizationFee": 200}}
+		case "getLatestBlockhash":
+			result = map[string]interface{}{"context": slot, "value": map[string]interface{}{"blockhash": solana.NewWallet().PublicKey().String(), "lastValidBlockHeight": 1000}}
+		case "getFeeForMessage":
+			result = map[string]interface{}{"context": slot, "value": 5_012}
+		case "sendTransaction":
+			var encoded string
+			_ = json.Unmarshal(req.Params[0], &encoded)
+			tx, err := solana.TransactionFromBase64(encoded)
+			if err != nil {
+				t.Errorf("undecodable transaction: %v", err)
+			}
+			sent = append(sent, tx)
+			result = tx.Signatures[0].String()
+		case "getSignatureStatuses":
+			// The first send never lands.
+			var value interface{}
+			if len(sent) > 1 {
+				value = map[string]interface{}{"slot": 2, "confirmationStatus": "confirmed"}
+			}
+			result = map[string]interface{}{"context": slot, "value": []interface{}{value}}
+		case "getBlockHeight":
+			result = 1001
+		default:
+			t.Errorf("unexpected call %s", req.Method)
+		}
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.ID, "result": result})
+	}))
+	defer server.Close()
+
+	chain.RPCHttp = []string{server.URL}
+	token := mint.String()
+
+	result, err := chain.Sweep(context.Background(), *wallet, blockchain.SweepRequest{ToAddress: owner.String(), Token: &token})
+	if err != nil {
+		t.Fatalf("Sweep: %v", err)
+	}
+	if len(sent) != 2 || result.TxHash != sent[1].Signatures[0].String() {
+		t.Fatalf("expected a resend on a fresh blockhash, sent %d", len(sent))
+	}
+	if result.Fee.Int64() != 5_012+2_039_280 {
+		t.Fatalf("Fee = %s", result.Fee)
+	}
+
+	tx := sent[1]
+	if err := tx.VerifySignatures(); err != nil {
+		t.Fatalf("VerifySignatures: %v", err)
+	}
+	var programs []solana.PublicKey
+	for _, instruction := range tx.Message.Instructions {
+		programs = append(programs, tx.Message.AccountKeys[instruction.ProgramIDIndex])
+	}
+	if len(programs) != 4 || !programs[0].Equals(solana.ComputeBudget) || !programs[2].Equals(solana.SPLAssociatedTokenAccountProgramID) || !programs[3].Equals(solana.TokenProgramID) {
+		t.Fatalf("unexpected programs %v", programs)
+	}
+	data := tx.Message.Instructions[3].Data
+	if amount := binary.LittleEndian.Uint64(data[1:9]); amount != 2_500_000 || data[9] != 6 {
+		t.Fatalf("transferChecked amount %d decimals %d", amount, data[9])
+	}
+}
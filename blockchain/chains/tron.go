@@ -1,37 +1,41 @@
 package chains
 
 import (
-	"bytes"
 	"context"
 	blockchain "core/blockchain"
 	"core/constants"
+	"core/helpers"
 	"core/models"
 	"encoding/hex"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log"
-	"net/http"
+	"math/big"
+	"os"
+	"strings"
 	"sync"
-	"time"
 
 	"github.com/btcsuite/btcd/btcec/v2"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/okx/go-wallet-sdk/coins/tron"
 	tronSDK "github.com/okx/go-wallet-sdk/coins/tron"
 )
 
 type TronChain struct {
 	blockchain.BaseChain
+
+	api *tronAPI
 }
 
 func NewTronChain() *TronChain {
 	return &TronChain{
-		blockchain.BaseChain{
+		BaseChain: blockchain.BaseChain{
 			ID:          constants.TRON,
 			ChainName:   "tron",
 			ExplorerURL: "https://tronscan.org/",
 			RPCHttp:     []string{"https://api.trongrid.io/jsonrpc", "https://rpc.ankr.com/tron_jsonrpc"},
 		},
+		api: newTronAPI([]string{"https://api.trongrid.io"}, os.Getenv("TRONGRID_API_KEY")),
 	}
 }
 
@@ -65,16 +69,11 @@ func (s *TronChain) ValidateAddress(address string) bool {
 func (s *TronChain) Create(ctx context.Context) (*blockchain.WalletDetails, error) {
 	fmt.Printf("[%s]: Creating wallet\n", s.Name())
 
-	mnemonic, err := s.BaseChain.GenerateMnemonicPhrase()
-	if err != nil {
-		return nil, err
-	}
-
-	hdPath := s.BaseChain.GetDerivedPath(44, 195, 0, 0, 0)
-	privateKey, err := s.BaseChain.GetDerivedPrivateKey(mnemonic, hdPath)
+	key, err := crypto.GenerateKey()
 	if err != nil {
 		return nil, err
 	}
+	privateKey := hex.EncodeToString(crypto.FromECDSA(key))
 	address, err := s.NewAddress(privateKey)
 	if err != nil {
 		log.Printf("[%s] NewAddress error:%s \n", s.BaseChain.Name(), err.Error())
@@ -86,29 +85,27 @@ func (s *TronChain) Create(ctx context.Context) (*blockchain.WalletDetails, erro
 	}
 
 	return &blockchain.WalletDetails{
-		Address:        address,
-		PrivateKey:     privateKey,
-		MnemonicPhrase: mnemonic,
+		Address:    address,
+		PrivateKey: privateKey,
 	}, nil
 }
 
+func (s *TronChain) DerivationPath(hdAccountId, hdWalletId int) string {
+	return s.BaseChain.GetDerivedPath(44, 195, 0, hdAccountId, hdWalletId)
+}
+
 func (s *TronChain) CreateHDWallet(ctx context.Context, hdAccountId, hdWalletId int) (*blockchain.WalletDetails, error) {
 	fmt.Printf("[%s]: Creating HD wallet\n", s.Name())
 
-	mnemonic, err := s.BaseChain.GetMnemonic()
+	hdPath := s.DerivationPath(hdAccountId, hdWalletId)
+	public, err := s.BaseChain.PublicKey(ctx, blockchain.Secp256k1, hdPath)
 	if err != nil {
 		return nil, err
 	}
-
-	hdPath := s.BaseChain.GetDerivedPath(44, 195, 0, hdAccountId, hdWalletId)
-	privateKey, err := s.BaseChain.GetDerivedPrivateKey(mnemonic, hdPath)
+	address, err := tronAddress(public)
 	if err != nil {
 		return nil, err
 	}
-	address, err := s.NewAddress(privateKey)
-	if err != nil {
-		log.Printf("[%s] NewAddress error:%s \n", s.BaseChain.Name(), err.Error())
-	}
 
 	if !s.ValidateAddress(address) {
 		return nil, errors.New("invalid tron address format")
@@ -117,8 +114,7 @@ func (s *TronChain) CreateHDWallet(ctx context.Context, hdAccountId, hdWalletId
 
 	return &blockchain.WalletDetails{
 		Address:        address,
-		PrivateKey:     privateKey,
-		MnemonicPhrase: mnemonic,
+		DerivationPath: hdPath,
 	}, nil
 }
 
@@ -127,28 +123,138 @@ func (s *TronChain) Deposit(ctx context.Context, wallet blockchain.WalletDetails
 	return &blockchain.TransactionResult{TxHash: "DepositTxHash", Success: true}, nil
 }
 
-func (s *TronChain) Withdraw(ctx context.Context, wallet blockchain.WalletDetails, amount float64, toAddress string) (*blockchain.TransactionResult, error) {
-	fmt.Printf("[%s]: Withdrawing %f from %s\n", s.Name(), amount, wallet.Address)
-	return &blockchain.TransactionResult{TxHash: "WithdrawTxHash", Success: true}, nil
+func (s *TronChain) CanSend() bool {
+	return true
+}
+
+// Withdraw sends amount sun, or amount of the TRC-20 token, from the
+// wallet to toAddress.
+func (s *TronChain) Withdraw(ctx context.Context, wallet blockchain.WalletDetails, amount *big.Int, token *string, toAddress string) (*blockchain.TransactionResult, error) {
+	if amount == nil || amount.Sign() <= 0 {
+		return nil, helpers.ErrInvalidAmount
+	}
+	if !s.ValidateAddress(toAddress) {
+		return nil, fmt.Errorf("invalid withdrawal destination %q", toAddress)
+	}
+
+	return s.transfer(ctx, wallet, tronTransfer{
+		from:   wallet.Address,
+		to:     toAddress,
+		token:  token,
+		amount: amount,
+	})
+}
+
+func (s *TronChain) BlockNumber(ctx context.Context) (uint64, error) {
+	block, err := s.api.nowBlock(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return uint64(block.number), nil
+}
+
+func (s *TronChain) TxInclusion(ctx context.Context, txHash string) (*blockchain.TxInclusion, error) {
+	info, err := s.api.transactionInfo(ctx, strings.TrimPrefix(strings.ToLower(txHash), "0x"))
+	if err != nil || info == nil {
+		return nil, err
+	}
+	return &blockchain.TxInclusion{
+		BlockNumber: uint64(info.BlockNumber),
+		Success:     info.Result != "FAILED",
+	}, nil
+}
+
+// EstimateSweep quotes the TRX the sender burns for the sweep, with
+// energy delegated from the energy account when that is cheaper. Tron fees
+// are reported in sun at a unit gas price.
+func (s *TronChain) EstimateSweep(ctx context.Context, fromAddress string, request blockchain.SweepRequest) (*blockchain.SweepFee, error) {
+	plan, err := s.planTransfer(ctx, tronTransfer{
+		from:   fromAddress,
+		to:     request.ToAddress,
+		token:  request.Token,
+		amount: request.Amount,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &blockchain.SweepFee{GasLimit: uint64(plan.burn), GasPrice: big.NewInt(1), Balance: plan.balance}, nil
+}
+
+// Sweep sends TRX or a TRC-20 token. request.Fee is not used, Tron prices
+// the transfer again right before sending it.
+func (s *TronChain) Sweep(ctx context.Context, wallet blockchain.WalletDetails, request blockchain.SweepRequest) (*blockchain.TransactionResult, error) {
+	if !s.ValidateAddress(request.ToAddress) {
+		return nil, fmt.Errorf("invalid sweep destination %q", request.ToAddress)
+	}
+
+	return s.transfer(ctx, wallet, tronTransfer{
+		from:   wallet.Address,
+		to:     request.ToAddress,
+		token:  request.Token,
+		amount: request.Amount,
+	})
 }
 
-func (s *TronChain) Sweep(ctx context.Context, wallet blockchain.WalletDetails) (*blockchain.TransactionResult, error) {
-	fmt.Printf("[%s]: Sweeping wallet", s.Name())
-	return &blockchain.TransactionResult{TxHash: "SweepTxHash", Success: true}, nil
+// transfer pays for the transfer as planned, delegating energy first when
+// that is cheaper than burning TRX, and takes the energy back once the
+// transfer is in a block.
+func (s *TronChain) transfer(ctx context.Context, wallet blockchain.WalletDetails, transfer tronTransfer) (*blockchain.TransactionResult, error) {
+	plan, err := s.planTransfer(ctx, transfer)
+	if err != nil {
+		return nil, err
+	}
+
+	needed := big.NewInt(plan.burn)
+	if transfer.token == nil {
+		needed.Add(needed, plan.amount)
+	}
+	if needed.Cmp(plan.balance) > 0 {
+		return nil, blockchain.ErrInsufficientGas
+	}
+	if plan.delegate > 0 {
+		release, err := s.delegate(ctx, transfer.from, plan.delegate)
+		if err != nil {
+			// The transfer itself was not sent, whatever became of the
+			// delegation.
+			return nil, fmt.Errorf("delegating energy: %v", err)
+		}
+		defer release()
+	}
+
+	contract, err := transfer.contract(plan.amount)
+	if err != nil {
+		return nil, err
+	}
+	// Built only now, a transaction prepared before the delegation could
+	// expire while it confirms.
+	txID, err := s.send(ctx, wallet.DerivationPath, contract, plan.feeLimit)
+	if errors.Is(err, blockchain.ErrSendUnknown) {
+		return &blockchain.TransactionResult{TxHash: tronTxHash(txID), Fee: big.NewInt(plan.burn)}, err
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if plan.delegate > 0 {
+		// The delegated energy must stay until the transfer has used it.
+		if _, err := s.api.waitForTransaction(ctx, txID, constants.TRON_CONFIRM_TIMEOUT, constants.TRON_CONFIRM_POLL); err != nil {
+			log.Printf("[%s] %s not seen before undelegating: %v\n", s.Name(), txID, err)
+		}
+	}
+
+	return &blockchain.TransactionResult{TxHash: tronTxHash(txID), Success: true, Fee: big.NewInt(plan.burn)}, nil
 }
 
 func (e *TronChain) BatchBalances(ctx context.Context, addresses []string, workers int) []models.BalanceResult {
 	jobs := make(chan string, len(addresses))
 	results := make(chan models.BalanceResult, len(addresses))
 
-	client := &http.Client{Timeout: 10 * time.Second}
-
 	var wg sync.WaitGroup
 
 	workerFunc := func() {
 		defer wg.Done()
 		for addr := range jobs {
-			balance, err := e.getBalance(client, addr)
+			balance, err := e.getBalance(ctx, addr)
 			results <- models.BalanceResult{
 				Address: addr,
 				Balance: balance,
@@ -179,38 +285,10 @@ func (e *TronChain) BatchBalances(ctx context.Context, addresses []string, worke
 	return out
 }
 
-func (e *TronChain) getBalance(client *http.Client, address string) (string, error) {
-	// en iyi RPC seçimi (round-robin basit)
-	rpc := e.RPCHttp[0]
-
-	reqBody := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"id":      1,
-		"method":  "eth_getBalance",
-		"params":  []interface{}{address, "latest"},
-	}
-
-	data, _ := json.Marshal(reqBody)
-
-	req, _ := http.NewRequest("POST", rpc, bytes.NewReader(data))
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := client.Do(req)
+func (e *TronChain) getBalance(ctx context.Context, address string) (string, error) {
+	balance, err := e.api.balance(ctx, address)
 	if err != nil {
 		return "", err
 	}
-	defer resp.Body.Close()
-
-	var res struct {
-		Result string          `json:"result"`
-		Error  json.RawMessage `json:"error"`
-	}
-	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
-		return "", err
-	}
-	if res.Error != nil {
-		return "", fmt.Errorf("rpc error")
-	}
-
-	return res.Result, nil
+	return balance.String(), nil
 }
@@ -0,0 +1,299 @@
+package chains
+
+import (
+	"bytes"
+	"context"
+	"core/blockchain"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// tronAPI talks to the wallet HTTP API of a TronGrid or full node endpoint.
+// Addresses are exchanged in base58 (visible=true). Transactions are built
+// and signed locally, the node only reads state and relays them.
+type tronAPI struct {
+	urls   []string
+	apiKey string
+	client *http.Client
+}
+
+func newTronAPI(urls []string, apiKey string) *tronAPI {
+	return &tronAPI{
+		urls:   urls,
+		apiKey: apiKey,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// tronReturn is the result envelope of contract calls and broadcasts. The
+// message is hex encoded text.
+type tronReturn struct {
+	Result  bool   `json:"result"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func (r tronReturn) err() error {
+	if r.Result || (r.Code == "" && r.Message == "") {
+		return nil
+	}
+	message := r.Message
+	if decoded, err := hex.DecodeString(message); err == nil {
+		message = string(decoded)
+	}
+	return fmt.Errorf("tron: %s %s", r.Code, message)
+}
+
+type tronAccountResources struct {
+	FreeNetLimit      int64 `json:"freeNetLimit"`
+	FreeNetUsed       int64 `json:"freeNetUsed"`
+	NetLimit          int64 `json:"NetLimit"`
+	NetUsed           int64 `json:"NetUsed"`
+	EnergyLimit       int64 `json:"EnergyLimit"`
+	EnergyUsed        int64 `json:"EnergyUsed"`
+	TotalEnergyLimit  int64 `json:"TotalEnergyLimit"`
+	TotalEnergyWeight int64 `json:"TotalEnergyWeight"`
+}
+
+func (r *tronAccountResources) bandwidth() int64 {
+	return max(r.FreeNetLimit-r.FreeNetUsed, 0) + max(r.NetLimit-r.NetUsed, 0)
+}
+
+func (r *tronAccountResources) energy() int64 {
+	return max(r.EnergyLimit-r.EnergyUsed, 0)
+}
+
+type tronTransactionInfo struct {
+	ID          string `json:"id"`
+	BlockNumber int64  `json:"blockNumber"`
+	Fee         int64  `json:"fee"`
+	Result      string `json:"result"` // "FAILED" on failure, empty otherwise
+	Receipt     struct {
+		Result           string `json:"result"`
+		EnergyUsageTotal int64  `json:"energy_usage_total"`
+		NetUsage         int64  `json:"net_usage"`
+	} `json:"receipt"`
+}
+
+func (a *tronAPI) post(ctx context.Context, path string, body, out interface{}) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, url := range a.urls {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimRight(url, "/")+path, bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if a.apiKey != "" {
+			req.Header.Set("TRON-PRO-API-KEY", a.apiKey)
+		}
+
+		resp, err := a.client.Do(req)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		data, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+			errs = append(errs, fmt.Errorf("%s: http %d", url, resp.StatusCode))
+			continue
+		}
+		if resp.StatusCode != http.StatusOK {
+			return fmt.Errorf("tron %s: http %d: %s", path, resp.StatusCode, data)
+		}
+
+		// Most endpoints report failures in an "Error" field with status 200.
+		var failure struct {
+			Error string `json:"Error"`
+		}
+		if json.Unmarshal(data, &failure) == nil && failure.Error != "" {
+			return fmt.Errorf("tron %s: %s", path, failure.Error)
+		}
+		return json.Unmarshal(data, out)
+	}
+	return fmt.Errorf("%w: %v", blockchain.ErrChainUnavailable, errors.Join(errs...))
+}
+
+func (a *tronAPI) accountResources(ctx context.Context, address string) (*tronAccountResources, error) {
+	var resources tronAccountResources
+	err := a.post(ctx, "/wallet/getaccountresource", map[string]interface{}{
+		"address": address,
+		"visible": true,
+	}, &resources)
+	return &resources, err
+}
+
+// balance is the TRX balance of the account in sun.
+func (a *tronAPI) balance(ctx context.Context, address string) (*big.Int, error) {
+	var account struct {
+		Balance int64 `json:"balance"`
+	}
+	if err := a.post(ctx, "/wallet/getaccount", map[string]interface{}{
+		"address": address,
+		"visible": true,
+	}, &account); err != nil {
+		return nil, err
+	}
+	return big.NewInt(account.Balance), nil
+}
+
+// chainParameters returns the network parameters, e.g. getEnergyFee (sun
+// per energy) and getTransactionFee (sun per bandwidth byte).
+func (a *tronAPI) chainParameters(ctx context.Context) (map[string]int64, error) {
+	var response struct {
+		ChainParameter []struct {
+			Key   string `json:"key"`
+			Value int64  `json:"value"`
+		} `json:"chainParameter"`
+	}
+	if err := a.post(ctx, "/wallet/getchainparameters", map[string]interface{}{}, &response); err != nil {
+		return nil, err
+	}
+
+	params := make(map[string]int64, len(response.ChainParameter))
+	for _, p := range response.ChainParameter {
+		params[p.Key] = p.Value
+	}
+	return params, nil
+}
+
+// constantCall runs a contract call without a transaction and returns its
+// output and the energy it used.
+func (a *tronAPI) constantCall(ctx context.Context, owner, contract, selector string, parameter []byte) ([]byte, int64, error) {
+	var response struct {
+		Result         tronReturn `json:"result"`
+		EnergyUsed     int64      `json:"energy_used"`
+		ConstantResult []string   `json:"constant_result"`
+	}
+	if err := a.post(ctx, "/wallet/triggerconstantcontract", map[string]interface{}{
+		"owner_address":     owner,
+		"contract_address":  contract,
+		"function_selector": selector,
+		"parameter":         hex.EncodeToString(parameter),
+		"visible":           true,
+	}, &response); err != nil {
+		return nil, 0, err
+	}
+	if err := response.Result.err(); err != nil {
+		return nil, 0, err
+	}
+
+	var output []byte
+	if len(response.ConstantResult) > 0 {
+		output, _ = hex.DecodeString(response.ConstantResult[0])
+	}
+	return output, response.EnergyUsed, nil
+}
+
+// delegatableEnergy is how much staked TRX (sun) the owner can still
+// delegate for energy.
+func (a *tronAPI) delegatableEnergy(ctx context.Context, owner string) (int64, error) {
+	var response struct {
+		MaxSize int64 `json:"max_size"`
+	}
+	err := a.post(ctx, "/wallet/getcandelegatedmaxsize", map[string]interface{}{
+		"owner_address": owner,
+		"type":          1, // energy
+		"visible":       true,
+	}, &response)
+	return response.MaxSize, err
+}
+
+// nowBlock is the latest block, the reference of new transactions.
+func (a *tronAPI) nowBlock(ctx context.Context) (*tronBlockRef, error) {
+	var block struct {
+		BlockID     string `json:"blockID"`
+		BlockHeader struct {
+			RawData struct {
+				Number    int64 `json:"number"`
+				Timestamp int64 `json:"timestamp"`
+			} `json:"raw_data"`
+		} `json:"block_header"`
+	}
+	if err := a.post(ctx, "/wallet/getnowblock", map[string]interface{}{}, &block); err != nil {
+		return nil, err
+	}
+
+	id, err := hex.DecodeString(block.BlockID)
+	if err != nil || len(id) != 32 {
+		return nil, fmt.Errorf("tron: invalid block id %q", block.BlockID)
+	}
+	return &tronBlockRef{
+		number:    block.BlockHeader.RawData.Number,
+		id:        id,
+		timestamp: block.BlockHeader.RawData.Timestamp,
+	}, nil
+}
+
+// broadcast relays a signed transaction and returns its id. Without an
+// answer from the node the transaction may have been relayed anyway, its
+// id is returned with an error wrapping ErrSendUnknown.
+func (a *tronAPI) broadcast(ctx context.Context, tx *tronTx) (string, error) {
+	var response tronReturn
+	if err := a.post(ctx, "/wallet/broadcasthex", map[string]interface{}{
+		"transaction": hex.EncodeToString(tx.encode()),
+	}, &response); err != nil {
+		return tx.id(), fmt.Errorf("%w: %s: %v", blockchain.ErrSendUnknown, tronTxHash(tx.id()), err)
+	}
+	if !response.Result {
+		if err := response.err(); err != nil {
+			return "", err
+		}
+		return "", errors.New("tron: transaction not accepted")
+	}
+	return tx.id(), nil
+}
+
+// transactionInfo returns nil while the transaction is not in a block.
+func (a *tronAPI) transactionInfo(ctx context.Context, txID string) (*tronTransactionInfo, error) {
+	var info tronTransactionInfo
+	if err := a.post(ctx, "/wallet/gettransactioninfobyid", map[string]interface{}{
+		"value": txID,
+	}, &info); err != nil {
+		return nil, err
+	}
+	if info.ID == "" {
+		return nil, nil
+	}
+	return &info, nil
+}
+
+// waitForTransaction polls until the transaction is in a block or timeout
+// passes.
+func (a *tronAPI) waitForTransaction(ctx context.Context, txID string, timeout, poll time.Duration) (*tronTransactionInfo, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		info, err := a.transactionInfo(ctx, txID)
+		if err == nil && info != nil {
+			return info, nil
+		}
+		if time.Now().After(deadline) {
+			if err == nil {
+				err = fmt.Errorf("tron: transaction %s not confirmed after %s", txID, timeout)
+			}
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(poll):
+		}
+	}
+}
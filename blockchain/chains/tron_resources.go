@@ -0,0 +1,276 @@
+package chains
+
+import (
+	"context"
+	"core/blockchain"
+	"core/constants"
+	"core/contracts/erc20"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	trc20TransferSelector  = "transfer(address,uint256)"
+	trc20BalanceOfSelector = "balanceOf(address)"
+)
+
+// tronTransfer is a TRX (nil token) or TRC-20 transfer. A nil amount moves
+// the whole balance, less the fee for TRX.
+type tronTransfer struct {
+	from   string
+	to     string
+	token  *string
+	amount *big.Int
+}
+
+// tronResourcePlan is how a transfer pays for its energy and bandwidth:
+// by burning TRX of the sender, or with energy the gateway energy account
+// delegates to the sender for the duration of the transfer.
+type tronResourcePlan struct {
+	amount    *big.Int // resolved transfer amount
+	balance   *big.Int // TRX balance of the sender, in sun
+	energy    int64
+	bandwidth int64
+	burn      int64 // sun the sender burns
+	delegate  int64 // sun of staked TRX to delegate first, 0 to burn instead
+	feeLimit  int64 // most the contract call may burn for energy
+}
+
+// trc20Call is the call data of a TRC-20 method, selector included.
+func trc20Call(method string, args ...interface{}) ([]byte, error) {
+	erc20ABI, err := erc20.ERC20MetaData.GetAbi()
+	if err != nil {
+		return nil, err
+	}
+	return erc20ABI.Pack(method, args...)
+}
+
+// contract is the contract that moves amount.
+func (t tronTransfer) contract(amount *big.Int) (tronContract, error) {
+	owner, err := tronAddressBytes(t.from)
+	if err != nil {
+		return tronContract{}, err
+	}
+	to, err := tronAddressBytes(t.to)
+	if err != nil {
+		return tronContract{}, err
+	}
+	if t.token == nil {
+		if !amount.IsInt64() {
+			return tronContract{}, fmt.Errorf("tron: amount %s out of range", amount)
+		}
+		return newTronTransferContract(owner, to, amount.Int64()), nil
+	}
+
+	token, err := tronAddressBytes(*t.token)
+	if err != nil {
+		return tronContract{}, err
+	}
+	data, err := trc20Call("transfer", common.BytesToAddress(to[1:]), amount)
+	if err != nil {
+		return tronContract{}, err
+	}
+	return newTronTriggerContract(owner, token, data), nil
+}
+
+// planTransfer estimates the resources of the transfer and picks the
+// cheaper way to pay for them.
+func (s *TronChain) planTransfer(ctx context.Context, transfer tronTransfer) (*tronResourcePlan, error) {
+	params, err := s.api.chainParameters(ctx)
+	if err != nil {
+		return nil, err
+	}
+	energyFee := params["getEnergyFee"]
+	bandwidthFee := params["getTransactionFee"]
+
+	sender, err := s.api.accountResources(ctx, transfer.from)
+	if err != nil {
+		return nil, err
+	}
+	balance, err := s.api.balance(ctx, transfer.from)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &tronResourcePlan{amount: transfer.amount, balance: balance}
+
+	// Sized with the whole balance when sweeping TRX, the fee is taken off
+	// the amount afterwards.
+	probe := plan.amount
+	if transfer.token == nil {
+		if probe == nil {
+			probe = balance
+		}
+	} else {
+		if plan.amount == nil {
+			if plan.amount, err = s.trc20Balance(ctx, transfer.from, *transfer.token); err != nil {
+				return nil, err
+			}
+		}
+		probe = plan.amount
+	}
+	if probe.Sign() <= 0 {
+		return nil, blockchain.ErrNothingToSweep
+	}
+
+	contract, err := transfer.contract(probe)
+	if err != nil {
+		return nil, err
+	}
+	if transfer.token != nil {
+		to, _ := tronABIAddress(transfer.to)
+		data, err := trc20Call("transfer", to, probe)
+		if err != nil {
+			return nil, err
+		}
+		// The wallet API takes the selector separately from the arguments.
+		if _, plan.energy, err = s.api.constantCall(ctx, transfer.from, *transfer.token, trc20TransferSelector, data[4:]); err != nil {
+			return nil, err
+		}
+		// A fifth on top of the estimate, the cap is only burned if used.
+		plan.feeLimit = max(plan.energy*energyFee*6/5, constants.TRON_SUN_PER_TRX)
+	}
+
+	ref, err := s.api.nowBlock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	plan.bandwidth = newTronTx(ref, contract, plan.feeLimit, time.Now()).bandwidth()
+	if sender.bandwidth() < plan.bandwidth {
+		// Without enough bandwidth the whole transaction is paid in TRX.
+		plan.burn = plan.bandwidth * bandwidthFee
+	}
+
+	if missing := plan.energy - sender.energy(); missing > 0 {
+		burnEnergy := missing * energyFee
+		sun, cost, ok := s.quoteDelegation(ctx, missing, bandwidthFee)
+		if ok && cost < burnEnergy {
+			plan.delegate = sun
+		} else {
+			plan.burn += burnEnergy
+		}
+	}
+
+	if transfer.token == nil && transfer.amount == nil {
+		plan.amount = new(big.Int).Sub(balance, big.NewInt(plan.burn))
+		if plan.amount.Sign() <= 0 {
+			return nil, blockchain.ErrNothingToSweep
+		}
+	}
+
+	return plan, nil
+}
+
+// quoteDelegation prices lending energy from the energy account: how much
+// staked TRX must be delegated for it and the TRX the delegation and its
+// undelegation burn. ok is false when the account cannot cover it.
+func (s *TronChain) quoteDelegation(ctx context.Context, energy, bandwidthFee int64) (sun, cost int64, ok bool) {
+	wallet, err := s.energyWallet(ctx)
+	if err != nil {
+		log.Printf("[%s] energy account unavailable: %v\n", s.Name(), err)
+		return 0, 0, false
+	}
+
+	resources, err := s.api.accountResources(ctx, wallet.Address)
+	if err != nil || resources.TotalEnergyLimit == 0 {
+		return 0, 0, false
+	}
+
+	// Energy is shared out in proportion to stake: TotalEnergyLimit energy
+	// for TotalEnergyWeight staked TRX.
+	stake := new(big.Int).Mul(big.NewInt(energy), big.NewInt(resources.TotalEnergyWeight))
+	stake.Mul(stake, big.NewInt(constants.TRON_SUN_PER_TRX))
+	limit := big.NewInt(resources.TotalEnergyLimit)
+	stake.Add(stake, new(big.Int).Sub(limit, big.NewInt(1)))
+	stake.Quo(stake, limit)
+	if !stake.IsInt64() {
+		return 0, 0, false
+	}
+	sun = max(stake.Int64(), constants.TRON_MIN_DELEGATION_SUN)
+
+	available, err := s.api.delegatableEnergy(ctx, wallet.Address)
+	if err != nil || available < sun {
+		return 0, 0, false
+	}
+
+	if resources.bandwidth() < constants.TRON_DELEGATION_BANDWIDTH {
+		cost = constants.TRON_DELEGATION_BANDWIDTH * bandwidthFee
+	}
+	return sun, cost, true
+}
+
+// delegate lends the planned energy to receiver and waits until it is
+// usable. The returned function takes it back.
+func (s *TronChain) delegate(ctx context.Context, receiver string, sun int64) (func(), error) {
+	wallet, err := s.energyWallet(ctx)
+	if err != nil {
+		return nil, err
+	}
+	owner, err := tronAddressBytes(wallet.Address)
+	if err != nil {
+		return nil, err
+	}
+	to, err := tronAddressBytes(receiver)
+	if err != nil {
+		return nil, err
+	}
+
+	txID, err := s.send(ctx, wallet.DerivationPath, newTronDelegateContract(owner, to, sun), 0)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.api.waitForTransaction(ctx, txID, constants.TRON_CONFIRM_TIMEOUT, constants.TRON_CONFIRM_POLL); err != nil {
+		return nil, err
+	}
+
+	release := func() {
+		if _, err := s.send(ctx, wallet.DerivationPath, newTronUndelegateContract(owner, to, sun), 0); err != nil {
+			log.Printf("[%s] undelegating %d sun of energy from %s failed: %v\n", s.Name(), sun, receiver, err)
+		}
+	}
+	return release, nil
+}
+
+func (s *TronChain) energyWallet(ctx context.Context) (*blockchain.WalletDetails, error) {
+	return s.CreateHDWallet(ctx, constants.TRON_ENERGY_HD_ACCOUNT, constants.TRON_ENERGY_HD_INDEX)
+}
+
+func (s *TronChain) trc20Balance(ctx context.Context, owner, token string) (*big.Int, error) {
+	address, err := tronABIAddress(owner)
+	if err != nil {
+		return nil, err
+	}
+	data, err := trc20Call("balanceOf", address)
+	if err != nil {
+		return nil, err
+	}
+	output, _, err := s.api.constantCall(ctx, owner, token, trc20BalanceOfSelector, data[4:])
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).SetBytes(output), nil
+}
+
+// send builds the transaction of contract on the latest block, signs it
+// with the signer's key at path and broadcasts it.
+func (s *TronChain) send(ctx context.Context, path string, contract tronContract, feeLimit int64) (string, error) {
+	ref, err := s.api.nowBlock(ctx)
+	if err != nil {
+		return "", err
+	}
+	tx := newTronTx(ref, contract, feeLimit, time.Now())
+	if err := tx.sign(ctx, &s.BaseChain, path); err != nil {
+		return "", err
+	}
+	return s.api.broadcast(ctx, tx)
+}
+
+// tronTxHash formats a transaction id the way the listeners record it.
+func tronTxHash(txID string) string {
+	return "0x" + strings.TrimPrefix(strings.ToLower(txID), "0x")
+}
@@ -0,0 +1,182 @@
+package chains
+
+import (
+	"context"
+	"core/blockchain"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+func tronTestAddress(b byte) string {
+	return base58.CheckEncode([]byte(strings.Repeat(string(rune(b)), 20)), tronAddressPrefix)
+}
+
+// tronStub answers the wallet API calls a transfer makes. The sender is
+// the account for which getaccountresource reports free bandwidth, every
+// other account is the staked energy account.
+type tronStub struct {
+	sender      string
+	delegatable int64
+	broadcast   []byte
+}
+
+func (s *tronStub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var body map[string]interface{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	var response interface{}
+	switch r.URL.Path {
+	case "/wallet/getchainparameters":
+		response = map[string]interface{}{"chainParameter": []map[string]interface{}{
+			{"key": "getEnergyFee", "value": 420},
+			{"key": "getTransactionFee", "value": 1000},
+		}}
+	case "/wallet/getaccountresource":
+		if body["address"] == s.sender {
+			response = map[string]interface{}{"freeNetLimit": 600}
+		} else {
+			response = map[string]interface{}{
+				"TotalEnergyLimit":  180_000_000_000,
+				"TotalEnergyWeight": 10_000_000_000,
+			}
+		}
+	case "/wallet/getaccount":
+		response = map[string]interface{}{"balance": 5_000_000}
+	case "/wallet/triggerconstantcontract":
+		response = map[string]interface{}{
+			"result":          map[string]interface{}{"result": true},
+			"energy_used":     65_000,
+			"constant_result": []string{strings.Repeat("0", 58) + "0f4240"},
+		}
+	case "/wallet/getnowblock":
+		response = map[string]interface{}{
+			"blockID":      strings.Repeat("00", 8) + strings.Repeat("ab", 24),
+			"block_header": map[string]interface{}{"raw_data": map[string]interface{}{"number": 70_000_000, "timestamp": 1_760_000_000_000}},
+		}
+	case "/wallet/getcandelegatedmaxsize":
+		response = map[string]interface{}{"max_size": s.delegatable}
+	case "/wallet/broadcasthex":
+		s.broadcast, _ = hex.DecodeString(body["transaction"].(string))
+		response = map[string]interface{}{"result": true}
+	default:
+		http.NotFound(w, r)
+		return
+	}
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// protoField returns the last bytes or varint field num of a message.
+func protoField(t *testing.T, b []byte, num protowire.Number) (value []byte, varint uint64) {
+	t.Helper()
+	for len(b) > 0 {
+		n, typ, l := protowire.ConsumeTag(b)
+		if l < 0 {
+			t.Fatalf("malformed message")
+		}
+		b = b[l:]
+		switch typ {
+		case protowire.BytesType:
+			v, l := protowire.ConsumeBytes(b)
+			if n == num {
+				value = v
+			}
+			b = b[l:]
+		default:
+			v, l := protowire.ConsumeVarint(b)
+			if n == num {
+				varint = v
+			}
+			b = b[l:]
+		}
+	}
+	return value, varint
+}
+
+func Test_TronPlanTransferDelegatesWhenCheaper(t *testing.T) {
+	sender, treasury, token := tronTestAddress(1), tronTestAddress(2), tronTestAddress(3)
+	stub := &tronStub{sender: sender, delegatable: 10_000 * 1_000_000}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+
+	chain := NewTronChain()
+	chain.SetSigner(newFakeSigner())
+	chain.api = newTronAPI([]string{server.URL}, "")
+	transfer := tronTransfer{from: sender, to: treasury, token: &token}
+
+	// Burning 65000 energy costs 27.3 TRX, delegating it only the 600
+	// bandwidth of the delegation.
+	plan, err := chain.planTransfer(context.Background(), transfer)
+	if err != nil {
+		t.Fatalf("planTransfer: %v", err)
+	}
+	if plan.amount.Int64() != 1_000_000 || plan.burn != 0 || plan.delegate != 3_611_111_112 {
+		t.Fatalf("unexpected plan %+v", plan)
+	}
+
+	// Without enough stake to lend, the energy is burned.
+	stub.delegatable = 0
+	fee, err := chain.EstimateSweep(context.Background(), sender, blockchain.SweepRequest{ToAddress: treasury, Token: &token})
+	if err != nil {
+		t.Fatalf("EstimateSweep: %v", err)
+	}
+	if fee.Total().Int64() != 27_300_000 || fee.Shortfall().Int64() != 22_300_000 {
+		t.Fatalf("unexpected fee %+v", fee)
+	}
+}
+
+func Test_TronWithdrawSignsLocally(t *testing.T) {
+	signer := newFakeSigner()
+	chain := NewTronChain()
+	chain.SetSigner(signer)
+	wallet, err := chain.CreateHDWallet(context.Background(), 0, 1)
+	if err != nil {
+		t.Fatalf("CreateHDWallet: %v", err)
+	}
+	key := signer.secp[wallet.DerivationPath]
+	to := tronTestAddress(2)
+
+	stub := &tronStub{sender: wallet.Address}
+	server := httptest.NewServer(stub)
+	defer server.Close()
+	chain.api = newTronAPI([]string{server.URL}, "")
+
+	result, err := chain.Withdraw(context.Background(), *wallet, big.NewInt(1_500_000), nil, to)
+	if err != nil {
+		t.Fatalf("Withdraw: %v", err)
+	}
+
+	raw, _ := protoField(t, stub.broadcast, 1)
+	signature, _ := protoField(t, stub.broadcast, 2)
+	hash := sha256.Sum256(raw)
+	if result.TxHash != "0x"+hex.EncodeToString(hash[:]) {
+		t.Fatalf("TxHash = %s", result.TxHash)
+	}
+	signedBy, err := crypto.SigToPub(hash[:], signature)
+	if err != nil || crypto.PubkeyToAddress(*signedBy) != crypto.PubkeyToAddress(key.PublicKey) {
+		t.Fatalf("transaction not signed by the wallet: %v", err)
+	}
+
+	contract, _ := protoField(t, raw, 11)
+	if _, kind := protoField(t, contract, 1); kind != tronTransferContract {
+		t.Fatalf("contract type = %d", kind)
+	}
+	parameter, _ := protoField(t, contract, 2)
+	value, _ := protoField(t, parameter, 2)
+	recipient, _ := protoField(t, value, 2)
+	if _, amount := protoField(t, value, 3); amount != 1_500_000 {
+		t.Fatalf("amount = %d", amount)
+	}
+	if base58.CheckEncode(recipient[1:], tronAddressPrefix) != to {
+		t.Fatalf("recipient = %x", recipient)
+	}
+}
@@ -0,0 +1,205 @@
+package chains
+
+import (
+	"context"
+	blockchain "core/blockchain"
+	"core/constants"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const tronAddressPrefix = 0x41
+
+// Contract types of the Tron protocol (core/Tron.proto) the gateway sends.
+const (
+	tronTransferContract           = 1
+	tronTriggerSmartContract       = 31
+	tronDelegateResourceContract   = 57
+	tronUnDelegateResourceContract = 58
+
+	tronResourceEnergy = 1
+)
+
+// tronContract is the single contract of a transaction: its type and the
+// protobuf encoded parameter message.
+type tronContract struct {
+	kind      protowire.Number
+	name      string
+	parameter []byte
+}
+
+// tronBlockRef is the recent block a transaction references. The network
+// drops transactions whose reference block is not on its chain.
+type tronBlockRef struct {
+	number    int64
+	id        []byte
+	timestamp int64 // ms
+}
+
+// tronTx is a transaction built and signed locally, so the node only ever
+// sees it once it is final.
+type tronTx struct {
+	raw        []byte
+	signatures [][]byte
+}
+
+// tronAddressBytes converts a base58 Tron address to the 21 byte form
+// transactions carry.
+func tronAddressBytes(address string) ([]byte, error) {
+	decoded, version, err := base58.CheckDecode(address)
+	if err != nil || version != tronAddressPrefix || len(decoded) != common.AddressLength {
+		return nil, fmt.Errorf("invalid tron address %q", address)
+	}
+	return append([]byte{tronAddressPrefix}, decoded...), nil
+}
+
+// tronAddress is the base58 address of an uncompressed secp256k1 public
+// key.
+func tronAddress(public []byte) (string, error) {
+	key, err := crypto.UnmarshalPubkey(public)
+	if err != nil {
+		return "", err
+	}
+	return base58.CheckEncode(crypto.PubkeyToAddress(*key).Bytes(), tronAddressPrefix), nil
+}
+
+// tronABIAddress converts a base58 Tron address to the 20 byte form
+// contracts see.
+func tronABIAddress(address string) (common.Address, error) {
+	decoded, err := tronAddressBytes(address)
+	if err != nil {
+		return common.Address{}, err
+	}
+	return common.BytesToAddress(decoded[1:]), nil
+}
+
+func newTronTransferContract(owner, to []byte, amount int64) tronContract {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, owner)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, to)
+	b = protowire.AppendTag(b, 3, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(amount))
+	return tronContract{kind: tronTransferContract, name: "TransferContract", parameter: b}
+}
+
+func newTronTriggerContract(owner, contract, data []byte) tronContract {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, owner)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, contract)
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	b = protowire.AppendBytes(b, data)
+	return tronContract{kind: tronTriggerSmartContract, name: "TriggerSmartContract", parameter: b}
+}
+
+func newTronDelegateContract(owner, receiver []byte, sun int64) tronContract {
+	return tronContract{
+		kind:      tronDelegateResourceContract,
+		name:      "DelegateResourceContract",
+		parameter: tronResourceParameter(owner, receiver, sun),
+	}
+}
+
+func newTronUndelegateContract(owner, receiver []byte, sun int64) tronContract {
+	return tronContract{
+		kind:      tronUnDelegateResourceContract,
+		name:      "UnDelegateResourceContract",
+		parameter: tronResourceParameter(owner, receiver, sun),
+	}
+}
+
+// tronResourceParameter encodes the fields delegation and undelegation
+// share. Delegations are not locked, so they can be taken back at once.
+func tronResourceParameter(owner, receiver []byte, sun int64) []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, owner)
+	b = protowire.AppendTag(b, 2, protowire.VarintType)
+	b = protowire.AppendVarint(b, tronResourceEnergy)
+	b = protowire.AppendTag(b, 3, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(sun))
+	b = protowire.AppendTag(b, 4, protowire.BytesType)
+	b = protowire.AppendBytes(b, receiver)
+	return b
+}
+
+// newTronTx builds raw_data for the contract. feeLimit is only set for
+// smart contract calls.
+func newTronTx(ref *tronBlockRef, contract tronContract, feeLimit int64, now time.Time) *tronTx {
+	number := make([]byte, 8)
+	binary.BigEndian.PutUint64(number, uint64(ref.number))
+
+	var parameter []byte
+	parameter = protowire.AppendTag(parameter, 1, protowire.BytesType)
+	parameter = protowire.AppendString(parameter, "type.googleapis.com/protocol."+contract.name)
+	parameter = protowire.AppendTag(parameter, 2, protowire.BytesType)
+	parameter = protowire.AppendBytes(parameter, contract.parameter)
+
+	var c []byte
+	c = protowire.AppendTag(c, 1, protowire.VarintType)
+	c = protowire.AppendVarint(c, uint64(contract.kind))
+	c = protowire.AppendTag(c, 2, protowire.BytesType)
+	c = protowire.AppendBytes(c, parameter)
+
+	var raw []byte
+	raw = protowire.AppendTag(raw, 1, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, number[6:8])
+	raw = protowire.AppendTag(raw, 4, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, ref.id[8:16])
+	raw = protowire.AppendTag(raw, 8, protowire.VarintType)
+	raw = protowire.AppendVarint(raw, uint64(ref.timestamp+constants.TRON_TX_EXPIRATION.Milliseconds()))
+	raw = protowire.AppendTag(raw, 11, protowire.BytesType)
+	raw = protowire.AppendBytes(raw, c)
+	raw = protowire.AppendTag(raw, 14, protowire.VarintType)
+	raw = protowire.AppendVarint(raw, uint64(now.UnixMilli()))
+	if feeLimit > 0 {
+		raw = protowire.AppendTag(raw, 18, protowire.VarintType)
+		raw = protowire.AppendVarint(raw, uint64(feeLimit))
+	}
+
+	return &tronTx{raw: raw}
+}
+
+// id is the transaction id, the sha256 of raw_data.
+func (t *tronTx) id() string {
+	hash := sha256.Sum256(t.raw)
+	return hex.EncodeToString(hash[:])
+}
+
+// bandwidth is what the network charges for the signed transaction.
+func (t *tronTx) bandwidth() int64 {
+	return int64(len(t.raw)) + constants.TRON_SIGNED_TX_OVERHEAD
+}
+
+func (t *tronTx) sign(ctx context.Context, signer blockchain.Signer, path string) error {
+	hash := sha256.Sum256(t.raw)
+	signature, err := signer.Sign(ctx, blockchain.Secp256k1, path, hash[:])
+	if err != nil {
+		return err
+	}
+	t.signatures = append(t.signatures, signature)
+	return nil
+}
+
+// encode is the Transaction message the network accepts.
+func (t *tronTx) encode() []byte {
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendBytes(b, t.raw)
+	for _, signature := range t.signatures {
+		b = protowire.AppendTag(b, 2, protowire.BytesType)
+		b = protowire.AppendBytes(b, signature)
+	}
+	return b
+}
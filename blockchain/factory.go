@@ -6,11 +6,15 @@ import (
 	"sync"
 )
 
-var ErrChainNotFound = errors.New("chain not found")
+var (
+	ErrChainNotFound    = errors.New("chain not found")
+	ErrChainUnavailable = errors.New("chain unavailable")
+)
 
 type ChainFactory struct {
 	mu     sync.RWMutex
 	chains map[string]Chain
+	signer Signer
 }
 
 func NewChainFactory() *ChainFactory {
@@ -51,6 +55,48 @@ func (f *ChainFactory) ListChains() []string {
 	return names
 }
 
+// SetNonceManager has the chains that use nonces reserve them with nonces.
+func (f *ChainFactory) SetNonceManager(nonces *NonceManager) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for _, chain := range f.chains {
+		if user, ok := chain.(interface{ SetNonceManager(*NonceManager) }); ok {
+			user.SetNonceManager(nonces)
+		}
+	}
+}
+
+// SetSigner has every chain derive its HD wallets from and sign with
+// signer.
+func (f *ChainFactory) SetSigner(signer Signer) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.signer = signer
+	for _, chain := range f.chains {
+		if user, ok := chain.(interface{ SetSigner(Signer) }); ok {
+			user.SetSigner(signer)
+		}
+	}
+}
+
+// WatchOnly is whether the signer only derives addresses and cannot sign,
+// so nothing that sends transactions should run.
+func (f *ChainFactory) WatchOnly() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	_, ok := f.signer.(*WatchOnlySigner)
+	return ok
+}
+
+// CloseSigner wipes the keys of a signer that holds them in this process.
+func (f *ChainFactory) CloseSigner() {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	if closer, ok := f.signer.(interface{ Close() }); ok {
+		closer.Close()
+	}
+}
+
 func (f *ChainFactory) CreateWallets(ctx context.Context) (map[string]*WalletDetails, map[string]error) {
 	f.mu.RLock()
 	defer f.mu.RUnlock()
@@ -107,7 +153,7 @@ func (f *ChainFactory) StartAllWorkers(ctx context.Context) map[string]error {
 	return errMap
 }
 
-func (f *ChainFactory) StopAllWorkers(ctx context.Context) map[string]error {
+func (f *ChainFactory) StopAllWorkers() map[string]error {
 	f.mu.RLock()
 	chains := make(map[string]Chain, len(f.chains))
 	for k, v := range f.chains {
@@ -116,12 +162,8 @@ func (f *ChainFactory) StopAllWorkers(ctx context.Context) map[string]error {
 	f.mu.RUnlock()
 	errMap := make(map[string]error)
 	for name, chain := range chains {
-		if starter, ok := chain.(interface {
-			StopWorkers(context.Context) error
-		}); ok {
-			if err := starter.StopWorkers(ctx); err != nil {
-				errMap[name] = err
-			}
+		if err := chain.StopWorkers(); err != nil {
+			errMap[name] = err
 		}
 	}
 	return errMap
@@ -0,0 +1,196 @@
+package blockchain
+
+import (
+	"core/constants"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/okx/go-wallet-sdk/crypto/go-bip39"
+	"golang.org/x/crypto/scrypt"
+)
+
+var ErrWrongPassphrase = errors.New("wrong keystore passphrase")
+
+// Keystore is the gateway's BIP-39 seed encrypted under a key derived from
+// a passphrase with scrypt.
+type Keystore struct {
+	Version int            `json:"version"`
+	ID      string         `json:"id"`
+	Crypto  KeystoreCrypto `json:"crypto"`
+}
+
+type KeystoreCrypto struct {
+	Cipher       string               `json:"cipher"`
+	CipherText   string               `json:"ciphertext"`
+	CipherParams KeystoreCipherParams `json:"cipherparams"`
+	KDF          string               `json:"kdf"`
+	KDFParams    KeystoreScryptParams `json:"kdfparams"`
+}
+
+type KeystoreCipherParams struct {
+	Nonce string `json:"nonce"`
+}
+
+type KeystoreScryptParams struct {
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	DKLen int    `json:"dklen"`
+	Salt  string `json:"salt"`
+}
+
+// EncryptSeed seals seed under passphrase with the scrypt parameters of
+// new keystores.
+func EncryptSeed(seed, passphrase []byte) (*Keystore, error) {
+	return encryptSeed(seed, passphrase, KeystoreScryptParams{
+		N:     constants.KEYSTORE_SCRYPT_N,
+		R:     constants.KEYSTORE_SCRYPT_R,
+		P:     constants.KEYSTORE_SCRYPT_P,
+		DKLen: constants.KEYSTORE_KEY_LENGTH,
+	})
+}
+
+func encryptSeed(seed, passphrase []byte, params KeystoreScryptParams) (*Keystore, error) {
+	salt := make([]byte, constants.KEYSTORE_SALT_LENGTH)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	params.Salt = hex.EncodeToString(salt)
+
+	keystore := &Keystore{
+		Version: constants.KEYSTORE_VERSION,
+		ID:      uuid.NewString(),
+		Crypto: KeystoreCrypto{
+			Cipher:    constants.KEYSTORE_CIPHER,
+			KDF:       constants.KEYSTORE_KDF,
+			KDFParams: params,
+		},
+	}
+
+	aead, err := keystore.cipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	keystore.Crypto.CipherParams.Nonce = hex.EncodeToString(nonce)
+	keystore.Crypto.CipherText = hex.EncodeToString(aead.Seal(nil, nonce, seed, []byte(keystore.ID)))
+	return keystore, nil
+}
+
+// DecryptSeed opens the seed, which the caller wipes once it is done.
+func (k *Keystore) DecryptSeed(passphrase []byte) ([]byte, error) {
+	if k.Version != constants.KEYSTORE_VERSION || k.Crypto.Cipher != constants.KEYSTORE_CIPHER || k.Crypto.KDF != constants.KEYSTORE_KDF {
+		return nil, fmt.Errorf("unsupported keystore version %d with %s and %s", k.Version, k.Crypto.Cipher, k.Crypto.KDF)
+	}
+	nonce, err := hex.DecodeString(k.Crypto.CipherParams.Nonce)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore nonce: %w", err)
+	}
+	sealed, err := hex.DecodeString(k.Crypto.CipherText)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore ciphertext: %w", err)
+	}
+
+	aead, err := k.cipher(passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(nonce) != aead.NonceSize() {
+		return nil, errors.New("invalid keystore nonce length")
+	}
+	seed, err := aead.Open(nil, nonce, sealed, []byte(k.ID))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	return seed, nil
+}
+
+// cipher is AES-GCM under the key scrypt derives from passphrase.
+func (k *Keystore) cipher(passphrase []byte) (cipher.AEAD, error) {
+	params := k.Crypto.KDFParams
+	salt, err := hex.DecodeString(params.Salt)
+	if err != nil {
+		return nil, fmt.Errorf("invalid keystore salt: %w", err)
+	}
+	if params.DKLen != constants.KEYSTORE_KEY_LENGTH {
+		return nil, fmt.Errorf("unsupported keystore key length %d", params.DKLen)
+	}
+
+	key, err := scrypt.Key(passphrase, salt, params.N, params.R, params.P, params.DKLen)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// ReadKeystore reads the keystore file at path.
+func ReadKeystore(path string) (*Keystore, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keystore Keystore
+	if err := json.Unmarshal(data, &keystore); err != nil {
+		return nil, fmt.Errorf("invalid keystore %s: %w", path, err)
+	}
+	return &keystore, nil
+}
+
+// Write stores the keystore at path, readable by the owner only. An
+// existing file is never overwritten.
+func (k *Keystore) Write(path string) error {
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// NewMnemonic is a fresh 24 word BIP-39 mnemonic.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(constants.KEYSTORE_SEED_ENTROPY)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(entropy)
+	return bip39.NewMnemonic(entropy)
+}
+
+// ParseSeed reads a seed given as a BIP-39 mnemonic, derived without
+// passphrase, or as the hex of the seed itself.
+func ParseSeed(input string) ([]byte, error) {
+	input = strings.TrimSpace(input)
+	if mnemonic := strings.Join(strings.Fields(input), " "); bip39.IsMnemonicValid(mnemonic) {
+		return bip39.NewSeed(mnemonic, ""), nil
+	}
+	seed, err := hex.DecodeString(strings.TrimPrefix(input, "0x"))
+	if err != nil || len(seed) < 16 || len(seed) > 64 {
+		return nil, errors.New("neither a valid mnemonic nor a hex seed of 16 to 64 bytes")
+	}
+	return seed, nil
+}
@@ -0,0 +1,53 @@
+package blockchain
+
+import (
+	"bytes"
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func Test_KeystoreSealsSeedUnderPassphrase(t *testing.T) {
+	seed, err := ParseSeed("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Fatal(err)
+	}
+	passphrase := []byte("correct horse battery staple")
+
+	// Cheap scrypt parameters, the defaults take a second per derivation.
+	keystore, err := encryptSeed(seed, passphrase, KeystoreScryptParams{N: 1 << 10, R: 8, P: 1, DKLen: 32})
+	if err != nil {
+		t.Fatalf("encryptSeed: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	if err := keystore.Write(path); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	if err := keystore.Write(path); err == nil {
+		t.Fatal("an existing keystore was overwritten")
+	}
+
+	read, err := ReadKeystore(path)
+	if err != nil {
+		t.Fatalf("ReadKeystore: %v", err)
+	}
+	opened, err := read.DecryptSeed(passphrase)
+	if err != nil || !bytes.Equal(opened, seed) {
+		t.Fatalf("DecryptSeed: %v", err)
+	}
+	if _, err := read.DecryptSeed([]byte("wrong horse battery staple")); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected ErrWrongPassphrase, got %v", err)
+	}
+
+	// The id is authenticated with the seed.
+	read.ID = "another"
+	if _, err := read.DecryptSeed(passphrase); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected a tampered keystore to fail, got %v", err)
+	}
+
+	// An exported hex seed imports to the same seed.
+	hexSeed, err := ParseSeed("0x5eb00bbddcf069084889a8ab9155568165f5c453ccb85e70811aaed6f6da5fc19a5ac40b389cd370d086206dec8aa6c43daea6690f20ad3d8d48b2d2ce9e38e4")
+	if err != nil || !bytes.Equal(hexSeed, seed) {
+		t.Fatalf("hex seed %x: %v", hexSeed, err)
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package blockchain
+
+// lockedBytes cannot lock memory on this platform, the bytes are only
+// wiped when freed.
+func lockedBytes(size int) ([]byte, error) {
+	return make([]byte, size), nil
+}
+
+func freeLockedBytes(b []byte) {
+	wipe(b)
+}
@@ -0,0 +1,31 @@
+//go:build unix
+
+package blockchain
+
+import (
+	"log"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockedBytes allocates size bytes outside the Go heap, where the garbage
+// collector never copies them, and locks them into RAM so they are never
+// swapped to disk. Memory that cannot be locked, past RLIMIT_MEMLOCK, is
+// still handed out.
+func lockedBytes(size int) ([]byte, error) {
+	b, err := unix.Mmap(-1, 0, size, unix.PROT_READ|unix.PROT_WRITE, unix.MAP_ANON|unix.MAP_PRIVATE)
+	if err != nil {
+		return nil, err
+	}
+	if err := unix.Mlock(b); err != nil {
+		log.Printf("[signer] key memory not locked: %v\n", err)
+	}
+	return b, nil
+}
+
+// freeLockedBytes wipes and releases memory from lockedBytes.
+func freeLockedBytes(b []byte) {
+	wipe(b)
+	_ = unix.Munlock(b)
+	_ = unix.Munmap(b)
+}
@@ -0,0 +1,247 @@
+package blockchain
+
+import (
+	"context"
+	"core/constants"
+	"core/models"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// NonceManager hands out the nonces of sender addresses of account-based
+// chains. Reservations live in Postgres, so concurrent sends from one
+// address, in this process or another, never share a nonce and a restart
+// picks up where the last run stopped. The nonce of a transaction that
+// never left is released and handed out again first, so it does not stay a
+// gap holding up every transaction after it.
+type NonceManager struct {
+	db *gorm.DB
+}
+
+func NewNonceManager(db *gorm.DB) *NonceManager {
+	return &NonceManager{db: db}
+}
+
+// Nonce is a reserved nonce. Its transaction is either broadcast or the
+// nonce released.
+type Nonce struct {
+	Value uint64
+
+	manager *NonceManager
+	chainID constants.ChainID
+	address string
+}
+
+// Reserve takes the next nonce of address: the lowest released one the
+// node has not seen used, or the one after the last handed out. pending is
+// the node's transaction count of the address including its mempool, the
+// floor of what may be handed out.
+func (m *NonceManager) Reserve(ctx context.Context, chainID constants.ChainID, chain, address string, pending uint64) (*Nonce, error) {
+	address = strings.ToLower(address)
+	nonce := &Nonce{manager: m, chainID: chainID, address: address}
+
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// The upsert takes the row lock of the address, serializing
+		// reservations until this transaction ends.
+		var next uint64
+		if err := tx.Raw(`
+			INSERT INTO address_nonces (chain_id, address, chain, next, updated_at)
+			VALUES (?, ?, ?, ?, NOW())
+			ON CONFLICT (chain_id, address) DO UPDATE
+			SET next = GREATEST(address_nonces.next, EXCLUDED.next), updated_at = NOW()
+			RETURNING next`, chainID, address, chain, pending).Scan(&next).Error; err != nil {
+			return err
+		}
+
+		var released models.NonceReservation
+		err := tx.Where("chain_id = ? AND address = ? AND status = ? AND nonce >= ?",
+			chainID, address, constants.NONCE_STATUS_RELEASED, pending).
+			Order("nonce ASC").
+			First(&released).Error
+		if err == nil {
+			nonce.Value = released.Nonce
+			return tx.Model(&models.NonceReservation{}).
+				Where("chain_id = ? AND address = ? AND nonce = ?", chainID, address, released.Nonce).
+				Updates(map[string]interface{}{
+					"status":     constants.NONCE_STATUS_RESERVED,
+					"tx_hash":    nil,
+					"updated_at": time.Now(),
+				}).Error
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		nonce.Value = next
+		if err := tx.Model(&models.AddressNonce{}).
+			Where("chain_id = ? AND address = ?", chainID, address).
+			Updates(map[string]interface{}{"next": next + 1, "updated_at": time.Now()}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&models.NonceReservation{
+			ChainID: chainID,
+			Address: address,
+			Nonce:   next,
+			Status:  constants.NONCE_STATUS_RESERVED,
+		}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nonce, nil
+}
+
+// Lookup returns a nonce of address handed out earlier, e.g. to record the
+// replacement of its transaction.
+func (m *NonceManager) Lookup(chainID constants.ChainID, address string, value uint64) *Nonce {
+	return &Nonce{Value: value, manager: m, chainID: chainID, address: strings.ToLower(address)}
+}
+
+// Broadcast records that the transaction using the nonce, or a replacement
+// of it, was sent.
+func (n *Nonce) Broadcast(ctx context.Context, txHash string) error {
+	return n.manager.db.WithContext(ctx).
+		Model(&models.NonceReservation{}).
+		Where("chain_id = ? AND address = ? AND nonce = ? AND status IN ?",
+			n.chainID, n.address, n.Value,
+			[]string{constants.NONCE_STATUS_RESERVED, constants.NONCE_STATUS_BROADCAST}).
+		Updates(map[string]interface{}{
+			"status":     constants.NONCE_STATUS_BROADCAST,
+			"tx_hash":    txHash,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// Release hands the nonce back after its transaction failed before it was
+// broadcast.
+func (n *Nonce) Release(ctx context.Context) error {
+	return n.manager.db.WithContext(ctx).
+		Model(&models.NonceReservation{}).
+		Where("chain_id = ? AND address = ? AND nonce = ? AND status = ?",
+			n.chainID, n.address, n.Value, constants.NONCE_STATUS_RESERVED).
+		Updates(map[string]interface{}{
+			"status":     constants.NONCE_STATUS_RELEASED,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+// Reconcile aligns the reservations of address with pending, the node's
+// transaction count of it including its mempool. Reservations below it are
+// known to the node and forgotten. Above it, only broadcast ones are kept;
+// the nonces between pending and the last of them that no broadcast
+// transaction uses are gaps the node waits on. They are returned and
+// released, so the next reservations fill them.
+func (m *NonceManager) Reconcile(ctx context.Context, chainID constants.ChainID, address string, pending uint64) ([]uint64, error) {
+	address = strings.ToLower(address)
+
+	var gaps []uint64
+	err := m.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var state models.AddressNonce
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&state, "chain_id = ? AND address = ?", chainID, address).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		owned := tx.Where("chain_id = ? AND address = ?", chainID, address)
+		if err := owned.Session(&gorm.Session{}).
+			Where("nonce < ?", pending).
+			Delete(&models.NonceReservation{}).Error; err != nil {
+			return err
+		}
+
+		var broadcast []uint64
+		if err := owned.Session(&gorm.Session{}).
+			Model(&models.NonceReservation{}).
+			Where("status = ?", constants.NONCE_STATUS_BROADCAST).
+			Order("nonce ASC").
+			Pluck("nonce", &broadcast).Error; err != nil {
+			return err
+		}
+		if err := owned.Session(&gorm.Session{}).
+			Where("status <> ?", constants.NONCE_STATUS_BROADCAST).
+			Delete(&models.NonceReservation{}).Error; err != nil {
+			return err
+		}
+
+		state.Next = pending
+		if len(broadcast) > 0 {
+			state.Next = broadcast[len(broadcast)-1] + 1
+		}
+		used := make(map[uint64]bool, len(broadcast))
+		for _, nonce := range broadcast {
+			used[nonce] = true
+		}
+		for nonce := pending; nonce < state.Next; nonce++ {
+			if used[nonce] {
+				continue
+			}
+			gaps = append(gaps, nonce)
+			if err := tx.Create(&models.NonceReservation{
+				ChainID: chainID,
+				Address: address,
+				Nonce:   nonce,
+				Status:  constants.NONCE_STATUS_RELEASED,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		state.UpdatedAt = time.Now()
+		return tx.Save(&state).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return gaps, nil
+}
+
+// pendingNoncer is implemented by chains whose senders use nonces.
+type pendingNoncer interface {
+	PendingNonce(ctx context.Context, address string) (uint64, error)
+}
+
+// ReconcileAll reconciles every address the manager handed out nonces for
+// with its chain, as a restart must before sending again.
+func (m *NonceManager) ReconcileAll(ctx context.Context, chains *ChainFactory) error {
+	var states []models.AddressNonce
+	if err := m.db.WithContext(ctx).Find(&states).Error; err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, state := range states {
+		chain, err := chains.GetChain(state.Chain)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		noncer, ok := chain.(pendingNoncer)
+		if !ok {
+			continue
+		}
+
+		pending, err := noncer.PendingNonce(ctx, state.Address)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		gaps, err := m.Reconcile(ctx, state.ChainID, state.Address, pending)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if len(gaps) > 0 {
+			log.Printf("[nonces] %s %s: gaps at %v, filled by the next transactions\n", state.Chain, state.Address, gaps)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,110 @@
+package blockchain
+
+import (
+	"context"
+	"core/constants"
+	"core/models"
+	"os"
+	"slices"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Test_NonceManagerReserveReleaseReconcile needs a disposable Postgres
+// database in TEST_DATABASE_URL.
+func Test_NonceManagerReserveReleaseReconcile(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.AddressNonce{}, &models.NonceReservation{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	manager := NewNonceManager(db)
+	address := "0x" + uuid.NewString()[:8]
+
+	const workers = 20
+	var wg sync.WaitGroup
+	nonces := make(chan *Nonce, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			nonce, err := manager.Reserve(ctx, constants.Ethereum, "ethereum", address, 5)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			nonces <- nonce
+		}()
+	}
+	wg.Wait()
+	close(nonces)
+
+	var values []uint64
+	reserved := make(map[uint64]*Nonce)
+	for nonce := range nonces {
+		values = append(values, nonce.Value)
+		reserved[nonce.Value] = nonce
+	}
+	slices.Sort(values)
+	for i, value := range values {
+		if value != uint64(5+i) {
+			t.Fatalf("nonces not contiguous from 5: %v", values)
+		}
+	}
+
+	// A released nonce is handed out again before new ones.
+	if err := reserved[9].Release(ctx); err != nil {
+		t.Fatal(err)
+	}
+	again, err := manager.Reserve(ctx, constants.Ethereum, "ethereum", address, 5)
+	if err != nil || again.Value != 9 {
+		t.Fatalf("Reserve after release = %v, %v", again, err)
+	}
+
+	// Only 11 and 12 made it out, the node saw up to 10.
+	for _, value := range []uint64{11, 12} {
+		if err := reserved[value].Broadcast(ctx, "0xhash"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A replacement of 12 takes over its reservation.
+	if err := manager.Lookup(constants.Ethereum, address, 12).Broadcast(ctx, "0xreplacement"); err != nil {
+		t.Fatal(err)
+	}
+	var replaced models.NonceReservation
+	if err := db.First(&replaced, "chain_id = ? AND address = ? AND nonce = ?", constants.Ethereum, address, 12).Error; err != nil {
+		t.Fatal(err)
+	}
+	if replaced.TxHash == nil || *replaced.TxHash != "0xreplacement" {
+		t.Fatalf("replacement not recorded: %v", replaced.TxHash)
+	}
+
+	gaps, err := manager.Reconcile(ctx, constants.Ethereum, address, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(gaps, []uint64{10}) {
+		t.Fatalf("gaps = %v", gaps)
+	}
+	next, err := manager.Reserve(ctx, constants.Ethereum, "ethereum", address, 10)
+	if err != nil || next.Value != 10 {
+		t.Fatalf("gap not filled first: %v, %v", next, err)
+	}
+	after, err := manager.Reserve(ctx, constants.Ethereum, "ethereum", address, 10)
+	if err != nil || after.Value != 13 {
+		t.Fatalf("Reserve after gap = %v, %v", after, err)
+	}
+}
@@ -0,0 +1,289 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/okx/go-wallet-sdk/crypto/go-bip32"
+	"github.com/okx/go-wallet-sdk/crypto/go-bip39"
+)
+
+// SignatureScheme is the curve a key is derived on and signs with.
+type SignatureScheme string
+
+const (
+	// Secp256k1 keys follow BIP-32. They sign 32 byte digests with a 65
+	// byte recoverable signature, r || s || v with v 0 or 1.
+	Secp256k1 SignatureScheme = "secp256k1"
+	// Ed25519 keys follow SLIP-0010, whose paths are hardened only. They
+	// sign the whole message.
+	Ed25519 SignatureScheme = "ed25519"
+)
+
+var (
+	ErrNoSigner              = errors.New("no signer configured")
+	ErrSignerClosed          = errors.New("signer closed")
+	ErrInvalidDerivationPath = errors.New("invalid derivation path")
+)
+
+// Signer holds the gateway's keys. Chains address a key by its derivation
+// path and never see the key or the seed it comes from, so the keys can
+// live in this process, behind a remote signing service or in a hardware
+// module.
+type Signer interface {
+	// PublicKey is the key at path, 65 bytes uncompressed for secp256k1
+	// and 32 bytes for ed25519.
+	PublicKey(ctx context.Context, scheme SignatureScheme, path string) ([]byte, error)
+	// Sign signs payload, a digest for secp256k1 and the message for
+	// ed25519, with the key at path.
+	Sign(ctx context.Context, scheme SignatureScheme, path string, payload []byte) ([]byte, error)
+}
+
+// LocalSigner derives the keys from a BIP-39 seed held in this process. The
+// seed stays sealed under a random key of the signer in locked memory and
+// is only in the clear, in locked memory too, while a key is derived.
+type LocalSigner struct {
+	mu     sync.Mutex
+	aead   cipher.AEAD
+	nonce  []byte
+	sealed []byte
+	opened []byte // room for the seed while it is open
+}
+
+// NewLocalSigner seals a copy of seed, the caller may wipe its own.
+func NewLocalSigner(seed []byte) (*LocalSigner, error) {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	defer wipe(key)
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	sealed, err := lockedBytes(len(seed) + aead.Overhead())
+	if err != nil {
+		return nil, err
+	}
+	opened, err := lockedBytes(len(seed))
+	if err != nil {
+		freeLockedBytes(sealed)
+		return nil, err
+	}
+	aead.Seal(sealed[:0], nonce, seed, nil)
+
+	return &LocalSigner{aead: aead, nonce: nonce, sealed: sealed, opened: opened}, nil
+}
+
+// NewLocalSignerFromMnemonic derives the seed of a BIP-39 mnemonic without
+// passphrase.
+func NewLocalSignerFromMnemonic(mnemonic string) (*LocalSigner, error) {
+	if !bip39.IsMnemonicValid(mnemonic) {
+		return nil, errors.New("invalid mnemonic")
+	}
+	seed := bip39.NewSeed(mnemonic, "")
+	defer wipe(seed)
+	return NewLocalSigner(seed)
+}
+
+// Close wipes and releases the seed, the signer refuses to sign afterwards.
+func (s *LocalSigner) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.aead == nil {
+		return
+	}
+	freeLockedBytes(s.sealed)
+	freeLockedBytes(s.opened)
+	s.sealed, s.opened, s.aead = nil, nil, nil
+}
+
+func (s *LocalSigner) PublicKey(ctx context.Context, scheme SignatureScheme, path string) ([]byte, error) {
+	var public []byte
+	err := s.withKey(scheme, path, func(key []byte) error {
+		switch scheme {
+		case Secp256k1:
+			private, err := crypto.ToECDSA(key)
+			if err != nil {
+				return err
+			}
+			public = crypto.FromECDSAPub(&private.PublicKey)
+		case Ed25519:
+			public = ed25519.NewKeyFromSeed(key).Public().(ed25519.PublicKey)
+		}
+		return nil
+	})
+	return public, err
+}
+
+func (s *LocalSigner) Sign(ctx context.Context, scheme SignatureScheme, path string, payload []byte) ([]byte, error) {
+	var signature []byte
+	err := s.withKey(scheme, path, func(key []byte) error {
+		switch scheme {
+		case Secp256k1:
+			private, err := crypto.ToECDSA(key)
+			if err != nil {
+				return err
+			}
+			signature, err = crypto.Sign(payload, private)
+			return err
+		case Ed25519:
+			private := ed25519.NewKeyFromSeed(key)
+			defer wipe(private)
+			signature = ed25519.Sign(private, payload)
+		}
+		return nil
+	})
+	return signature, err
+}
+
+// withKey opens the seed, derives the private key at path and passes it to
+// use. Seed and key are wiped when use returns.
+func (s *LocalSigner) withKey(scheme SignatureScheme, path string, use func(key []byte) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.aead == nil {
+		return ErrSignerClosed
+	}
+	seed, err := s.aead.Open(s.opened[:0], s.nonce, s.sealed, nil)
+	if err != nil {
+		return err
+	}
+	defer wipe(seed)
+
+	var key []byte
+	switch scheme {
+	case Secp256k1:
+		child, err := bip32Child(seed, path)
+		if err != nil {
+			return err
+		}
+		key = child.Key
+	case Ed25519:
+		if key, err = slip10Ed25519(seed, path); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown signature scheme %q", scheme)
+	}
+	defer wipe(key)
+
+	return use(key)
+}
+
+// ExtendedPublicKey is the BIP-32 extended public key at path, from which
+// a watch-only process derives the keys of its non-hardened children.
+func (s *LocalSigner) ExtendedPublicKey(ctx context.Context, path string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.aead == nil {
+		return "", ErrSignerClosed
+	}
+	seed, err := s.aead.Open(s.opened[:0], s.nonce, s.sealed, nil)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(seed)
+
+	child, err := bip32Child(seed, path)
+	if err != nil {
+		return "", err
+	}
+	defer wipe(child.Key)
+	return child.PublicKey().B58Serialize(), nil
+}
+
+// bip32Child derives the extended private key at path.
+func bip32Child(seed []byte, path string) (*bip32.Key, error) {
+	master, err := bip32.NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	defer wipe(master.Key)
+	child, err := master.NewChildKeyByPathString(path)
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidDerivationPath, path, err)
+	}
+	return child, nil
+}
+
+// slip10Ed25519 derives the ed25519 private key seed at path.
+func slip10Ed25519(seed []byte, path string) ([]byte, error) {
+	segments, err := parseHardenedPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	sum := mac.Sum(nil)
+	key, chainCode := sum[:32], sum[32:]
+
+	for _, segment := range segments {
+		data := make([]byte, 0, 37)
+		data = append(data, 0)
+		data = append(data, key...)
+		data = binary.BigEndian.AppendUint32(data, segment)
+
+		mac := hmac.New(sha512.New, chainCode)
+		mac.Write(data)
+		wipe(data)
+		wipe(sum)
+		sum = mac.Sum(nil)
+		key, chainCode = sum[:32], sum[32:]
+	}
+
+	derived := make([]byte, 32)
+	copy(derived, key)
+	wipe(sum)
+	return derived, nil
+}
+
+// parseHardenedPath reads a path like m/44'/501'/0'/0', every segment of
+// which must be hardened.
+func parseHardenedPath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) < 2 || parts[0] != "m" {
+		return nil, fmt.Errorf("%w %q", ErrInvalidDerivationPath, path)
+	}
+
+	segments := make([]uint32, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		index, ok := strings.CutSuffix(part, "'")
+		if !ok {
+			return nil, fmt.Errorf("%w %q: ed25519 segments must be hardened", ErrInvalidDerivationPath, path)
+		}
+		value, err := strconv.ParseUint(index, 10, 31)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q: %v", ErrInvalidDerivationPath, path, err)
+		}
+		segments = append(segments, uint32(value)|bip32.FirstHardenedChild)
+	}
+	return segments, nil
+}
+
+func wipe(b []byte) {
+	clear(b)
+}
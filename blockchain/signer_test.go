@@ -0,0 +1,57 @@
+package blockchain
+
+import (
+	"context"
+	"crypto/ed25519"
+	"errors"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcutil/base58"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// The keys at these paths of the BIP-39 test mnemonic are the ones the
+// chains derived before the signer held the seed.
+func Test_LocalSignerDerivesKnownKeys(t *testing.T) {
+	signer, err := NewLocalSignerFromMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	path := "m/44'/60'/1'/0/0"
+	public, err := signer.PublicKey(ctx, Secp256k1, path)
+	if err != nil {
+		t.Fatalf("PublicKey: %v", err)
+	}
+	key, err := crypto.UnmarshalPubkey(public)
+	if err != nil || crypto.PubkeyToAddress(*key).Hex() != "0x78839F6054d7ed13918bAe0473BA31b1Ca9D7265" {
+		t.Fatalf("secp256k1 key %x: %v", public, err)
+	}
+	digest := crypto.Keccak256([]byte("sweep"))
+	signature, err := signer.Sign(ctx, Secp256k1, path, digest)
+	if err != nil {
+		t.Fatalf("Sign: %v", err)
+	}
+	if recovered, err := crypto.SigToPub(digest, signature); err != nil || crypto.PubkeyToAddress(*recovered) != crypto.PubkeyToAddress(*key) {
+		t.Fatalf("signature not recoverable to the key: %v", err)
+	}
+
+	path = "m/44'/501'/0'/0'"
+	public, err = signer.PublicKey(ctx, Ed25519, path)
+	if err != nil || base58.Encode(public) != "HAgk14JpMQLgt6rVgv7cBQFJWFto5Dqxi472uT3DKpqk" {
+		t.Fatalf("ed25519 key %s: %v", base58.Encode(public), err)
+	}
+	signature, err = signer.Sign(ctx, Ed25519, path, []byte("message"))
+	if err != nil || !ed25519.Verify(public, []byte("message"), signature) {
+		t.Fatalf("ed25519 signature invalid: %v", err)
+	}
+	if _, err := signer.PublicKey(ctx, Ed25519, "m/44'/501'/0'/0"); !errors.Is(err, ErrInvalidDerivationPath) {
+		t.Fatalf("expected ErrInvalidDerivationPath, got %v", err)
+	}
+
+	signer.Close()
+	if _, err := signer.Sign(ctx, Secp256k1, "m/44'/60'/1'/0/0", digest); !errors.Is(err, ErrSignerClosed) {
+		t.Fatalf("expected ErrSignerClosed, got %v", err)
+	}
+}
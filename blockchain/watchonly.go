@@ -0,0 +1,232 @@
+package blockchain
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/okx/go-wallet-sdk/crypto/go-bip32"
+)
+
+var (
+	ErrWatchOnly = errors.New("watch-only signer cannot sign")
+	ErrNotInPool = errors.New("no pre-generated public key for the path")
+)
+
+// WatchOnlyKeys is what a watch-only process derives its addresses from,
+// exported by the process that holds the seed.
+//
+// secp256k1 chains derive their HD wallets at account-level paths like
+// m/44'/60'/1' followed by non-hardened segments, so one extended public
+// key (xpub, ypub or zpub) of that path yields every deposit address.
+// SLIP-0010 ed25519 paths, Solana's, are hardened to the end and cannot be
+// derived from public keys. Their public keys are pre-generated into a pool
+// for a range of HD accounts and indexes instead, and a wallet beyond the
+// pool gets no address on that chain until the pool is exported again with
+// a larger range.
+type WatchOnlyKeys struct {
+	ExtendedKeys []WatchOnlyExtendedKey `json:"extended_keys"`
+	Ed25519Pool  []WatchOnlyPoolKey     `json:"ed25519_pool"`
+}
+
+type WatchOnlyExtendedKey struct {
+	Path string `json:"path"`
+	Key  string `json:"key"`
+}
+
+type WatchOnlyPoolKey struct {
+	Path      string `json:"path"`
+	PublicKey string `json:"public_key"`
+}
+
+// WatchOnlySigner hands out the public keys of WatchOnlyKeys and holds no
+// private key. Sign always fails with ErrWatchOnly.
+type WatchOnlySigner struct {
+	extended map[string]*bip32.Key
+	pool     map[string][]byte
+}
+
+// NewWatchOnlySigner loads keys. The version bytes of the extended keys are
+// not checked, so the xpub, ypub and zpub serializations of a key all load.
+func NewWatchOnlySigner(keys WatchOnlyKeys) (*WatchOnlySigner, error) {
+	signer := &WatchOnlySigner{
+		extended: make(map[string]*bip32.Key, len(keys.ExtendedKeys)),
+		pool:     make(map[string][]byte, len(keys.Ed25519Pool)),
+	}
+
+	for _, extended := range keys.ExtendedKeys {
+		key, err := bip32.B58Deserialize(extended.Key)
+		if err != nil {
+			return nil, fmt.Errorf("extended key of %s: %w", extended.Path, err)
+		}
+		if key.IsPrivate {
+			return nil, fmt.Errorf("extended key of %s is private", extended.Path)
+		}
+		if depth := strings.Count(extended.Path, "/"); !strings.HasPrefix(extended.Path, "m/") || int(key.Depth) != depth {
+			return nil, fmt.Errorf("extended key of depth %d does not belong to %s", key.Depth, extended.Path)
+		}
+		signer.extended[extended.Path] = key
+	}
+
+	for _, pooled := range keys.Ed25519Pool {
+		public, err := hex.DecodeString(pooled.PublicKey)
+		if err != nil || len(public) != 32 {
+			return nil, fmt.Errorf("invalid ed25519 public key of %s", pooled.Path)
+		}
+		signer.pool[pooled.Path] = public
+	}
+	return signer, nil
+}
+
+func (w *WatchOnlySigner) PublicKey(ctx context.Context, scheme SignatureScheme, path string) ([]byte, error) {
+	switch scheme {
+	case Secp256k1:
+		return w.derive(path)
+	case Ed25519:
+		public, ok := w.pool[path]
+		if !ok {
+			return nil, fmt.Errorf("%w %s", ErrNotInPool, path)
+		}
+		return public, nil
+	}
+	return nil, fmt.Errorf("unknown signature scheme %q", scheme)
+}
+
+func (w *WatchOnlySigner) Sign(ctx context.Context, scheme SignatureScheme, path string, payload []byte) ([]byte, error) {
+	return nil, ErrWatchOnly
+}
+
+// derive walks from the extended key of a parent of path down its
+// non-hardened segments.
+func (w *WatchOnlySigner) derive(path string) ([]byte, error) {
+	parent, rest := path, ""
+	for {
+		if key, ok := w.extended[parent]; ok {
+			child := key
+			for _, segment := range strings.Split(strings.TrimPrefix(rest, "/"), "/") {
+				if segment == "" {
+					continue
+				}
+				index, err := strconv.ParseUint(segment, 10, 31)
+				if err != nil {
+					return nil, fmt.Errorf("%w %q: %s is hardened or invalid", ErrInvalidDerivationPath, path, segment)
+				}
+				if child, err = child.NewChildKey(uint32(index)); err != nil {
+					return nil, err
+				}
+			}
+			public, err := crypto.DecompressPubkey(child.Key)
+			if err != nil {
+				return nil, err
+			}
+			return crypto.FromECDSAPub(public), nil
+		}
+
+		cut := strings.LastIndex(parent, "/")
+		if cut <= 0 {
+			return nil, fmt.Errorf("no extended key is a parent of %s", path)
+		}
+		parent, rest = parent[:cut], parent[cut:]+rest
+	}
+}
+
+// AccountPath is the hardened prefix of path, the deepest key an extended
+// public key of which derives path. It is false for paths hardened to the
+// end.
+func AccountPath(path string) (string, bool) {
+	segments := strings.Split(path, "/")
+	last := len(segments)
+	for last > 1 && !strings.HasSuffix(segments[last-1], "'") {
+		last--
+	}
+	if last == len(segments) {
+		return "", false
+	}
+	return strings.Join(segments[:last], "/"), true
+}
+
+// ReadWatchOnlyKeys reads the watch-only keys file at path.
+func ReadWatchOnlyKeys(path string) (*WatchOnlyKeys, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var keys WatchOnlyKeys
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("invalid watch-only keys %s: %w", path, err)
+	}
+	return &keys, nil
+}
+
+// Write stores the keys at path, readable by the owner only. An existing
+// file is never overwritten.
+func (k *WatchOnlyKeys) Write(path string) error {
+	data, err := json.MarshalIndent(k, "", "  ")
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// ExportWatchOnly collects the watch-only keys of every chain of factory
+// that derives its HD wallets with a DerivationPath method: the extended
+// public key of each account path, and for chains whose paths are hardened
+// to the end the ed25519 public keys of the first accounts HD accounts
+// with their first indexes wallets each.
+func ExportWatchOnly(ctx context.Context, signer *LocalSigner, factory *ChainFactory, accounts, indexes int) (*WatchOnlyKeys, error) {
+	names := factory.ListChains()
+	sort.Strings(names)
+
+	keys := &WatchOnlyKeys{}
+	exported := make(map[string]bool)
+	for _, name := range names {
+		chain, err := factory.GetChain(name)
+		if err != nil {
+			return nil, err
+		}
+		deriver, ok := chain.(interface{ DerivationPath(int, int) string })
+		if !ok {
+			continue
+		}
+
+		if account, ok := AccountPath(deriver.DerivationPath(0, 0)); ok {
+			if exported[account] {
+				continue
+			}
+			extended, err := signer.ExtendedPublicKey(ctx, account)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			keys.ExtendedKeys = append(keys.ExtendedKeys, WatchOnlyExtendedKey{Path: account, Key: extended})
+			exported[account] = true
+			continue
+		}
+
+		for account := 0; account < accounts; account++ {
+			for index := 0; index < indexes; index++ {
+				path := deriver.DerivationPath(account, index)
+				public, err := signer.PublicKey(ctx, Ed25519, path)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+				keys.Ed25519Pool = append(keys.Ed25519Pool, WatchOnlyPoolKey{Path: path, PublicKey: hex.EncodeToString(public)})
+			}
+		}
+	}
+	return keys, nil
+}
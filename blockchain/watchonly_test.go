@@ -0,0 +1,68 @@
+package blockchain
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+)
+
+func Test_WatchOnlySignerDerivesTheSignersKeys(t *testing.T) {
+	signer, err := NewLocalSignerFromMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	account, ok := AccountPath("m/44'/60'/1'/0/0")
+	if !ok || account != "m/44'/60'/1'" {
+		t.Fatalf("account path %q", account)
+	}
+	if _, ok := AccountPath("m/44'/501'/0'/0'"); ok {
+		t.Fatal("hardened path has an account path")
+	}
+	extended, err := signer.ExtendedPublicKey(ctx, account)
+	if err != nil {
+		t.Fatal(err)
+	}
+	solana, err := signer.PublicKey(ctx, Ed25519, "m/44'/501'/0'/0'")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	watcher, err := NewWatchOnlySigner(WatchOnlyKeys{
+		ExtendedKeys: []WatchOnlyExtendedKey{{Path: account, Key: extended}},
+		Ed25519Pool:  []WatchOnlyPoolKey{{Path: "m/44'/501'/0'/0'", PublicKey: hex.EncodeToString(solana)}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, path := range []string{"m/44'/60'/1'/0/0", "m/44'/60'/1'/7/42"} {
+		want, err := signer.PublicKey(ctx, Secp256k1, path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := watcher.PublicKey(ctx, Secp256k1, path)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Fatalf("%s: %x, want %x: %v", path, got, want, err)
+		}
+	}
+	if got, err := watcher.PublicKey(ctx, Ed25519, "m/44'/501'/0'/0'"); err != nil || !bytes.Equal(got, solana) {
+		t.Fatalf("pooled key %x: %v", got, err)
+	}
+
+	if _, err := watcher.PublicKey(ctx, Secp256k1, "m/44'/60'/1'/0'/0"); !errors.Is(err, ErrInvalidDerivationPath) {
+		t.Fatalf("hardened child: %v", err)
+	}
+	if _, err := watcher.PublicKey(ctx, Ed25519, "m/44'/501'/1'/0'"); !errors.Is(err, ErrNotInPool) {
+		t.Fatalf("key beyond the pool: %v", err)
+	}
+	if _, err := watcher.Sign(ctx, Secp256k1, "m/44'/60'/1'/0/0", make([]byte, 32)); !errors.Is(err, ErrWatchOnly) {
+		t.Fatalf("Sign: %v", err)
+	}
+	if _, err := NewWatchOnlySigner(WatchOnlyKeys{ExtendedKeys: []WatchOnlyExtendedKey{{Path: "m/44'/60'", Key: extended}}}); err == nil {
+		t.Fatal("extended key loaded at the wrong depth")
+	}
+}
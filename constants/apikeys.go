@@ -0,0 +1,11 @@
+package constants
+
+import "time"
+
+const (
+	API_KEY_ENV_LIVE = "live"
+	API_KEY_ENV_TEST = "test"
+
+	API_KEY_DEFAULT_GRACE = 24 * time.Hour
+	API_KEY_MAX_GRACE     = 7 * 24 * time.Hour
+)
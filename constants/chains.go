@@ -11,3 +11,15 @@ const (
 	Solana    ChainID = 99999999
 	TRON      ChainID = 99999998
 )
+
+// EVMChains use case-insensitive hex addresses.
+var EVMChains = []ChainID{Ethereum, Binance, Avalanche, Chiliz}
+
+func (c ChainID) IsEVM() bool {
+	for _, evm := range EVMChains {
+		if c == evm {
+			return true
+		}
+	}
+	return false
+}
@@ -11,33 +11,113 @@ type CommandEnvelope struct {
 type CommandType string
 
 const (
-	CMD_MERCHANT_CREATE          CommandType = "merchant.create"
-	CMD_MERCHANT_FETCH           CommandType = "merchant.fetch"
-	CMD_MERCHANT_FETCH_BY_ID     CommandType = "merchant.fetch.by_id"
-	CMD_MERCHANT_FETCH_BY_EMAIL  CommandType = "merchant.fetch.by_email"
-	CMD_MERCHANT_DELETE_BY_ID    CommandType = "merchant.delete.by_id"
-	CMD_MERCHANT_DELETE_BY_EMAIL CommandType = "merchant.delete.by_email"
-	CMD_MERCHANT_DOMAIN_CREATE   CommandType = "merchant.domain.create"
-	CMD_MERCHANT_DOMAIN_FETCH    CommandType = "merchant.domain.fetch"
-	CMD_MERCHANT_WALLET_CREATE   CommandType = "merchant.wallet.create"
-	CMD_DEPOSIT                  CommandType = "system.deposit"
-	CMD_WITHDRAW                 CommandType = "system.withdraw"
-	CMD_SWEEP                    CommandType = "system.sweep"
-	CMD_SCAN                     CommandType = "system.scan"
+	CMD_MERCHANT_CREATE                 CommandType = "merchant.create"
+	CMD_MERCHANT_FETCH_BY_ID            CommandType = "merchant.fetch.by_id"
+	CMD_MERCHANT_FETCH_BY_EMAIL         CommandType = "merchant.fetch.by_email"
+	CMD_MERCHANT_DELETE_BY_ID           CommandType = "merchant.delete.by_id"
+	CMD_MERCHANT_DELETE_BY_EMAIL        CommandType = "merchant.delete.by_email"
+	CMD_MERCHANT_LOGIN                  CommandType = "merchant.login"
+	CMD_MERCHANT_LOGOUT                 CommandType = "merchant.logout"
+	CMD_MERCHANT_TOKEN_REFRESH          CommandType = "merchant.token.refresh"
+	CMD_MERCHANT_PASSWORD_CHANGE        CommandType = "merchant.password.change"
+	CMD_MERCHANT_PASSWORD_RESET         CommandType = "merchant.password.reset"
+	CMD_MERCHANT_PASSWORD_RESET_CONFIRM CommandType = "merchant.password.reset.confirm"
+	CMD_MERCHANT_2FA_ENROLL             CommandType = "merchant.2fa.enroll"
+	CMD_MERCHANT_2FA_CONFIRM            CommandType = "merchant.2fa.confirm"
+	CMD_MERCHANT_2FA_DISABLE            CommandType = "merchant.2fa.disable"
+	CMD_MERCHANT_2FA_RECOVERY_CODES     CommandType = "merchant.2fa.recovery_codes"
+	CMD_MERCHANT_DOMAIN_CREATE          CommandType = "merchant.domain.create"
+	CMD_MERCHANT_DOMAIN_FETCH           CommandType = "merchant.domain.fetch"
+	CMD_MERCHANT_DOMAIN_LIST            CommandType = "merchant.domain.list"
+	CMD_MERCHANT_DOMAIN_UPDATE          CommandType = "merchant.domain.update"
+	CMD_MERCHANT_DOMAIN_ENABLE          CommandType = "merchant.domain.enable"
+	CMD_MERCHANT_DOMAIN_DISABLE         CommandType = "merchant.domain.disable"
+	CMD_MERCHANT_DOMAIN_DELETE          CommandType = "merchant.domain.delete"
+	CMD_MERCHANT_APIKEY_CREATE          CommandType = "merchant.domain.apikey.create"
+	CMD_MERCHANT_APIKEY_LIST            CommandType = "merchant.domain.apikey.list"
+	CMD_MERCHANT_APIKEY_ROTATE          CommandType = "merchant.domain.apikey.rotate"
+	CMD_MERCHANT_APIKEY_REVOKE          CommandType = "merchant.domain.apikey.revoke"
+	CMD_MERCHANT_WALLET_CREATE          CommandType = "merchant.wallet.create"
+	CMD_MERCHANT_WALLET_DERIVE          CommandType = "merchant.wallet.derive"
+	CMD_MERCHANT_WALLET_LIST            CommandType = "merchant.wallet.list"
+	CMD_MERCHANT_WALLET_FETCH_BY_ADDR   CommandType = "merchant.wallet.fetch.by_address"
+	CMD_MERCHANT_WALLET_HISTORY         CommandType = "merchant.wallet.history"
+	CMD_MERCHANT_PAYMENT_CREATE         CommandType = "merchant.payment.create"
+	CMD_MERCHANT_PAYMENT_FETCH          CommandType = "merchant.payment.fetch"
+	CMD_MERCHANT_PAYMENT_LIST           CommandType = "merchant.payment.list"
+	CMD_MERCHANT_BALANCE_LIST           CommandType = "merchant.balance.list"
+	CMD_MERCHANT_LEDGER_STATEMENT       CommandType = "merchant.ledger.statement"
+	CMD_MERCHANT_WITHDRAWAL_FETCH       CommandType = "merchant.withdrawal.fetch"
+	CMD_MERCHANT_WITHDRAWAL_LIST        CommandType = "merchant.withdrawal.list"
+	CMD_MERCHANT_WITHDRAWAL_APPROVE     CommandType = "merchant.withdrawal.approve"
+	CMD_MERCHANT_WITHDRAWAL_REJECT      CommandType = "merchant.withdrawal.reject"
+	CMD_MERCHANT_WITHDRAWAL_THRESHOLD   CommandType = "merchant.withdrawal.threshold.set"
+	CMD_MERCHANT_WITHDRAWAL_SPEED_UP    CommandType = "merchant.withdrawal.speedup"
+	CMD_MERCHANT_WITHDRAWAL_CANCEL      CommandType = "merchant.withdrawal.cancel"
+	CMD_MERCHANT_ALLOWLIST_ADD          CommandType = "merchant.withdrawal.allowlist.add"
+	CMD_MERCHANT_ALLOWLIST_REMOVE       CommandType = "merchant.withdrawal.allowlist.remove"
+	CMD_MERCHANT_ALLOWLIST_LIST         CommandType = "merchant.withdrawal.allowlist.list"
+	CMD_MERCHANT_ALLOWLIST_ENABLE       CommandType = "merchant.withdrawal.allowlist.enable"
+	CMD_MERCHANT_ALLOWLIST_DISABLE      CommandType = "merchant.withdrawal.allowlist.disable"
+	CMD_DEPOSIT                         CommandType = "system.deposit"
+	CMD_WITHDRAW                        CommandType = "system.withdraw"
+	CMD_SWEEP                           CommandType = "system.sweep"
+	CMD_SCAN                            CommandType = "system.scan"
+	CMD_FEES                            CommandType = "system.fees"
 )
 
 var AllCommands = []CommandType{
 	CMD_MERCHANT_CREATE,
-	CMD_MERCHANT_FETCH,
 	CMD_MERCHANT_FETCH_BY_ID,
 	CMD_MERCHANT_FETCH_BY_EMAIL,
+	CMD_MERCHANT_LOGIN,
+	CMD_MERCHANT_LOGOUT,
+	CMD_MERCHANT_TOKEN_REFRESH,
+	CMD_MERCHANT_PASSWORD_CHANGE,
+	CMD_MERCHANT_PASSWORD_RESET,
+	CMD_MERCHANT_PASSWORD_RESET_CONFIRM,
+	CMD_MERCHANT_2FA_ENROLL,
+	CMD_MERCHANT_2FA_CONFIRM,
+	CMD_MERCHANT_2FA_DISABLE,
+	CMD_MERCHANT_2FA_RECOVERY_CODES,
 	CMD_MERCHANT_DOMAIN_CREATE,
 	CMD_MERCHANT_DOMAIN_FETCH,
+	CMD_MERCHANT_DOMAIN_LIST,
+	CMD_MERCHANT_DOMAIN_UPDATE,
+	CMD_MERCHANT_DOMAIN_ENABLE,
+	CMD_MERCHANT_DOMAIN_DISABLE,
+	CMD_MERCHANT_DOMAIN_DELETE,
+	CMD_MERCHANT_APIKEY_CREATE,
+	CMD_MERCHANT_APIKEY_LIST,
+	CMD_MERCHANT_APIKEY_ROTATE,
+	CMD_MERCHANT_APIKEY_REVOKE,
 	CMD_MERCHANT_WALLET_CREATE,
+	CMD_MERCHANT_WALLET_DERIVE,
+	CMD_MERCHANT_WALLET_LIST,
+	CMD_MERCHANT_WALLET_FETCH_BY_ADDR,
+	CMD_MERCHANT_WALLET_HISTORY,
+	CMD_MERCHANT_PAYMENT_CREATE,
+	CMD_MERCHANT_PAYMENT_FETCH,
+	CMD_MERCHANT_PAYMENT_LIST,
+	CMD_MERCHANT_BALANCE_LIST,
+	CMD_MERCHANT_LEDGER_STATEMENT,
+	CMD_MERCHANT_WITHDRAWAL_FETCH,
+	CMD_MERCHANT_WITHDRAWAL_LIST,
+	CMD_MERCHANT_WITHDRAWAL_APPROVE,
+	CMD_MERCHANT_WITHDRAWAL_REJECT,
+	CMD_MERCHANT_WITHDRAWAL_THRESHOLD,
+	CMD_MERCHANT_WITHDRAWAL_SPEED_UP,
+	CMD_MERCHANT_WITHDRAWAL_CANCEL,
+	CMD_MERCHANT_ALLOWLIST_ADD,
+	CMD_MERCHANT_ALLOWLIST_REMOVE,
+	CMD_MERCHANT_ALLOWLIST_LIST,
+	CMD_MERCHANT_ALLOWLIST_ENABLE,
+	CMD_MERCHANT_ALLOWLIST_DISABLE,
 	CMD_DEPOSIT,
 	CMD_WITHDRAW,
 	CMD_SWEEP,
 	CMD_SCAN,
+	CMD_FEES,
 }
 
 func (c CommandType) MarshalJSON() ([]byte, error) {
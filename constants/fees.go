@@ -0,0 +1,49 @@
+package constants
+
+import "time"
+
+// Fee strategies trade confirmation speed for cost.
+const (
+	FEE_STRATEGY_SLOW   = "slow"
+	FEE_STRATEGY_NORMAL = "normal"
+	FEE_STRATEGY_FAST   = "fast"
+)
+
+var FeeStrategies = []string{FEE_STRATEGY_SLOW, FEE_STRATEGY_NORMAL, FEE_STRATEGY_FAST}
+
+func IsFeeStrategy(strategy string) bool {
+	for _, s := range FeeStrategies {
+		if s == strategy {
+			return true
+		}
+	}
+	return false
+}
+
+// Percentile of the priority fees paid in recent blocks each strategy
+// offers on EIP-1559 chains.
+var FeeRewardPercentiles = map[string]float64{
+	FEE_STRATEGY_SLOW:   10,
+	FEE_STRATEGY_NORMAL: 50,
+	FEE_STRATEGY_FAST:   90,
+}
+
+// Share of the node's suggested gas price, in percent, each strategy
+// offers on legacy chains.
+var FeeGasPricePercent = map[string]int64{
+	FEE_STRATEGY_SLOW:   90,
+	FEE_STRATEGY_NORMAL: 100,
+	FEE_STRATEGY_FAST:   125,
+}
+
+const (
+	FEE_HISTORY_BLOCKS = 20
+	FEE_ORACLE_TTL     = 15 * time.Second
+	// The max fee leaves room for the base fee to rise this many times
+	// over before the transaction stops being includable.
+	FEE_BASE_FEE_MULTIPLIER = 2
+
+	// Gas quoted for a plain transfer of the native coin and of a token.
+	EVM_NATIVE_TRANSFER_GAS = 21_000
+	EVM_TOKEN_TRANSFER_GAS  = 65_000
+)
@@ -0,0 +1,34 @@
+package constants
+
+// The seed keystore is laid out after Ethereum's keystore v3, with AES-GCM
+// in place of AES-CTR and its separate MAC.
+const (
+	KEYSTORE_VERSION = 1
+	KEYSTORE_CIPHER  = "aes-256-gcm"
+	KEYSTORE_KDF     = "scrypt"
+)
+
+// scrypt parameters of new keystores, the standard setting of keystore v3.
+// Unlocking takes about a second and 256 MiB.
+const (
+	KEYSTORE_SCRYPT_N    = 1 << 18
+	KEYSTORE_SCRYPT_R    = 8
+	KEYSTORE_SCRYPT_P    = 1
+	KEYSTORE_KEY_LENGTH  = 32
+	KEYSTORE_SALT_LENGTH = 32
+)
+
+const (
+	// Entropy of the mnemonic of a created keystore, 24 words.
+	KEYSTORE_SEED_ENTROPY = 256
+	// Shortest passphrase a new keystore accepts.
+	KEYSTORE_PASSPHRASE_MIN = 12
+)
+
+// Range of the ed25519 public keys a watch-only export pre-generates, for
+// chains whose derivation paths cannot be derived from an extended public
+// key. HD account 0 is the gateway's, merchants count from 1.
+const (
+	WATCH_ONLY_POOL_ACCOUNTS  = 32
+	WATCH_ONLY_POOL_ADDRESSES = 256
+)
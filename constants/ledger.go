@@ -0,0 +1,20 @@
+package constants
+
+// Ledger account types. Merchant accounts hold what the gateway owes each
+// merchant; the others belong to the gateway itself.
+const (
+	LEDGER_ACCOUNT_MERCHANT     = "merchant"
+	LEDGER_ACCOUNT_RESERVED     = "reserved"     // merchant funds held for pending withdrawals
+	LEDGER_ACCOUNT_CUSTODY      = "custody"      // funds held on chain
+	LEDGER_ACCOUNT_FEE_INCOME   = "fee_income"   // service fees charged to merchants
+	LEDGER_ACCOUNT_NETWORK_FEES = "network_fees" // gas paid by the gateway
+)
+
+const (
+	LEDGER_ENTRY_DEPOSIT     = "deposit"
+	LEDGER_ENTRY_WITHDRAWAL  = "withdrawal"
+	LEDGER_ENTRY_HOLD        = "hold"
+	LEDGER_ENTRY_FEE         = "fee"
+	LEDGER_ENTRY_NETWORK_FEE = "network_fee"
+	LEDGER_ENTRY_REVERSAL    = "reversal"
+)
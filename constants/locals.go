@@ -0,0 +1,10 @@
+package constants
+
+// Keys used to pass request scoped values through fiber.Ctx.Locals.
+const (
+	LOCAL_MERCHANT_ID = "merchant_id"
+	LOCAL_SESSION_ID  = "session_id"
+	LOCAL_DOMAIN_ID   = "domain_id"
+	LOCAL_API_KEY_ID  = "api_key_id"
+	LOCAL_ACTION      = "action"
+)
@@ -0,0 +1,65 @@
+package constants
+
+import "time"
+
+// Outgoing transaction lifecycle. The latest attempt of a transaction is
+// pending until an attempt is mined; the attempts it replaced are kept as
+// replaced since any of them may still be the one mined. Once one is mined
+// (or mined and reverted, failed) the others are dropped.
+const (
+	OUTGOING_STATUS_PENDING  = "pending"
+	OUTGOING_STATUS_REPLACED = "replaced"
+	OUTGOING_STATUS_MINED    = "mined"
+	OUTGOING_STATUS_FAILED   = "failed"
+	OUTGOING_STATUS_DROPPED  = "dropped"
+)
+
+// What an outgoing transaction was broadcast for.
+const (
+	OUTGOING_KIND_WITHDRAWAL = "withdrawal"
+	OUTGOING_KIND_SWEEP      = "sweep"
+	OUTGOING_KIND_GAS_TOPUP  = "gas_topup"
+)
+
+// Replacements requested explicitly rather than for being stuck.
+const (
+	OUTGOING_ACTION_SPEED_UP = "speed_up"
+	OUTGOING_ACTION_CANCEL   = "cancel"
+)
+
+const (
+	TX_TRACK_INTERVAL = 30 * time.Second
+
+	// A replacement offers at least this percentage of the fees of the
+	// attempt it replaces; nodes require a bump of more than 10%.
+	TX_REPLACEMENT_BUMP_PERCENT = 125
+	// Stuck transactions are replaced this many times at most, explicit
+	// speed-ups and cancellations are always sent.
+	TX_MAX_REPLACEMENTS = 5
+)
+
+// A transaction is stuck when it is not mined this many blocks after it
+// was broadcast, a few minutes on each chain.
+var TxStuckAfterBlocks = map[ChainID]uint64{
+	Ethereum:  15,
+	Binance:   60,
+	Avalanche: 90,
+	Chiliz:    60,
+}
+
+const TX_STUCK_AFTER_BLOCKS_DEFAULT = 30
+
+func TxStuckAfter(chainID ChainID) uint64 {
+	if blocks, ok := TxStuckAfterBlocks[chainID]; ok {
+		return blocks
+	}
+	return TX_STUCK_AFTER_BLOCKS_DEFAULT
+}
+
+// Nonce reservation lifecycle: reserved -> broadcast, or released when the
+// transaction never left.
+const (
+	NONCE_STATUS_RESERVED  = "reserved"
+	NONCE_STATUS_BROADCAST = "broadcast"
+	NONCE_STATUS_RELEASED  = "released"
+)
@@ -0,0 +1,48 @@
+package constants
+
+import "time"
+
+const (
+	PAYMENT_STATUS_PENDING        = "pending"
+	PAYMENT_STATUS_PARTIALLY_PAID = "partially_paid"
+	PAYMENT_STATUS_PAID           = "paid"
+	PAYMENT_STATUS_OVERPAID       = "overpaid"
+	PAYMENT_STATUS_EXPIRED        = "expired"
+
+	PAYMENT_DEFAULT_EXPIRY = 30 * time.Minute
+	PAYMENT_MIN_EXPIRY     = time.Minute
+	PAYMENT_MAX_EXPIRY     = 7 * 24 * time.Hour
+
+	// A reused deposit address rests this long after its last request
+	// expired, so a late payment for that request lands between windows and
+	// is not taken as a payment of the next one.
+	PAYMENT_REUSE_COOLDOWN = 24 * time.Hour
+
+	PAYMENT_MAX_METADATA = 4096 // bytes, JSON encoded
+
+	// Tolerance around the expected amount, in basis points. Fiat priced
+	// requests default to a small window to absorb rounding by wallets.
+	PAYMENT_DEFAULT_FIAT_TOLERANCE_BPS = 50
+	PAYMENT_MAX_TOLERANCE_BPS          = 1000
+
+	PRICE_QUOTE_TTL = time.Minute
+)
+
+const (
+	FIAT_USD = "USD"
+	FIAT_EUR = "EUR"
+	FIAT_TRY = "TRY"
+
+	FIAT_DECIMALS = 2
+)
+
+var SupportedFiats = []string{FIAT_USD, FIAT_EUR, FIAT_TRY}
+
+func IsSupportedFiat(code string) bool {
+	for _, fiat := range SupportedFiats {
+		if fiat == code {
+			return true
+		}
+	}
+	return false
+}
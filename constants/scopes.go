@@ -0,0 +1,75 @@
+package constants
+
+// Scope groups an API key can carry. A key scope is either one of these
+// groups or the code of a single command.
+const (
+	SCOPE_ALL            = "*"
+	SCOPE_READ           = "read"
+	SCOPE_WALLETS_CREATE = "wallets:create"
+	SCOPE_PAYMENTS       = "payments"
+	SCOPE_WITHDRAW       = "withdraw"
+)
+
+// DefaultScopes are given to a domain's first key and to migrated legacy
+// keys. Withdrawing takes a key created with the withdraw scope.
+var DefaultScopes = []string{SCOPE_READ, SCOPE_WALLETS_CREATE}
+
+var ScopeGroups = map[string][]CommandType{
+	SCOPE_READ: {
+		CMD_MERCHANT_DOMAIN_FETCH,
+		CMD_MERCHANT_WALLET_LIST,
+		CMD_MERCHANT_WALLET_FETCH_BY_ADDR,
+		CMD_MERCHANT_WALLET_HISTORY,
+		CMD_MERCHANT_PAYMENT_FETCH,
+		CMD_MERCHANT_PAYMENT_LIST,
+		CMD_MERCHANT_BALANCE_LIST,
+		CMD_MERCHANT_LEDGER_STATEMENT,
+		CMD_MERCHANT_ALLOWLIST_LIST,
+		CMD_FEES,
+	},
+	SCOPE_WALLETS_CREATE: {
+		CMD_MERCHANT_WALLET_CREATE,
+		CMD_MERCHANT_WALLET_DERIVE,
+	},
+	SCOPE_PAYMENTS: {
+		CMD_MERCHANT_PAYMENT_CREATE,
+		CMD_MERCHANT_PAYMENT_FETCH,
+		CMD_MERCHANT_PAYMENT_LIST,
+		CMD_FEES,
+	},
+	SCOPE_WITHDRAW: {
+		CMD_WITHDRAW,
+		CMD_MERCHANT_WITHDRAWAL_FETCH,
+		CMD_MERCHANT_WITHDRAWAL_LIST,
+	},
+}
+
+func IsKnownScope(scope string) bool {
+	if scope == SCOPE_ALL {
+		return true
+	}
+	if _, ok := ScopeGroups[scope]; ok {
+		return true
+	}
+	for _, cmd := range AllCommands {
+		if cmd.String() == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopesAllow reports whether any of the scopes grants the command.
+func ScopesAllow(scopes []string, cmd CommandType) bool {
+	for _, scope := range scopes {
+		if scope == SCOPE_ALL || scope == cmd.String() {
+			return true
+		}
+		for _, grouped := range ScopeGroups[scope] {
+			if grouped == cmd {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,25 @@
+package constants
+
+import "time"
+
+const (
+	SOLANA_DECIMALS = 9
+
+	// Compute units requested per transfer, the priority fee is paid on
+	// these. Token transfers leave room for creating the destination's
+	// associated token account.
+	SOLANA_NATIVE_TRANSFER_UNITS = 1_000
+	SOLANA_TOKEN_TRANSFER_UNITS  = 60_000
+
+	// Ceiling of the priority fee in micro-lamports per compute unit.
+	SOLANA_MAX_PRIORITY_FEE = 1_000_000
+
+	// Size of an SPL token account, the rent of a created one is paid by
+	// the sender.
+	SOLANA_TOKEN_ACCOUNT_SIZE = 165
+
+	// A transfer is rebuilt on a fresh blockhash this many times when its
+	// blockhash expires before it lands.
+	SOLANA_SEND_ATTEMPTS = 3
+	SOLANA_CONFIRM_POLL  = 2 * time.Second
+)
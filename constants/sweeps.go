@@ -0,0 +1,38 @@
+package constants
+
+import "time"
+
+// Sweep lifecycle: pending -> broadcast -> confirmed, or failed from any
+// step. Token sweeps from addresses without gas detour through funding and
+// back to pending once the top-up confirms. A sweep whose transaction or
+// top-up left but could not be recorded waits in unknown until it is
+// checked on chain. Deposits of a failed sweep are released and swept
+// again later.
+const (
+	SWEEP_STATUS_PENDING   = "pending"
+	SWEEP_STATUS_FUNDING   = "funding"
+	SWEEP_STATUS_BROADCAST = "broadcast"
+	SWEEP_STATUS_CONFIRMED = "confirmed"
+	SWEEP_STATUS_FAILED    = "failed"
+	SWEEP_STATUS_UNKNOWN   = "unknown"
+)
+
+const (
+	SWEEP_INTERVAL      = time.Minute
+	SWEEP_MAX_PER_CHAIN = 10 // sweeps broadcast per chain and tick
+
+	// A pending sweep older than this was interrupted before broadcast.
+	SWEEP_STALE_AFTER = 10 * time.Minute
+	// An address whose sweep failed is left alone for this long.
+	SWEEP_RETRY_AFTER = time.Hour
+	// Gas left on an address after its token sweep is recovered this long
+	// after the sweep confirmed.
+	SWEEP_DUST_RECOVERY_AFTER = 30 * time.Minute
+)
+
+// HD wallet of the gas station that pays for token sweeps, next to the hot
+// wallet on the gateway account.
+const (
+	GAS_WALLET_HD_ACCOUNT = HOT_WALLET_HD_ACCOUNT
+	GAS_WALLET_HD_INDEX   = 1
+)
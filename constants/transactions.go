@@ -0,0 +1,33 @@
+package constants
+
+import "time"
+
+const (
+	TX_STATUS_PENDING   = "pending"
+	TX_STATUS_CONFIRMED = "confirmed"
+	TX_STATUS_FAILED    = "failed"
+	TX_STATUS_ORPHANED  = "orphaned" // its block was dropped by a reorg
+)
+
+const TX_CONFIRM_INTERVAL = 15 * time.Second
+
+// A transfer is final once its block is this many blocks deep, counting
+// its own block, on each chain.
+var TxConfirmationsRequired = map[ChainID]uint64{
+	Bitcoin:   3,
+	Ethereum:  12,
+	Binance:   15,
+	Avalanche: 1,
+	Chiliz:    12,
+	Solana:    32,
+	TRON:      19,
+}
+
+const TX_CONFIRMATIONS_DEFAULT = 12
+
+func TxConfirmations(chainID ChainID) uint64 {
+	if blocks, ok := TxConfirmationsRequired[chainID]; ok {
+		return blocks
+	}
+	return TX_CONFIRMATIONS_DEFAULT
+}
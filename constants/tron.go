@@ -0,0 +1,30 @@
+package constants
+
+import "time"
+
+// Gateway Tron account with TRX staked for energy (Stake 2.0). It delegates
+// energy to deposit and hot wallets right before they send TRC-20 tokens
+// when that is cheaper than burning TRX for it.
+const (
+	TRON_ENERGY_HD_ACCOUNT = HOT_WALLET_HD_ACCOUNT
+	TRON_ENERGY_HD_INDEX   = 2
+)
+
+const (
+	TRON_SUN_PER_TRX = 1_000_000
+
+	// Bytes a signature and the transaction result add on top of raw_data
+	// when the network charges bandwidth.
+	TRON_SIGNED_TX_OVERHEAD = 134
+	// Bandwidth of a delegateresource plus an undelegateresource.
+	TRON_DELEGATION_BANDWIDTH = 600
+	// Smallest amount Stake 2.0 lets an account delegate.
+	TRON_MIN_DELEGATION_SUN = TRON_SUN_PER_TRX
+
+	// How long after its reference block a transaction expires.
+	TRON_TX_EXPIRATION = time.Minute
+
+	// How long to wait for a delegation or transfer to land in a block.
+	TRON_CONFIRM_TIMEOUT = time.Minute
+	TRON_CONFIRM_POLL    = 3 * time.Second
+)
@@ -0,0 +1,47 @@
+package constants
+
+import "time"
+
+// Withdrawal lifecycle: requested -> approved -> signing -> broadcast ->
+// confirmed, with failed reachable from any step before confirmation and
+// rejected only from requested.
+const (
+	WITHDRAWAL_STATUS_REQUESTED = "requested"
+	WITHDRAWAL_STATUS_APPROVED  = "approved"
+	WITHDRAWAL_STATUS_REJECTED  = "rejected"
+	WITHDRAWAL_STATUS_SIGNING   = "signing"
+	WITHDRAWAL_STATUS_BROADCAST = "broadcast"
+	WITHDRAWAL_STATUS_CONFIRMED = "confirmed"
+	WITHDRAWAL_STATUS_FAILED    = "failed"
+)
+
+var WithdrawalTransitions = map[string][]string{
+	WITHDRAWAL_STATUS_REQUESTED: {WITHDRAWAL_STATUS_APPROVED, WITHDRAWAL_STATUS_REJECTED, WITHDRAWAL_STATUS_FAILED},
+	WITHDRAWAL_STATUS_APPROVED:  {WITHDRAWAL_STATUS_SIGNING, WITHDRAWAL_STATUS_FAILED},
+	WITHDRAWAL_STATUS_SIGNING:   {WITHDRAWAL_STATUS_BROADCAST, WITHDRAWAL_STATUS_FAILED},
+	WITHDRAWAL_STATUS_BROADCAST: {WITHDRAWAL_STATUS_CONFIRMED, WITHDRAWAL_STATUS_FAILED},
+}
+
+func CanTransitionWithdrawal(from, to string) bool {
+	for _, next := range WithdrawalTransitions[from] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Who moved a withdrawal to its new state, recorded in the audit log.
+const (
+	ACTOR_SESSION = "session"
+	ACTOR_API_KEY = "api_key"
+	ACTOR_SYSTEM  = "system"
+)
+
+// A new allowlisted destination only becomes usable after this delay, so a
+// stolen credential cannot add an address and pay out to it right away.
+const WITHDRAWAL_ALLOWLIST_COOLDOWN = 24 * time.Hour
+
+// HD account of the gateway hot wallet that signs payouts. Domains are
+// allocated accounts from 1 upwards.
+const HOT_WALLET_HD_ACCOUNT = 0
@@ -21,6 +21,9 @@ const (
 	LivePrefix  = "gw_live"
 	TestPrefix  = "gw_test"
 	SecretPref  = "gw_secret"
+	AccessPref  = "gw_at"
+	RefreshPref = "gw_rt"
+	ResetPref   = "gw_reset"
 	TimeSkewSec = 30
 )
 
@@ -61,6 +64,15 @@ func GenerateSecret() (string, error) {
 	return SecretPref + "_" + r, nil
 }
 
+// GenerateToken returns an opaque bearer token. Only its SHA-256 hash should be persisted.
+func GenerateToken(prefix string) (string, error) {
+	r, err := randomHex(32)
+	if err != nil {
+		return "", err
+	}
+	return prefix + "_" + r, nil
+}
+
 func HashSHA256(value string) string {
 	h := sha256.Sum256([]byte(value))
 	return hex.EncodeToString(h[:])
@@ -105,18 +117,19 @@ func ValidateTimestamp(ts string) error {
 
 func ExtractKeyID(apiKey string) (string, error) {
 
+	// gw_live_<keyID>_<random>
 	parts := strings.Split(apiKey, "_")
-	if len(parts) < 3 {
+	if len(parts) != 4 {
 		return "", errors.New("invalid api key format")
 	}
 
-	return parts[1], nil
+	return parts[2], nil
 }
 
-func EncryptSecret(secret string) (string, error) {
+func masterCipher() (cipher.AEAD, error) {
 	masterKey := os.Getenv("MASTER_KEY")
 	if masterKey == "" {
-		return "", errors.New("MASTER_KEY not set")
+		return nil, errors.New("MASTER_KEY not set")
 	}
 
 	hash := sha256.Sum256([]byte(masterKey))
@@ -124,10 +137,14 @@ func EncryptSecret(secret string) (string, error) {
 
 	block, err := aes.NewCipher(key)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	gcm, err := cipher.NewGCM(block)
+	return cipher.NewGCM(block)
+}
+
+func EncryptSecret(secret string) (string, error) {
+	gcm, err := masterCipher()
 	if err != nil {
 		return "", err
 	}
@@ -141,3 +158,27 @@ func EncryptSecret(secret string) (string, error) {
 
 	return base64.StdEncoding.EncodeToString(ciphertext), nil
 }
+
+func DecryptSecret(encrypted string) (string, error) {
+	gcm, err := masterCipher()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := base64.StdEncoding.DecodeString(encrypted)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < gcm.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+
+	nonce, ciphertext := data[:gcm.NonceSize()], data[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(plaintext), nil
+}
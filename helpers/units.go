@@ -0,0 +1,64 @@
+package helpers
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+)
+
+var ErrInvalidAmount = errors.New("invalid amount")
+
+// ParseUnits converts a decimal amount ("12.5") to base units of an asset
+// with the given decimals. More fractional digits than decimals is an error.
+func ParseUnits(amount string, decimals uint8) (*big.Int, error) {
+	amount = strings.TrimSpace(amount)
+	if amount == "" || strings.HasPrefix(amount, "-") || strings.HasPrefix(amount, "+") {
+		return nil, ErrInvalidAmount
+	}
+
+	whole, frac, _ := strings.Cut(amount, ".")
+	if len(frac) > int(decimals) {
+		return nil, ErrInvalidAmount
+	}
+	if whole == "" {
+		whole = "0"
+	}
+
+	digits := whole + frac + strings.Repeat("0", int(decimals)-len(frac))
+	for _, c := range digits {
+		if c < '0' || c > '9' {
+			return nil, ErrInvalidAmount
+		}
+	}
+
+	units, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return nil, ErrInvalidAmount
+	}
+	return units, nil
+}
+
+// FormatUnits is the inverse of ParseUnits, trailing zeros are trimmed.
+func FormatUnits(units *big.Int, decimals uint8) string {
+	if units == nil {
+		return "0"
+	}
+
+	negative := units.Sign() < 0
+	digits := new(big.Int).Abs(units).String()
+	if len(digits) <= int(decimals) {
+		digits = strings.Repeat("0", int(decimals)-len(digits)+1) + digits
+	}
+
+	whole := digits[:len(digits)-int(decimals)]
+	frac := strings.TrimRight(digits[len(digits)-int(decimals):], "0")
+
+	result := whole
+	if frac != "" {
+		result += "." + frac
+	}
+	if negative {
+		result = "-" + result
+	}
+	return result
+}
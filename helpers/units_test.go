@@ -0,0 +1,40 @@
+package helpers
+
+import "testing"
+
+func Test_ParseUnits(t *testing.T) {
+	cases := []struct {
+		amount   string
+		decimals uint8
+		want     string
+		ok       bool
+	}{
+		{"50", 6, "50000000", true},
+		{"0.000001", 6, "1", true},
+		{".5", 18, "500000000000000000", true},
+		{"1.2345678", 6, "", false},
+		{"-1", 6, "", false},
+		{"1e5", 6, "", false},
+		{"", 6, "", false},
+	}
+
+	for _, c := range cases {
+		units, err := ParseUnits(c.amount, c.decimals)
+		if (err == nil) != c.ok {
+			t.Fatalf("ParseUnits(%q): unexpected error %v", c.amount, err)
+		}
+		if c.ok && units.String() != c.want {
+			t.Fatalf("ParseUnits(%q) = %s, want %s", c.amount, units, c.want)
+		}
+		if c.ok && FormatUnits(units, c.decimals) != trimAmount(c.amount) {
+			t.Fatalf("FormatUnits(%s) = %s, want %s", units, FormatUnits(units, c.decimals), trimAmount(c.amount))
+		}
+	}
+}
+
+func trimAmount(amount string) string {
+	if amount[0] == '.' {
+		return "0" + amount
+	}
+	return amount
+}
@@ -7,7 +7,12 @@ import (
 	"core/helpers"
 	"core/models"
 	"core/types"
+	"core/workers/confirmations"
 	"core/workers/dispatcher"
+	"core/workers/payments"
+	"core/workers/sweeper"
+	"core/workers/tracker"
+	"core/workers/withdrawals"
 	"flag"
 	"fmt"
 	"log"
@@ -16,6 +21,7 @@ import (
 	"syscall"
 
 	coreApplication "core/application"
+	configurations "core/application/configuration"
 	coreDB "core/services/database"
 	"core/workers/listeners/chiliz"
 	"core/workers/listeners/ethereum"
@@ -27,6 +33,21 @@ import (
 func NewApp() (*coreApplication.App, error) {
 	if coreApplication.CORE == nil {
 
+		migrateFlag := flag.Bool("migrate", false, "Run DB migrations")
+		seedFlag := flag.Bool("seed", false, "Run DB seed")
+		installFlag := flag.Bool("install", false, "Run DB migrate & seed")
+		keystoreFlag := flag.String("keystore", "", "create, import, export or watch the seed keystore at KEYSTORE_FILE and exit")
+
+		flag.Parse()
+
+		// Runs before the router, which unlocks the keystore.
+		if *keystoreFlag != "" {
+			if err := configurations.RunKeystoreCommand(*keystoreFlag); err != nil {
+				log.Fatal(err)
+			}
+			os.Exit(0)
+		}
+
 		err := coreDB.InitDB()
 		if err != nil {
 			return nil, err
@@ -37,12 +58,6 @@ func NewApp() (*coreApplication.App, error) {
 			Router: routes.NewRouter(coreDB.DB),
 		}
 
-		migrateFlag := flag.Bool("migrate", false, "Run DB migrations")
-		seedFlag := flag.Bool("seed", false, "Run DB seed")
-		installFlag := flag.Bool("install", false, "Run DB migrate & seed")
-
-		flag.Parse()
-
 		if *installFlag {
 			*seedFlag = true
 			*migrateFlag = true
@@ -86,6 +101,8 @@ func main() {
 	if err != nil {
 		log.Fatal(err)
 	}
+	// Deferred first, the seed is wiped after every worker has stopped.
+	defer coreApplication.CORE.Router.Blockchains().CloseSigner()
 
 	createMerchantParams := types.MerchantParams{
 		Context:        mainCtx,
@@ -203,7 +220,7 @@ func main() {
 	)
 
 	chzWorker := chiliz.NewRpcListener(
-		ethChain,
+		chilizChain,
 		assetRegistry,
 		chilizState,
 		bus,
@@ -213,7 +230,7 @@ func main() {
 	)
 
 	tronWorker := tron.NewRpcListener(
-		ethChain,
+		tronChain,
 		assetRegistry,
 		tronState,
 		bus,
@@ -229,48 +246,94 @@ func main() {
 
 	coreApplication.CORE.Router.Blockchains().StartAllWorkers(mainCtx)
 
-	ethChan := bus.Subscribe(constants.Ethereum, 100)
-	tronChan := bus.Subscribe(constants.TRON, 100)
+	// Every chain's transfers are persisted as pending, the confirmer
+	// credits them once they are deep enough.
+	for _, name := range coreApplication.CORE.Router.Blockchains().ListChains() {
+		chain, err := coreApplication.CORE.Router.Blockchains().GetChain(name)
+		if err != nil {
+			continue
+		}
 
-	go func() {
-		for event := range tronChan {
-			switch event.Type {
+		events := bus.Subscribe(chain.ChainID(), 100)
+		go func() {
+			for event := range events {
+				switch event.Type {
 
-			case "transfer":
-				transaction := event.Transaction
-				fmt.Printf("[TX] : %d \t %s \n", event.Transaction.ChainID, *event.Transaction.Hash)
+				case "transfer":
+					err := coreApplication.CORE.Router.TransactionRepo.Create(*event.Transaction)
+					if err != nil {
+						fmt.Println("Error", err)
+					}
 
-				err := coreApplication.CORE.Router.TransactionRepo.Create(*transaction)
-				if err != nil {
-					fmt.Println("Error", err)
 				}
-
 			}
-		}
+		}()
+	}
 
-		for event := range ethChan {
-			switch event.Type {
+	transferConfirmer := confirmations.NewConfirmer(
+		coreApplication.CORE.Router.TransactionRepo,
+		coreApplication.CORE.Router.Blockchains(),
+		constants.TX_CONFIRM_INTERVAL,
+	)
+	transferConfirmer.Start(mainCtx)
+	defer transferConfirmer.Stop()
+
+	paymentMatcher := payments.NewMatcher(coreApplication.CORE.Router.PaymentRepo, payments.DefaultMatchInterval)
+	paymentMatcher.Start(mainCtx)
+	defer paymentMatcher.Stop()
+
+	// A watch-only process only hands out deposit addresses, the process
+	// holding the keystore sends withdrawals and sweeps.
+	if !coreApplication.CORE.Router.Blockchains().WatchOnly() {
+		if err := coreApplication.CORE.Router.Nonces().ReconcileAll(mainCtx, coreApplication.CORE.Router.Blockchains()); err != nil {
+			log.Printf("[nonces] reconciliation failed: %v\n", err)
+		}
 
-			case "transfer":
-				transaction := event.Transaction
-				//fmt.Printf("[TX] : %d \t %s \n", event.Transaction.ChainID, *event.Transaction.Hash)
+		withdrawalProcessor := withdrawals.NewProcessor(
+			coreApplication.CORE.Router.WithdrawalRepo,
+			coreApplication.CORE.Router.OutgoingTxRepo,
+			withdrawals.NewChainSender(coreApplication.CORE.Router.Blockchains()),
+			withdrawals.DefaultProcessInterval,
+		)
+		withdrawalProcessor.Start(mainCtx)
+		defer withdrawalProcessor.Stop()
+
+		depositSweeper := sweeper.NewSweeper(
+			coreApplication.CORE.Router.SweepRepo,
+			coreApplication.CORE.Router.OutgoingTxRepo,
+			coreApplication.CORE.Router.Blockchains(),
+			configurations.NewSweepPolicies(),
+			constants.SWEEP_INTERVAL,
+		)
+		depositSweeper.Start(mainCtx)
+		defer depositSweeper.Stop()
+
+		txTracker := tracker.NewTracker(
+			coreApplication.CORE.Router.OutgoingTxRepo,
+			coreApplication.CORE.Router.WithdrawalRepo,
+			coreApplication.CORE.Router.SweepRepo,
+			coreApplication.CORE.Router.Blockchains(),
+			constants.TX_TRACK_INTERVAL,
+		)
+		txTracker.Start(mainCtx)
+		defer txTracker.Stop()
+	}
 
-				err := coreApplication.CORE.Router.TransactionRepo.Create(*transaction)
-				if err != nil {
-					fmt.Println("Error", err)
-				}
+	fiberApp := coreApplication.CORE.Router.GetFiber()
 
-			}
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-c
+		log.Println("Shutting down...")
+		if err := fiberApp.Shutdown(); err != nil {
+			log.Println(err)
 		}
 	}()
 
-	fiberApp := coreApplication.CORE.Router.GetFiber()
 	log.Println("App running on", os.Getenv("PORT"))
-	log.Fatal(fiberApp.Listen(os.Getenv("PORT")))
-
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, syscall.SIGINT, syscall.SIGTERM)
-	<-c
-	log.Println("Shutting down...")
+	if err := fiberApp.Listen(os.Getenv("PORT")); err != nil {
+		log.Println(err)
+	}
 	bus.Shutdown()
 }
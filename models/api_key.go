@@ -0,0 +1,45 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type APIKey struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	Merchant   Merchant  `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+
+	DomainID uuid.UUID `gorm:"type:uuid;not null;index" json:"domain_id"`
+	Domain   Domain    `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+
+	KeyID       string   `gorm:"size:32;uniqueIndex;not null" json:"key_id"`
+	KeyHash     string   `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	SecretEnc   string   `gorm:"size:256;not null" json:"-"` // encrypted with MASTER_KEY, used for request signatures
+	Environment string   `gorm:"size:8;not null;index" json:"environment"`
+	Label       string   `gorm:"size:100" json:"label"`
+	Scopes      []string `gorm:"serializer:json;type:jsonb" json:"scopes"`
+
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt  *time.Time `gorm:"index" json:"expires_at,omitempty"`
+	RevokedAt  *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+
+	// Plaintext credentials, only populated in the response that created the key.
+	PlainKey    string `gorm:"-" json:"api_key,omitempty"`
+	PlainSecret string `gorm:"-" json:"api_secret,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+		return false
+	}
+	return true
+}
@@ -4,26 +4,27 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type Domain struct {
-	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
 
-	MerchantID uuid.UUID `gorm:"type:uuid;not null;index"`
-	Merchant   Merchant  `gorm:"constraint:OnDelete:CASCADE;"`
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	Merchant   Merchant  `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
 
-	DomainURL string `gorm:"size:255;not null"`
+	DomainURL string `gorm:"size:255;not null" json:"domain_url"`
 
-	KeyID     string `gorm:"size:32;index"`
-	APIKey    string `gorm:"size:128;uniqueIndex;not null"`
-	APISecret string `gorm:"size:256;not null" json:"-"`
+	APIKeys []APIKey `gorm:"foreignKey:DomainID" json:"api_keys,omitempty"`
 
-	HDAccountID uint32 `gorm:"not null;uniqueIndex"`
+	// Never reused, soft deleted domains keep their HD account.
+	HDAccountID uint32 `gorm:"not null;uniqueIndex" json:"hd_account_id"`
 
-	WebhookURL    string `gorm:"size:500"`
-	WebhookSecret string `gorm:"size:256" json:"-"`
-	IsEnabled     bool   `json:"is_enabled" gorm:"-"`
+	WebhookURL    string `gorm:"size:500" json:"webhook_url"`
+	WebhookSecret string `gorm:"size:256" json:"-"` // encrypted with MASTER_KEY
+	IsEnabled     bool   `gorm:"not null;default:true" json:"is_enabled"`
 
-	CreatedAt time.Time
-	UpdatedAt time.Time
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
 }
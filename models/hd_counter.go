@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// HDCounter holds the last HD index handed out for a scope (all domains, or
+// the wallets of one domain). Rows are only ever incremented.
+type HDCounter struct {
+	Scope     string `gorm:"size:64;primaryKey"`
+	Value     uint32 `gorm:"not null"`
+	UpdatedAt time.Time
+}
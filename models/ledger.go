@@ -0,0 +1,58 @@
+package models
+
+import (
+	"core/constants"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LedgerAccount holds one asset for one owner. Gateway accounts use
+// uuid.Nil as MerchantID. Balance caches the sum of the account postings.
+type LedgerAccount struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	MerchantID uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_ledger_account" json:"merchant_id"`
+	Type       string            `gorm:"size:20;not null;uniqueIndex:idx_ledger_account" json:"type"`
+	ChainID    constants.ChainID `gorm:"type:bigint;not null;uniqueIndex:idx_ledger_account" json:"chain_id"`
+	Asset      string            `gorm:"size:128;not null;uniqueIndex:idx_ledger_account" json:"asset"` // token contract or native symbol, lower case
+	Symbol     string            `gorm:"size:20;not null" json:"symbol"`
+	Decimals   uint8             `gorm:"not null" json:"decimals"`
+
+	Balance string `gorm:"type:numeric(78,0);not null;default:0" json:"balance"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// LedgerEntry is one journal entry. Its postings always sum to zero.
+type LedgerEntry struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	IdempotencyKey string     `gorm:"size:128;not null;uniqueIndex" json:"idempotency_key"`
+	Type           string     `gorm:"size:20;not null;index" json:"type"`
+	MerchantID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	TransactionID  *uuid.UUID `gorm:"type:uuid;index" json:"transaction_id,omitempty"`
+	ReversalOf     *uuid.UUID `gorm:"type:uuid;uniqueIndex" json:"reversal_of,omitempty"`
+	Description    string     `gorm:"size:255" json:"description,omitempty"`
+
+	Postings []LedgerPosting `gorm:"foreignKey:EntryID" json:"postings,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LedgerPosting moves Amount into an account: credits are positive, debits
+// negative.
+type LedgerPosting struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	EntryID   uuid.UUID     `gorm:"type:uuid;not null;index" json:"entry_id"`
+	Entry     *LedgerEntry  `gorm:"constraint:OnDelete:RESTRICT;" json:"entry,omitempty"`
+	AccountID uuid.UUID     `gorm:"type:uuid;not null;index" json:"account_id"`
+	Account   LedgerAccount `gorm:"constraint:OnDelete:RESTRICT;" json:"-"`
+
+	Amount       string `gorm:"type:numeric(78,0);not null" json:"amount"`
+	BalanceAfter string `gorm:"type:numeric(78,0);not null" json:"balance_after"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -14,6 +14,24 @@ type Merchant struct {
 	Password string    `json:"-"`
 	Domains  []Domain  `gorm:"foreignKey:MerchantID" json:"domains,omitempty"`
 
+	FailedLoginAttempts int        `gorm:"not null;default:0" json:"-"`
+	LockedUntil         *time.Time `json:"-"`
+	PasswordChangedAt   *time.Time `json:"-"`
+	// Passwords set before they were case-sensitive were stored lowercased.
+	// Login accepts those in any case once and rehashes them as typed.
+	PasswordCaseSensitive bool `gorm:"not null;default:false" json:"-"`
+
+	TOTPSecret      string     `gorm:"size:256" json:"-"` // encrypted with MASTER_KEY
+	TOTPEnabled     bool       `gorm:"not null;default:false" json:"totp_enabled"`
+	TOTPConfirmedAt *time.Time `json:"-"`
+	TOTPLastStep    int64      `gorm:"not null;default:0" json:"-"`
+	// Invalid codes outside login, where failed logins lock the account.
+	FailedTOTPAttempts int        `gorm:"not null;default:0" json:"-"`
+	TOTPLockedUntil    *time.Time `json:"-"`
+
+	// Restrict withdrawals to allowlisted destinations.
+	WithdrawalAllowlist bool `gorm:"not null;default:false" json:"withdrawal_allowlist"`
+
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
 	DeletedAt gorm.DeletedAt `gorm:"index"`
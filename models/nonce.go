@@ -0,0 +1,29 @@
+package models
+
+import (
+	"core/constants"
+	"time"
+)
+
+// AddressNonce is the next nonce the gateway hands out for a sender
+// address of an account-based chain. Address is lower case.
+type AddressNonce struct {
+	ChainID   constants.ChainID `gorm:"type:bigint;primaryKey" json:"chain_id"`
+	Address   string            `gorm:"size:128;primaryKey" json:"address"`
+	Chain     string            `gorm:"size:32;not null" json:"chain"`
+	Next      uint64            `gorm:"not null" json:"next"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// NonceReservation is a nonce handed out and not yet known to the node:
+// reserved while its transaction is signed, broadcast once sent, released
+// when sending failed so the next reservation fills it.
+type NonceReservation struct {
+	ChainID   constants.ChainID `gorm:"type:bigint;primaryKey" json:"chain_id"`
+	Address   string            `gorm:"size:128;primaryKey" json:"address"`
+	Nonce     uint64            `gorm:"primaryKey" json:"nonce"`
+	Status    string            `gorm:"size:20;not null" json:"status"`
+	TxHash    *string           `gorm:"size:128" json:"tx_hash,omitempty"`
+	CreatedAt time.Time         `json:"created_at"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
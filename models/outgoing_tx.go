@@ -0,0 +1,62 @@
+package models
+
+import (
+	"core/constants"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// OutgoingTx is one attempt at a transaction the gateway broadcast for a
+// withdrawal, sweep or gas top-up. A stuck attempt is replaced by a new one
+// with the same nonce and higher fees, or by a cancellation; replacements
+// point at the attempt they replaced through ReplacesID and share the
+// RootID of the first attempt, whose ID it is.
+type OutgoingTx struct {
+	ID         uuid.UUID  `gorm:"type:uuid;primaryKey" json:"id"`
+	RootID     uuid.UUID  `gorm:"type:uuid;not null;index" json:"root_id"`
+	ReplacesID *uuid.UUID `gorm:"type:uuid" json:"replaces_id,omitempty"`
+
+	ChainID constants.ChainID `gorm:"type:bigint;not null" json:"chain_id"`
+	Chain   string            `gorm:"size:32;not null" json:"chain"`
+
+	Kind        string    `gorm:"size:20;not null;index:idx_outgoing_reference" json:"kind"`
+	ReferenceID uuid.UUID `gorm:"type:uuid;not null;index:idx_outgoing_reference" json:"reference_id"` // withdrawal or sweep
+
+	// HD key that signed it, and signs its replacements.
+	HDAccountID uint32 `gorm:"not null" json:"-"`
+	HDAddressId uint32 `gorm:"not null" json:"-"`
+
+	FromAddress string  `gorm:"size:128;not null" json:"from_address"`
+	ToAddress   string  `gorm:"size:128;not null" json:"to_address"`
+	Nonce       uint64  `gorm:"not null" json:"nonce"`
+	Value       string  `gorm:"type:text;not null" json:"value"`
+	Data        []byte  `json:"-"`
+	GasLimit    uint64  `gorm:"not null" json:"gas_limit"`
+	GasPrice    string  `gorm:"type:text;not null" json:"gas_price"` // fee cap of EIP-1559 attempts
+	GasTipCap   *string `gorm:"type:text" json:"gas_tip_cap,omitempty"`
+	Cancel      bool    `gorm:"not null;default:false" json:"cancel"`
+
+	TxHash string `gorm:"size:128;not null;index" json:"tx_hash"`
+	Status string `gorm:"size:20;not null;index" json:"status"`
+	// Speed-up or cancellation asked for while pending, sent on the next
+	// check.
+	Action *string `gorm:"size:20" json:"action,omitempty"`
+
+	BroadcastBlock uint64     `gorm:"not null" json:"broadcast_block"`
+	MinedBlock     *uint64    `json:"mined_block,omitempty"`
+	BroadcastAt    time.Time  `json:"broadcast_at"`
+	MinedAt        *time.Time `json:"mined_at,omitempty"`
+	// When the withdrawal or sweep was settled, once the mined attempt was
+	// deep enough.
+	SettledAt *time.Time `gorm:"index" json:"settled_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Replaceable is whether the attempt records what was sent. Chains that
+// cannot replace their transactions are only followed until mined.
+func (t *OutgoingTx) Replaceable() bool {
+	return t.GasLimit > 0
+}
@@ -0,0 +1,66 @@
+package models
+
+import (
+	"core/constants"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// PaymentRequest is an order expecting an amount of one asset on a deposit
+// address before ExpiresAt. Amounts are in base units of the asset.
+type PaymentRequest struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	Merchant   Merchant  `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+
+	DomainID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_payment_request_order" json:"domain_id"`
+	Domain   Domain    `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+
+	WalletID uuid.UUID `gorm:"type:uuid;not null;index" json:"wallet_id"`
+	OrderID  string    `gorm:"size:128;not null;uniqueIndex:idx_payment_request_order" json:"order_id"`
+
+	ChainID  constants.ChainID `gorm:"type:bigint;not null" json:"chain_id"`
+	Address  string            `gorm:"size:128;not null;index" json:"address"`
+	Asset    string            `gorm:"size:20;not null" json:"asset"`
+	Token    *string           `gorm:"size:128" json:"token,omitempty"` // nil for the native asset
+	Decimals uint8             `gorm:"not null" json:"decimals"`
+
+	Amount     string `gorm:"type:text;not null" json:"amount"`
+	AmountPaid string `gorm:"type:text;not null;default:'0'" json:"amount_paid"`
+	Status     string `gorm:"size:20;not null;index" json:"status"`
+
+	// Fiat priced requests keep the rate they were converted at; Amount is
+	// derived from it and settlement is judged against that snapshot.
+	FiatCurrency *string    `gorm:"size:3" json:"fiat_currency,omitempty"`
+	FiatAmount   *string    `gorm:"type:text" json:"fiat_amount,omitempty"`
+	Rate         *string    `gorm:"type:text" json:"rate,omitempty"`
+	RateProvider *string    `gorm:"size:32" json:"rate_provider,omitempty"`
+	RateLockedAt *time.Time `json:"rate_locked_at,omitempty"`
+	ToleranceBps uint16     `gorm:"not null;default:0" json:"tolerance_bps"`
+
+	Metadata map[string]interface{} `gorm:"serializer:json;type:jsonb" json:"metadata,omitempty"`
+
+	ExpiresAt time.Time  `gorm:"not null;index" json:"expires_at"`
+	PaidAt    *time.Time `json:"paid_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// PaymentRequestEvent is an outbox row written on every state change, to be
+// delivered to the domain webhook.
+type PaymentRequestEvent struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	PaymentRequestID uuid.UUID      `gorm:"type:uuid;not null;index" json:"payment_request_id"`
+	PaymentRequest   PaymentRequest `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	DomainID         uuid.UUID      `gorm:"type:uuid;not null;index" json:"domain_id"`
+
+	Status     string `gorm:"size:20;not null" json:"status"`
+	AmountPaid string `gorm:"type:text;not null" json:"amount_paid"`
+
+	DeliveredAt *time.Time `gorm:"index" json:"delivered_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
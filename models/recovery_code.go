@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type RecoveryCode struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Merchant   Merchant  `gorm:"constraint:OnDelete:CASCADE;"`
+
+	CodeHash string     `gorm:"size:64;uniqueIndex;not null"`
+	UsedAt   *time.Time `gorm:"index"`
+
+	CreatedAt time.Time
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Session struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"merchant_id"`
+	Merchant   Merchant  `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+
+	AccessTokenHash  string    `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	RefreshTokenHash string    `gorm:"size:64;uniqueIndex;not null" json:"-"`
+	AccessExpiresAt  time.Time `gorm:"not null" json:"access_expires_at"`
+	RefreshExpiresAt time.Time `gorm:"not null" json:"refresh_expires_at"`
+
+	IP        string     `gorm:"size:64" json:"ip"`
+	UserAgent string     `gorm:"size:255" json:"user_agent"`
+	RevokedAt *time.Time `gorm:"index" json:"revoked_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type PasswordReset struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey"`
+
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index"`
+	Merchant   Merchant  `gorm:"constraint:OnDelete:CASCADE;"`
+
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	UsedAt    *time.Time `gorm:"index"`
+
+	CreatedAt time.Time
+}
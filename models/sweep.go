@@ -0,0 +1,48 @@
+package models
+
+import (
+	"core/constants"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Sweep moves the confirmed deposits of one asset on one deposit address to
+// the treasury. The deposits it covers point back to it through SweepID.
+// At most one sweep per address and asset is in flight at any time.
+type Sweep struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	ChainID constants.ChainID `gorm:"type:bigint;not null;uniqueIndex:idx_sweep_in_flight,where:status = 'pending' OR status = 'funding' OR status = 'broadcast'" json:"chain_id"`
+	Chain   string            `gorm:"size:32;not null" json:"chain"`
+
+	WalletID    uuid.UUID `gorm:"type:uuid;not null;index" json:"wallet_id"`
+	HDAccountID uint32    `gorm:"not null" json:"-"`
+	HDAddressId uint32    `gorm:"not null" json:"-"`
+	Address     string    `gorm:"size:128;not null;uniqueIndex:idx_sweep_in_flight" json:"address"`
+
+	Asset       string  `gorm:"size:20;not null;uniqueIndex:idx_sweep_in_flight" json:"asset"`
+	Token       *string `gorm:"size:128" json:"token,omitempty"` // nil for the native asset
+	Decimals    uint8   `gorm:"not null" json:"decimals"`
+	Amount      string  `gorm:"type:text;not null" json:"amount"` // sum of the covered deposits
+	Destination string  `gorm:"size:128;not null" json:"destination"`
+
+	Status        string     `gorm:"size:20;not null;index" json:"status"`
+	TxHash        *string    `gorm:"size:128;index" json:"tx_hash,omitempty"`
+	Fee           *string    `gorm:"type:text" json:"fee,omitempty"` // native base units, gas top-up included
+	FailureReason *string    `gorm:"size:255" json:"failure_reason,omitempty"`
+	BroadcastAt   *time.Time `json:"broadcast_at,omitempty"`
+	ConfirmedAt   *time.Time `json:"confirmed_at,omitempty"`
+
+	// Gas top-up of token sweeps. GasLimit and GasPrice pin the sweep to
+	// the fee the top-up paid for.
+	GasLimit       uint64     `json:"gas_limit,omitempty"`
+	GasPrice       *string    `gorm:"type:text" json:"gas_price,omitempty"`
+	FundingTxHash  *string    `gorm:"size:128;index" json:"funding_tx_hash,omitempty"`
+	FundedAt       *time.Time `json:"funded_at,omitempty"`
+	RecoveryTxHash *string    `gorm:"size:128" json:"recovery_tx_hash,omitempty"`
+	RecoveredAt    *time.Time `json:"recovered_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -27,6 +27,9 @@ type Transaction struct {
 
 	Status string `gorm:"type:varchar(20);not null;index" json:"status"` // pending, confirmed, failed vs.
 
+	PaymentRequestID *uuid.UUID `gorm:"type:uuid;index" json:"payment_request_id,omitempty"`
+	SweepID          *uuid.UUID `gorm:"type:uuid;index" json:"sweep_id,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
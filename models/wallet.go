@@ -1,6 +1,7 @@
 package models
 
 import (
+	"core/constants"
 	"time"
 
 	"github.com/google/uuid"
@@ -18,12 +19,18 @@ type Wallet struct {
 	DomainID uuid.UUID `gorm:"type:uuid;not null;index" json:"domain_id"`
 	Domain   Domain    `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
 
-	BitcoinAddress   string    `gorm:"size:128;uniqueIndex;not null" json:"bitcoin"`
-	EthereumAddress  string    `gorm:"size:128;uniqueIndex;not null" json:"ethereum"`
-	AvalancheAddress string    `gorm:"size:128;uniqueIndex;not null" json:"avalanche"`
-	TronAddress      string    `gorm:"size:128;uniqueIndex;not null" json:"tron"`
-	SolanaAddress    string    `gorm:"size:128;uniqueIndex;not null" json:"solana"`
-	ChilizAddress    string    `gorm:"size:128;uniqueIndex;not null" json:"chiliz"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	Addresses []WalletAddress `gorm:"foreignKey:WalletID" json:"addresses"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Address returns the wallet address on the chain, if derived.
+func (w *Wallet) Address(chainID constants.ChainID) (string, bool) {
+	for _, a := range w.Addresses {
+		if a.ChainID == chainID {
+			return a.Address, true
+		}
+	}
+	return "", false
 }
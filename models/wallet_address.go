@@ -0,0 +1,28 @@
+package models
+
+import (
+	"core/constants"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WalletAddress is the address of a wallet on one chain. Addresses are
+// derived lazily, a wallet only has rows for the chains requested so far.
+type WalletAddress struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"-"`
+
+	WalletID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_wallet_address_chain" json:"-"`
+	Wallet   Wallet    `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+	DomainID   uuid.UUID `gorm:"type:uuid;not null;index" json:"-"`
+
+	ChainID        constants.ChainID `gorm:"type:bigint;not null;uniqueIndex:idx_wallet_address_chain;uniqueIndex:idx_chain_address" json:"chain_id"`
+	Chain          string            `gorm:"size:32;not null" json:"chain"`
+	Address        string            `gorm:"size:128;not null;uniqueIndex:idx_chain_address" json:"address"`
+	DerivationPath string            `gorm:"size:64;not null" json:"derivation_path"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
@@ -0,0 +1,69 @@
+package models
+
+import (
+	"core/constants"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Withdrawal is a merchant payout. Amount is in base units of the asset and
+// is held on the ledger from the moment the request is accepted.
+type Withdrawal struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	MerchantID uuid.UUID  `gorm:"type:uuid;not null;uniqueIndex:idx_withdrawal_idempotency" json:"merchant_id"`
+	Merchant   Merchant   `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	DomainID   *uuid.UUID `gorm:"type:uuid;index" json:"domain_id,omitempty"`
+
+	IdempotencyKey string `gorm:"size:128;not null;uniqueIndex:idx_withdrawal_idempotency" json:"idempotency_key"`
+
+	ChainID     constants.ChainID `gorm:"type:bigint;not null;index" json:"chain_id"`
+	Chain       string            `gorm:"size:32;not null" json:"chain"`
+	Asset       string            `gorm:"size:20;not null" json:"asset"`
+	Token       *string           `gorm:"size:128" json:"token,omitempty"` // nil for the native asset
+	Decimals    uint8             `gorm:"not null" json:"decimals"`
+	Amount      string            `gorm:"type:text;not null" json:"amount"`
+	Destination string            `gorm:"size:128;not null" json:"destination"`
+
+	Status        string     `gorm:"size:20;not null;index" json:"status"`
+	TxHash        *string    `gorm:"size:128;index" json:"tx_hash,omitempty"`
+	FailureReason *string    `gorm:"size:255" json:"failure_reason,omitempty"`
+	ApprovedAt    *time.Time `json:"approved_at,omitempty"`
+	BroadcastAt   *time.Time `json:"broadcast_at,omitempty"`
+	ConfirmedAt   *time.Time `json:"confirmed_at,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// WithdrawalEvent is the audit log of a withdrawal, one row per transition.
+type WithdrawalEvent struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	WithdrawalID uuid.UUID  `gorm:"type:uuid;not null;index" json:"withdrawal_id"`
+	Withdrawal   Withdrawal `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+
+	FromStatus string     `gorm:"size:20" json:"from_status,omitempty"`
+	ToStatus   string     `gorm:"size:20;not null" json:"to_status"`
+	Actor      string     `gorm:"size:20;not null" json:"actor"`
+	ActorID    *uuid.UUID `gorm:"type:uuid" json:"actor_id,omitempty"`
+	Note       string     `gorm:"size:255" json:"note,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WithdrawalThreshold auto-approves withdrawals of an asset up to MaxAmount
+// (base units). Without a threshold every withdrawal waits for approval.
+type WithdrawalThreshold struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	MerchantID uuid.UUID         `gorm:"type:uuid;not null;uniqueIndex:idx_withdrawal_threshold" json:"merchant_id"`
+	Merchant   Merchant          `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+	ChainID    constants.ChainID `gorm:"type:bigint;not null;uniqueIndex:idx_withdrawal_threshold" json:"chain_id"`
+	Asset      string            `gorm:"size:128;not null;uniqueIndex:idx_withdrawal_threshold" json:"asset"` // token contract or native symbol, lower case
+	MaxAmount  string            `gorm:"type:text;not null" json:"max_amount"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
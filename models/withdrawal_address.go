@@ -0,0 +1,25 @@
+package models
+
+import (
+	"core/constants"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// WithdrawalAddress is an allowlisted payout destination. It can be used
+// from ActiveFrom on.
+type WithdrawalAddress struct {
+	ID uuid.UUID `gorm:"type:uuid;default:uuid_generate_v4();primaryKey" json:"id"`
+
+	MerchantID uuid.UUID `gorm:"type:uuid;not null;uniqueIndex:idx_withdrawal_address" json:"merchant_id"`
+	Merchant   Merchant  `gorm:"constraint:OnDelete:CASCADE;" json:"-"`
+
+	ChainID constants.ChainID `gorm:"type:bigint;not null;uniqueIndex:idx_withdrawal_address" json:"chain_id"`
+	Chain   string            `gorm:"size:32;not null" json:"chain"`
+	Address string            `gorm:"size:128;not null;uniqueIndex:idx_withdrawal_address" json:"address"`
+	Label   string            `gorm:"size:64" json:"label,omitempty"`
+
+	ActiveFrom time.Time `gorm:"not null" json:"active_from"`
+	CreatedAt  time.Time `json:"created_at"`
+}
@@ -0,0 +1,133 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+func getJSON(ctx context.Context, client *http.Client, endpoint string, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	decoder := json.NewDecoder(resp.Body)
+	decoder.UseNumber()
+	return decoder.Decode(out)
+}
+
+// CoinbaseProvider reads the public exchange rates endpoint, which quotes
+// every fiat currency against one asset symbol.
+type CoinbaseProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+func NewCoinbaseProvider() *CoinbaseProvider {
+	return &CoinbaseProvider{
+		baseURL: "https://api.coinbase.com",
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *CoinbaseProvider) Name() string {
+	return "coinbase"
+}
+
+func (p *CoinbaseProvider) Price(ctx context.Context, symbol, fiat string) (string, error) {
+	var body struct {
+		Data struct {
+			Rates map[string]string `json:"rates"`
+		} `json:"data"`
+	}
+
+	endpoint := p.baseURL + "/v2/exchange-rates?currency=" + url.QueryEscape(strings.ToUpper(symbol))
+	if err := getJSON(ctx, p.client, endpoint, &body); err != nil {
+		return "", err
+	}
+
+	rate, ok := body.Data.Rates[strings.ToUpper(fiat)]
+	if !ok {
+		return "", ErrPriceUnavailable
+	}
+	return rate, nil
+}
+
+// coinGeckoIDs maps asset symbols to CoinGecko coin ids.
+var coinGeckoIDs = map[string]string{
+	"BTC":  "bitcoin",
+	"WBTC": "wrapped-bitcoin",
+	"ETH":  "ethereum",
+	"BNB":  "binancecoin",
+	"AVAX": "avalanche-2",
+	"SOL":  "solana",
+	"TRX":  "tron",
+	"CHZ":  "chiliz",
+	"USDT": "tether",
+	"USDC": "usd-coin",
+}
+
+type CoinGeckoProvider struct {
+	baseURL string
+	apiKey  string
+	client  *http.Client
+}
+
+// NewCoinGeckoProvider uses the public API, or the pro API when apiKey is set.
+func NewCoinGeckoProvider(apiKey string) *CoinGeckoProvider {
+	baseURL := "https://api.coingecko.com/api/v3"
+	if apiKey != "" {
+		baseURL = "https://pro-api.coingecko.com/api/v3"
+	}
+	return &CoinGeckoProvider{
+		baseURL: baseURL,
+		apiKey:  apiKey,
+		client:  &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+func (p *CoinGeckoProvider) Name() string {
+	return "coingecko"
+}
+
+func (p *CoinGeckoProvider) Price(ctx context.Context, symbol, fiat string) (string, error) {
+	id, ok := coinGeckoIDs[strings.ToUpper(symbol)]
+	if !ok {
+		return "", ErrPriceUnavailable
+	}
+
+	query := url.Values{}
+	query.Set("ids", id)
+	query.Set("vs_currencies", strings.ToLower(fiat))
+	query.Set("precision", "full")
+	if p.apiKey != "" {
+		query.Set("x_cg_pro_api_key", p.apiKey)
+	}
+
+	var body map[string]map[string]json.Number
+	if err := getJSON(ctx, p.client, p.baseURL+"/simple/price?"+query.Encode(), &body); err != nil {
+		return "", err
+	}
+
+	rate, ok := body[id][strings.ToLower(fiat)]
+	if !ok {
+		return "", ErrPriceUnavailable
+	}
+	return rate.String(), nil
+}
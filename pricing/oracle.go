@@ -0,0 +1,117 @@
+package pricing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	ErrPriceUnavailable    = errors.New("price unavailable")
+	ErrUnsupportedCurrency = errors.New("unsupported fiat currency")
+)
+
+// Provider returns the price of one whole unit of an asset in a fiat
+// currency, as a decimal string.
+type Provider interface {
+	Name() string
+	Price(ctx context.Context, symbol, fiat string) (string, error)
+}
+
+// Quote is a price snapshot. Rate is the fiat price of one whole asset unit.
+type Quote struct {
+	Asset     string
+	Fiat      string
+	Rate      string
+	Provider  string
+	FetchedAt time.Time
+}
+
+func (q Quote) rat() (*big.Rat, bool) {
+	rate, ok := new(big.Rat).SetString(q.Rate)
+	if !ok || rate.Sign() <= 0 {
+		return nil, false
+	}
+	return rate, true
+}
+
+// Oracle asks its providers in order and caches quotes for ttl.
+type Oracle struct {
+	providers []Provider
+	ttl       time.Duration
+
+	mu    sync.Mutex
+	cache map[string]Quote
+}
+
+func NewOracle(ttl time.Duration, providers ...Provider) *Oracle {
+	return &Oracle{
+		providers: providers,
+		ttl:       ttl,
+		cache:     make(map[string]Quote),
+	}
+}
+
+func (o *Oracle) Quote(ctx context.Context, symbol, fiat string) (*Quote, error) {
+	symbol = strings.ToUpper(symbol)
+	fiat = strings.ToUpper(fiat)
+	key := symbol + "/" + fiat
+
+	o.mu.Lock()
+	cached, ok := o.cache[key]
+	o.mu.Unlock()
+	if ok && time.Since(cached.FetchedAt) < o.ttl {
+		return &cached, nil
+	}
+
+	for _, provider := range o.providers {
+		rate, err := provider.Price(ctx, symbol, fiat)
+		if err != nil {
+			log.Printf("[pricing] %s %s: %v\n", provider.Name(), key, err)
+			continue
+		}
+
+		quote := Quote{
+			Asset:     symbol,
+			Fiat:      fiat,
+			Rate:      rate,
+			Provider:  provider.Name(),
+			FetchedAt: time.Now(),
+		}
+		if _, ok := quote.rat(); !ok {
+			log.Printf("[pricing] %s %s: invalid rate %q\n", provider.Name(), key, rate)
+			continue
+		}
+
+		o.mu.Lock()
+		o.cache[key] = quote
+		o.mu.Unlock()
+		return &quote, nil
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrPriceUnavailable, key)
+}
+
+// ToUnits converts a fiat amount to base units of the asset at the quote
+// rate, rounding up so the merchant never receives less than asked.
+func (q Quote) ToUnits(fiatAmount *big.Rat, decimals uint8) (*big.Int, error) {
+	rate, ok := q.rat()
+	if !ok {
+		return nil, ErrPriceUnavailable
+	}
+
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	units := new(big.Rat).Mul(fiatAmount, new(big.Rat).SetInt(scale))
+	units.Quo(units, rate)
+
+	result, rem := new(big.Int).QuoRem(units.Num(), units.Denom(), new(big.Int))
+	if rem.Sign() > 0 {
+		result.Add(result, big.NewInt(1))
+	}
+	return result, nil
+}
@@ -0,0 +1,47 @@
+package pricing
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+)
+
+func Test_OracleFallbackAndConversion(t *testing.T) {
+	empty := NewStaticProvider(nil)
+	static := NewStaticProvider(map[string]map[string]string{
+		"eth":  {"usd": "3000"},
+		"USDT": {"TRY": "32.5"},
+	})
+	oracle := NewOracle(time.Minute, empty, static)
+
+	quote, err := oracle.Quote(context.Background(), "ETH", "usd")
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	if quote.Provider != "static" || quote.Rate != "3000" {
+		t.Fatalf("unexpected quote %+v", quote)
+	}
+
+	// 100 USD at 3000 USD/ETH is 1/30 ETH, rounded up in wei.
+	units, err := quote.ToUnits(big.NewRat(100, 1), 18)
+	if err != nil {
+		t.Fatalf("ToUnits: %v", err)
+	}
+	if units.String() != "33333333333333334" {
+		t.Fatalf("ToUnits = %s", units)
+	}
+
+	quote, err = oracle.Quote(context.Background(), "USDT", "TRY")
+	if err != nil {
+		t.Fatalf("Quote: %v", err)
+	}
+	units, _ = quote.ToUnits(big.NewRat(65, 1), 6)
+	if units.String() != "2000000" {
+		t.Fatalf("ToUnits = %s", units)
+	}
+
+	if _, err := oracle.Quote(context.Background(), "BTC", "USD"); err == nil {
+		t.Fatalf("expected ErrPriceUnavailable")
+	}
+}
@@ -0,0 +1,52 @@
+package pricing
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"strings"
+)
+
+// StaticProvider serves fixed prices, keyed by symbol and then fiat code.
+// Meant for tests and for pinning prices of assets no exchange lists.
+type StaticProvider struct {
+	prices map[string]map[string]string
+}
+
+func NewStaticProvider(prices map[string]map[string]string) *StaticProvider {
+	normalized := make(map[string]map[string]string, len(prices))
+	for symbol, rates := range prices {
+		byFiat := make(map[string]string, len(rates))
+		for fiat, rate := range rates {
+			byFiat[strings.ToUpper(fiat)] = rate
+		}
+		normalized[strings.ToUpper(symbol)] = byFiat
+	}
+	return &StaticProvider{prices: normalized}
+}
+
+// LoadStaticProvider reads a JSON file shaped like {"ETH": {"USD": "3000.5"}}.
+func LoadStaticProvider(path string) (*StaticProvider, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var prices map[string]map[string]string
+	if err := json.Unmarshal(data, &prices); err != nil {
+		return nil, err
+	}
+	return NewStaticProvider(prices), nil
+}
+
+func (p *StaticProvider) Name() string {
+	return "static"
+}
+
+func (p *StaticProvider) Price(_ context.Context, symbol, fiat string) (string, error) {
+	rate, ok := p.prices[strings.ToUpper(symbol)][strings.ToUpper(fiat)]
+	if !ok {
+		return "", ErrPriceUnavailable
+	}
+	return rate, nil
+}
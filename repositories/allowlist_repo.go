@@ -0,0 +1,180 @@
+package repositories
+
+import (
+	"core/constants"
+	"core/models"
+	"core/types"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AllowlistRepo struct {
+	merchantRepo *MerchantRepo
+}
+
+func (r *AllowlistRepo) DB() *gorm.DB {
+	return r.merchantRepo.DB()
+}
+
+func NewAllowlistRepo(merchantRepo *MerchantRepo) *AllowlistRepo {
+	return &AllowlistRepo{merchantRepo: merchantRepo}
+}
+
+// Add allowlists a destination. It becomes usable after the cooling-off
+// period.
+func (r *AllowlistRepo) Add(params types.AllowlistParams) (*models.WithdrawalAddress, error) {
+	if err := params.ValidateAdd(); err != nil {
+		return nil, err
+	}
+
+	chain, err := r.merchantRepo.Blockchains().GetChain(*params.Chain)
+	if err != nil {
+		return nil, types.NewValidationError("chain", "unknown chain")
+	}
+
+	address := strings.TrimSpace(*params.Address)
+	if !chain.ValidateAddress(address) {
+		return nil, types.NewValidationError("address", "invalid "+chain.Name()+" address")
+	}
+	if chain.ChainID().IsEVM() {
+		address = strings.ToLower(address)
+	}
+
+	label := ""
+	if params.Label != nil {
+		label = *params.Label
+	}
+
+	merchantUUID, _ := uuid.Parse(*params.MerchantID)
+
+	var entry *models.WithdrawalAddress
+	err = r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		var count int64
+		if err := tx.Model(&models.WithdrawalAddress{}).
+			Where("merchant_id = ? AND chain_id = ? AND address = ?", merchantUUID, chain.ChainID(), address).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrAllowlistExists
+		}
+
+		now := time.Now()
+		entry = &models.WithdrawalAddress{
+			ID:         uuid.New(),
+			MerchantID: merchantUUID,
+			ChainID:    chain.ChainID(),
+			Chain:      chain.Name(),
+			Address:    address,
+			Label:      label,
+			ActiveFrom: now.Add(constants.WITHDRAWAL_ALLOWLIST_COOLDOWN),
+			CreatedAt:  now,
+		}
+		return tx.Create(entry).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (r *AllowlistRepo) Remove(params types.AllowlistParams) error {
+	if err := params.ValidateRemove(); err != nil {
+		return err
+	}
+
+	result := r.DB().WithContext(params.Context).
+		Where("id = ? AND merchant_id = ?", *params.AddressID, *params.MerchantID).
+		Delete(&models.WithdrawalAddress{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrAllowlistNotFound
+	}
+	return nil
+}
+
+func (r *AllowlistRepo) List(params types.AllowlistParams) ([]models.WithdrawalAddress, *string, error) {
+	if err := params.ValidateList(); err != nil {
+		return nil, nil, err
+	}
+
+	query := r.DB().WithContext(params.Context).
+		Where("withdrawal_addresses.merchant_id = ?", *params.MerchantID)
+
+	query, err := pageQuery(query, "withdrawal_addresses", params.Pagination)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var entries []models.WithdrawalAddress
+	if err := query.Find(&entries).Error; err != nil {
+		return nil, nil, err
+	}
+
+	entries, cursor := trimPage(entries, params.Pagination, func(a models.WithdrawalAddress) (time.Time, uuid.UUID) {
+		return a.CreatedAt, a.ID
+	})
+	return entries, cursor, nil
+}
+
+// SetEnabled turns destination allowlisting on or off for the merchant.
+func (r *AllowlistRepo) SetEnabled(params types.AllowlistParams, enabled bool) (*models.Merchant, error) {
+	if err := params.ValidateMerchant(); err != nil {
+		return nil, err
+	}
+
+	var merchant *models.Merchant
+	err := r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		var err error
+		merchant, err = lockMerchant(tx, uuid.MustParse(*params.MerchantID))
+		if err != nil {
+			return err
+		}
+
+		merchant.WithdrawalAllowlist = enabled
+		return tx.Model(merchant).Update("withdrawal_allowlist", enabled).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return merchant, nil
+}
+
+// checkAllowlist rejects the destination when the merchant restricts
+// withdrawals and the address is not allowlisted or still cooling off.
+func checkAllowlist(tx *gorm.DB, merchantID uuid.UUID, chainID constants.ChainID, address string) error {
+	var merchant models.Merchant
+	if err := tx.Select("id", "withdrawal_allowlist").First(&merchant, "id = ?", merchantID).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMerchantNotFound
+		}
+		return err
+	}
+	if !merchant.WithdrawalAllowlist {
+		return nil
+	}
+
+	if chainID.IsEVM() {
+		address = strings.ToLower(address)
+	}
+
+	var count int64
+	if err := tx.Model(&models.WithdrawalAddress{}).
+		Where("merchant_id = ? AND chain_id = ? AND address = ? AND active_from <= ?",
+			merchantID, chainID, address, time.Now()).
+		Count(&count).Error; err != nil {
+		return err
+	}
+	if count == 0 {
+		return ErrDestinationNotAllowed
+	}
+	return nil
+}
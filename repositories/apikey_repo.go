@@ -0,0 +1,275 @@
+package repositories
+
+import (
+	"context"
+	"core/constants"
+	"core/helpers"
+	"core/models"
+	"core/types"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// lastUsedResolution limits last_used_at writes to one per key per minute.
+const lastUsedResolution = time.Minute
+
+type APIKeyRepo struct {
+	domainRepo *DomainRepo
+}
+
+func (r *APIKeyRepo) DB() *gorm.DB {
+	return r.domainRepo.DB()
+}
+
+func NewAPIKeyRepo(domainRepo *DomainRepo) *APIKeyRepo {
+	return &APIKeyRepo{domainRepo: domainRepo}
+}
+
+func (r *APIKeyRepo) Create(params types.APIKeyParams) (*models.APIKey, error) {
+	if err := params.ValidateCreate(); err != nil {
+		return nil, err
+	}
+
+	var key *models.APIKey
+
+	err := r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		domain, err := findMerchantDomain(tx, *params.MerchantID, *params.DomainID)
+		if err != nil {
+			return err
+		}
+
+		env := constants.API_KEY_ENV_LIVE
+		if params.Environment != nil {
+			env = *params.Environment
+		}
+		label := ""
+		if params.Label != nil {
+			label = *params.Label
+		}
+
+		key, err = createAPIKey(tx, domain, env, label, params.Scopes)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepo) List(params types.APIKeyParams) ([]models.APIKey, error) {
+	if err := params.ValidateList(); err != nil {
+		return nil, err
+	}
+
+	domain, err := findMerchantDomain(r.DB().WithContext(params.Context), *params.MerchantID, *params.DomainID)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []models.APIKey
+	err = r.DB().WithContext(params.Context).
+		Where("domain_id = ?", domain.ID).
+		Order("created_at DESC").
+		Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// Rotate issues a replacement with the same environment, label and scopes.
+// The old key keeps working until the grace period ends so integrations can
+// deploy the new one without downtime.
+func (r *APIKeyRepo) Rotate(params types.APIKeyParams) (*models.APIKey, error) {
+	if err := params.ValidateKey(); err != nil {
+		return nil, err
+	}
+
+	grace := constants.API_KEY_DEFAULT_GRACE
+	if params.GracePeriod != nil {
+		grace = time.Duration(*params.GracePeriod) * time.Second
+	}
+	if grace > constants.API_KEY_MAX_GRACE {
+		grace = constants.API_KEY_MAX_GRACE
+	}
+
+	var key *models.APIKey
+
+	err := r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		old, err := lockMerchantAPIKey(tx, *params.MerchantID, *params.KeyID)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if !old.IsActive(now) {
+			return ErrAPIKeyRevoked
+		}
+
+		var domain models.Domain
+		if err := tx.First(&domain, "id = ?", old.DomainID).Error; err != nil {
+			return err
+		}
+
+		key, err = createAPIKey(tx, &domain, old.Environment, old.Label, old.Scopes)
+		if err != nil {
+			return err
+		}
+
+		expiresAt := now.Add(grace)
+		if old.ExpiresAt != nil && old.ExpiresAt.Before(expiresAt) {
+			expiresAt = *old.ExpiresAt
+		}
+		return tx.Model(old).Update("expires_at", expiresAt).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+func (r *APIKeyRepo) Revoke(params types.APIKeyParams) error {
+	if err := params.ValidateKey(); err != nil {
+		return err
+	}
+
+	return r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		key, err := lockMerchantAPIKey(tx, *params.MerchantID, *params.KeyID)
+		if err != nil {
+			return err
+		}
+		if key.RevokedAt != nil {
+			return nil
+		}
+		return tx.Model(key).Update("revoked_at", time.Now()).Error
+	})
+}
+
+// Authenticate resolves a plaintext API key to its active key record.
+func (r *APIKeyRepo) Authenticate(ctx context.Context, apiKey string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := r.DB().WithContext(ctx).
+		Where("key_hash = ?", helpers.HashSHA256(apiKey)).
+		First(&key).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if !key.IsActive(now) {
+		return nil, ErrAPIKeyRevoked
+	}
+	if key.Environment != APIKeyEnvironment() {
+		return nil, ErrAPIKeyEnvironment
+	}
+
+	var domain models.Domain
+	err = r.DB().WithContext(ctx).
+		Select("id", "is_enabled").
+		First(&domain, "id = ?", key.DomainID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAPIKeyRevoked
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !domain.IsEnabled {
+		return nil, ErrDomainDisabled
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > lastUsedResolution {
+		if err := r.DB().WithContext(ctx).
+			Model(&key).
+			UpdateColumn("last_used_at", now).Error; err != nil {
+			return nil, err
+		}
+		key.LastUsedAt = &now
+	}
+
+	return &key, nil
+}
+
+// APIKeyEnvironment is the environment of the keys this gateway accepts,
+// API_KEY_ENVIRONMENT or live. A test deployment sets it to test so live keys
+// cannot reach it and test keys cannot move live funds.
+func APIKeyEnvironment() string {
+	if env := os.Getenv("API_KEY_ENVIRONMENT"); env == constants.API_KEY_ENV_TEST {
+		return env
+	}
+	return constants.API_KEY_ENV_LIVE
+}
+
+func findMerchantDomain(tx *gorm.DB, merchantID uuid.UUID, domainID string) (*models.Domain, error) {
+	var domain models.Domain
+	err := tx.First(&domain, "id = ? AND merchant_id = ?", domainID, merchantID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDomainNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
+
+func lockMerchantAPIKey(tx *gorm.DB, merchantID uuid.UUID, keyID string) (*models.APIKey, error) {
+	var key models.APIKey
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&key, "key_id = ? AND merchant_id = ?", keyID, merchantID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// createAPIKey stores only the SHA-256 of the key and the encrypted secret;
+// the plaintext pair is returned once on the model.
+func createAPIKey(tx *gorm.DB, domain *models.Domain, env, label string, scopes []string) (*models.APIKey, error) {
+	keyID, apiKey, err := helpers.GenerateAPIKey(env)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := helpers.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedSecret, err := helpers.EncryptSecret(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &models.APIKey{
+		ID:          uuid.New(),
+		MerchantID:  domain.MerchantID,
+		DomainID:    domain.ID,
+		KeyID:       keyID,
+		KeyHash:     helpers.HashSHA256(apiKey),
+		SecretEnc:   encryptedSecret,
+		Environment: env,
+		Label:       label,
+		Scopes:      scopes,
+	}
+
+	if err := tx.Create(key).Error; err != nil {
+		return nil, err
+	}
+
+	key.PlainKey = apiKey
+	key.PlainSecret = secret
+	return key, nil
+}
@@ -2,14 +2,17 @@ package repositories
 
 import (
 	"context"
+	"core/constants"
 	helpers "core/helpers"
 	"core/models"
 	"core/types"
 	"errors"
 	"os"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type DomainRepo struct {
@@ -28,50 +31,34 @@ func NewDomainRepo(merchantRepo *MerchantRepo) *DomainRepo {
 	return &DomainRepo{merchantRepo: merchantRepo}
 }
 
-func (r *DomainRepo) GetNextDomainHDIndex(ctx context.Context, merchantID uuid.UUID) (uint32, error) {
-	var maxIndex uint32
-	err := r.DB().WithContext(ctx).
-		Model(&models.Domain{}).
-		Where("merchant_id = ?", merchantID).
-		Select("COALESCE(MAX(hd_account_id), 0)").
-		Scan(&maxIndex).Error
-	if err != nil {
-		return 0, err
-	}
-	return maxIndex + 1, nil
-}
-
 func (r *DomainRepo) FindByID(params types.DomainParams) (*models.Domain, error) {
 	var domain models.Domain
 	err := r.merchantRepo.DB().WithContext(params.Context).
 		First(&domain, "id = ?", params.DomainID).Error
-	if err != nil {
-		return nil, err
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDomainNotFound
 	}
-	return &domain, nil
-}
-
-func (r *DomainRepo) FindByAPIKey(params types.DomainParams) (*models.Domain, error) {
-	var domain models.Domain
-	err := r.merchantRepo.DB().WithContext(params.Context).
-		Where("api_key = ?", params.APIKey).
-		First(&domain).Error
 	if err != nil {
 		return nil, err
 	}
 	return &domain, nil
 }
 
-func (r *DomainRepo) FindByAPISecret(params types.DomainParams) (*models.Domain, error) {
-	encryptedSecret, err := helpers.EncryptSecret(*params.APISecret)
-	if err != nil {
-		return nil, err
+func (r *DomainRepo) FindByAPIKey(params types.DomainParams) (*models.Domain, error) {
+	if params.APIKey == nil || *params.APIKey == "" {
+		return nil, types.NewValidationError("api_key", "api_key is required")
 	}
 
 	var domain models.Domain
-	err = r.merchantRepo.DB().WithContext(params.Context).
-		Where("api_secret = ?", encryptedSecret).
+	err := r.merchantRepo.DB().WithContext(params.Context).
+		Joins("JOIN api_keys ON api_keys.domain_id = domains.id").
+		Where("api_keys.key_hash = ?", helpers.HashSHA256(*params.APIKey)).
+		Where("api_keys.revoked_at IS NULL AND (api_keys.expires_at IS NULL OR api_keys.expires_at > ?)", time.Now()).
+		Where("api_keys.environment = ?", APIKeyEnvironment()).
 		First(&domain).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDomainNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -82,6 +69,9 @@ func (r *DomainRepo) FindByURL(params types.DomainParams) (*models.Domain, error
 	var domain models.Domain
 	err := r.merchantRepo.DB().WithContext(params.Context).
 		First(&domain, "domain_url = ?", params.DomainURL).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDomainNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -116,7 +106,7 @@ func (r *DomainRepo) Create(params types.DomainParams) (*models.Domain, error) {
 	merchantUUID, err := uuid.Parse(*params.MerchantID)
 	if err != nil {
 		tx.Rollback()
-		return nil, errors.New("invalid merchant id")
+		return nil, types.NewValidationError("merchant_id", "invalid merchant id")
 	}
 
 	exists, err := r.IsDomainExists(params.Context, merchantUUID, *params.DomainURL, *params.WebhookURL)
@@ -126,17 +116,17 @@ func (r *DomainRepo) Create(params types.DomainParams) (*models.Domain, error) {
 	}
 	if exists {
 		tx.Rollback()
-		return nil, errors.New("domain with this webhook already exists for the merchant")
+		return nil, ErrDomainExists
 	}
 
-	keyID, apiKey, err := helpers.GenerateAPIKey("live")
-	if err != nil {
-		tx.Rollback()
-		return nil, err
+	env := constants.API_KEY_ENV_LIVE
+	if params.Environment != nil {
+		env = *params.Environment
 	}
 
 	masterKey := os.Getenv("MASTER_KEY")
 	if masterKey == "" {
+		tx.Rollback()
 		return nil, errors.New("MASTER_KEY not set")
 	}
 
@@ -146,7 +136,8 @@ func (r *DomainRepo) Create(params types.DomainParams) (*models.Domain, error) {
 		return nil, err
 	}
 
-	hdIndex, err := r.GetNextDomainHDIndex(params.Context, merchantUUID)
+	// Soft deleted domains are counted so a retired HD account is never reused.
+	hdIndex, err := allocateHDIndex(tx, hdScopeDomain, "SELECT COALESCE(MAX(hd_account_id), 0) FROM domains")
 	if err != nil {
 		tx.Rollback()
 		return nil, err
@@ -155,12 +146,10 @@ func (r *DomainRepo) Create(params types.DomainParams) (*models.Domain, error) {
 	domain := &models.Domain{
 		MerchantID:    merchantUUID,
 		DomainURL:     *params.DomainURL,
-		KeyID:         keyID,
-		APIKey:        apiKey,
-		APISecret:     encryptedSecret,
 		WebhookURL:    *params.WebhookURL,
-		WebhookSecret: *params.WebhookSecret,
+		WebhookSecret: encryptedSecret,
 		HDAccountID:   hdIndex,
+		IsEnabled:     true,
 	}
 
 	if err := tx.Create(domain).Error; err != nil {
@@ -168,9 +157,174 @@ func (r *DomainRepo) Create(params types.DomainParams) (*models.Domain, error) {
 		return nil, err
 	}
 
+	key, err := createAPIKey(tx, domain, env, "default", constants.DefaultScopes)
+	if err != nil {
+		tx.Rollback()
+		return nil, err
+	}
+	domain.APIKeys = []models.APIKey{*key}
+
 	if err := tx.Commit().Error; err != nil {
 		return nil, err
 	}
 
 	return domain, nil
 }
+
+// Fetch returns a domain of the merchant, disabled ones included.
+func (r *DomainRepo) Fetch(params types.DomainParams) (*models.Domain, error) {
+	if err := params.ValidateLookup(); err != nil {
+		return nil, err
+	}
+
+	merchantUUID, _ := uuid.Parse(*params.MerchantID)
+	return findMerchantDomain(r.DB().WithContext(params.Context), merchantUUID, *params.DomainID)
+}
+
+func (r *DomainRepo) List(params types.DomainParams) ([]models.Domain, *string, error) {
+	if err := params.ValidateList(); err != nil {
+		return nil, nil, err
+	}
+
+	query, err := pageQuery(
+		r.DB().WithContext(params.Context).Where("domains.merchant_id = ?", *params.MerchantID),
+		"domains",
+		params.Pagination,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var domains []models.Domain
+	if err := query.Find(&domains).Error; err != nil {
+		return nil, nil, err
+	}
+
+	domains, cursor := trimPage(domains, params.Pagination, func(d models.Domain) (time.Time, uuid.UUID) {
+		return d.CreatedAt, d.ID
+	})
+	return domains, cursor, nil
+}
+
+// Update changes the webhook URL and/or secret of a domain.
+func (r *DomainRepo) Update(params types.DomainParams) (*models.Domain, error) {
+	if err := params.ValidateUpdate(); err != nil {
+		return nil, err
+	}
+
+	merchantUUID, _ := uuid.Parse(*params.MerchantID)
+
+	var domain *models.Domain
+	err := r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		var err error
+		domain, err = lockMerchantDomain(tx, merchantUUID, *params.DomainID)
+		if err != nil {
+			return err
+		}
+
+		updates := map[string]interface{}{}
+
+		if params.WebhookURL != nil && *params.WebhookURL != domain.WebhookURL {
+			var count int64
+			if err := tx.Model(&models.Domain{}).
+				Where("merchant_id = ? AND domain_url = ? AND webhook_url = ? AND id <> ?",
+					merchantUUID, domain.DomainURL, *params.WebhookURL, domain.ID).
+				Count(&count).Error; err != nil {
+				return err
+			}
+			if count > 0 {
+				return ErrDomainExists
+			}
+			updates["webhook_url"] = *params.WebhookURL
+		}
+
+		if params.WebhookSecret != nil {
+			encryptedSecret, err := helpers.EncryptSecret(*params.WebhookSecret)
+			if err != nil {
+				return err
+			}
+			updates["webhook_secret"] = encryptedSecret
+		}
+
+		if len(updates) == 0 {
+			return nil
+		}
+		return tx.Model(domain).Updates(updates).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return domain, nil
+}
+
+// SetEnabled toggles a domain. Disabled domains reject API key
+// authentication and new wallets, deposits to the addresses already handed
+// out are still tracked.
+func (r *DomainRepo) SetEnabled(params types.DomainParams, enabled bool) (*models.Domain, error) {
+	if err := params.ValidateLookup(); err != nil {
+		return nil, err
+	}
+
+	merchantUUID, _ := uuid.Parse(*params.MerchantID)
+
+	var domain *models.Domain
+	err := r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		var err error
+		domain, err = lockMerchantDomain(tx, merchantUUID, *params.DomainID)
+		if err != nil {
+			return err
+		}
+		if domain.IsEnabled == enabled {
+			return nil
+		}
+		return tx.Model(domain).Update("is_enabled", enabled).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return domain, nil
+}
+
+// Delete soft deletes the domain and revokes its API keys. The HD account
+// stays reserved, see allocateHDIndex.
+func (r *DomainRepo) Delete(params types.DomainParams) error {
+	if err := params.ValidateLookup(); err != nil {
+		return err
+	}
+
+	merchantUUID, _ := uuid.Parse(*params.MerchantID)
+
+	return r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		domain, err := lockMerchantDomain(tx, merchantUUID, *params.DomainID)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.APIKey{}).
+			Where("domain_id = ? AND revoked_at IS NULL", domain.ID).
+			Update("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(domain).Update("is_enabled", false).Error; err != nil {
+			return err
+		}
+
+		return tx.Delete(domain).Error
+	})
+}
+
+func lockMerchantDomain(tx *gorm.DB, merchantID uuid.UUID, domainID string) (*models.Domain, error) {
+	var domain models.Domain
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&domain, "id = ? AND merchant_id = ?", domainID, merchantID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrDomainNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &domain, nil
+}
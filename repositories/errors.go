@@ -0,0 +1,44 @@
+package repositories
+
+import "errors"
+
+var (
+	ErrMerchantNotFound = errors.New("merchant not found")
+	ErrMerchantExists   = errors.New("email already exists")
+	ErrDomainNotFound   = errors.New("domain not found")
+	ErrDomainExists     = errors.New("domain with this webhook already exists for the merchant")
+	ErrDomainDisabled   = errors.New("domain disabled")
+	ErrWalletNotFound   = errors.New("wallet not found")
+
+	ErrPaymentRequestNotFound = errors.New("payment request not found")
+	ErrPaymentRequestExists   = errors.New("payment request with this order id already exists")
+	ErrWithdrawalNotFound     = errors.New("withdrawal not found")
+	ErrWithdrawalState        = errors.New("withdrawal cannot move to this state")
+	ErrSweepNotFound          = errors.New("sweep not found")
+	ErrOutgoingTxNotFound     = errors.New("outgoing transaction not found")
+	ErrTxNotReplaceable       = errors.New("no pending transaction that can be replaced")
+	ErrIdempotencyConflict    = errors.New("idempotency key reused with different parameters")
+	ErrDestinationNotAllowed  = errors.New("destination is not allowlisted")
+	ErrAllowlistNotFound      = errors.New("allowlisted address not found")
+	ErrAllowlistExists        = errors.New("address already allowlisted")
+	ErrInsufficientBalance    = errors.New("insufficient balance")
+	ErrLedgerUnbalanced       = errors.New("ledger entry does not balance")
+	ErrAPIKeyNotFound         = errors.New("api key not found")
+	ErrAPIKeyRevoked          = errors.New("api key revoked or expired")
+	ErrInvalidAPIKey          = errors.New("invalid api key")
+	ErrAPIKeyEnvironment      = errors.New("api key environment not accepted by this gateway")
+	ErrForbiddenScope         = errors.New("api key scope does not allow this command")
+	ErrFeesUnsupported        = errors.New("fee estimates not supported on this chain")
+
+	ErrInvalidCredentials = errors.New("invalid email or password")
+	ErrAccountLocked      = errors.New("account temporarily locked")
+	ErrSessionNotFound    = errors.New("session not found")
+	ErrSessionExpired     = errors.New("session expired")
+	ErrInvalidResetToken  = errors.New("invalid or expired reset token")
+
+	ErrTwoFactorRequired   = errors.New("two-factor code required")
+	ErrInvalidTwoFactor    = errors.New("invalid two-factor code")
+	ErrTwoFactorEnabled    = errors.New("two-factor authentication already enabled")
+	ErrTwoFactorNotEnabled = errors.New("two-factor authentication not enabled")
+	ErrTwoFactorLocked     = errors.New("two-factor authentication temporarily locked")
+)
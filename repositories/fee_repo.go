@@ -0,0 +1,57 @@
+package repositories
+
+import (
+	"core/blockchain"
+	"core/constants"
+	"core/types"
+	"errors"
+	"math/big"
+)
+
+type FeeRepo struct {
+	merchantRepo *MerchantRepo
+}
+
+func NewFeeRepo(merchantRepo *MerchantRepo) *FeeRepo {
+	return &FeeRepo{merchantRepo: merchantRepo}
+}
+
+// Estimate quotes the network fee of a chain for a strategy, with what a
+// native and a token transfer are expected to cost at it.
+func (r *FeeRepo) Estimate(params types.FeeParams) (*types.FeeQuote, error) {
+	if err := params.ValidateEstimate(); err != nil {
+		return nil, err
+	}
+
+	chain, err := r.merchantRepo.Blockchains().GetChain(*params.Chain)
+	if err != nil {
+		return nil, types.NewValidationError("chain", "unknown chain")
+	}
+
+	estimate, err := chain.EstimateFees(params.Context, *params.Strategy)
+	if errors.Is(err, blockchain.ErrNotImplemented) {
+		return nil, ErrFeesUnsupported
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	expected := estimate.Expected()
+	quote := &types.FeeQuote{
+		Chain:             chain.Name(),
+		Strategy:          estimate.Strategy,
+		Legacy:            estimate.Legacy,
+		MaxFee:            estimate.MaxFee.String(),
+		NativeTransferFee: new(big.Int).Mul(expected, big.NewInt(constants.EVM_NATIVE_TRANSFER_GAS)).String(),
+		TokenTransferFee:  new(big.Int).Mul(expected, big.NewInt(constants.EVM_TOKEN_TRANSFER_GAS)).String(),
+	}
+	if estimate.BaseFee != nil {
+		baseFee := estimate.BaseFee.String()
+		quote.BaseFee = &baseFee
+	}
+	if estimate.MaxPriorityFee != nil {
+		tip := estimate.MaxPriorityFee.String()
+		quote.MaxPriorityFee = &tip
+	}
+	return quote, nil
+}
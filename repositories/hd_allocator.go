@@ -0,0 +1,32 @@
+package repositories
+
+import (
+	"gorm.io/gorm"
+)
+
+const hdScopeDomain = "domain"
+
+func hdScopeWallet(domainID string) string {
+	return "wallet:" + domainID
+}
+
+// allocateHDIndex hands out the next index of scope inside tx. The upsert
+// takes the counter row lock, so concurrent callers are serialized until
+// the creating transaction ends; an index is only consumed when it commits.
+// floorSQL returns the highest index already used by existing
+// rows so counters created after the data never go backwards.
+func allocateHDIndex(tx *gorm.DB, scope string, floorSQL string, args ...interface{}) (uint32, error) {
+	query := `
+		INSERT INTO hd_counters (scope, value, updated_at)
+		VALUES (?, (` + floorSQL + `) + 1, NOW())
+		ON CONFLICT (scope) DO UPDATE
+		SET value = GREATEST(hd_counters.value + 1, EXCLUDED.value), updated_at = NOW()
+		RETURNING value`
+
+	var index uint32
+	values := append([]interface{}{scope}, args...)
+	if err := tx.Raw(query, values...).Scan(&index).Error; err != nil {
+		return 0, err
+	}
+	return index, nil
+}
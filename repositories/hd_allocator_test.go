@@ -0,0 +1,76 @@
+package repositories
+
+import (
+	"core/models"
+	"os"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Test_AllocateHDIndexConcurrent needs a disposable Postgres database in
+// TEST_DATABASE_URL.
+func Test_AllocateHDIndexConcurrent(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.HDCounter{}); err != nil {
+		t.Fatal(err)
+	}
+
+	const workers = 50
+	scope := hdScopeWallet(uuid.NewString())
+
+	var wg sync.WaitGroup
+	indexes := make(chan uint32, workers)
+	errs := make(chan error, workers)
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(rollback bool) {
+			defer wg.Done()
+			err := db.Transaction(func(tx *gorm.DB) error {
+				index, err := allocateHDIndex(tx, scope, "SELECT 0")
+				if err != nil {
+					return err
+				}
+				if rollback {
+					return gorm.ErrInvalidTransaction
+				}
+				indexes <- index
+				return nil
+			})
+			if err != nil && err != gorm.ErrInvalidTransaction {
+				errs <- err
+			}
+		}(i%10 == 0)
+	}
+
+	wg.Wait()
+	close(indexes)
+	close(errs)
+
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	seen := make(map[uint32]bool)
+	for index := range indexes {
+		if seen[index] {
+			t.Fatalf("index %d allocated twice", index)
+		}
+		seen[index] = true
+	}
+	if len(seen) != workers-workers/10 {
+		t.Fatalf("expected %d committed indexes, got %d", workers-workers/10, len(seen))
+	}
+}
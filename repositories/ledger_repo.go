@@ -0,0 +1,391 @@
+package repositories
+
+import (
+	"context"
+	"core/constants"
+	"core/models"
+	"core/types"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LedgerRepo keeps merchant balances as a double-entry journal. Posting
+// methods take the caller's transaction so that ledger entries commit
+// together with the state change that caused them.
+type LedgerRepo struct {
+	db *gorm.DB
+}
+
+func (r *LedgerRepo) DB() *gorm.DB {
+	return r.db
+}
+
+func NewLedgerRepo(db *gorm.DB) *LedgerRepo {
+	return &LedgerRepo{db: db}
+}
+
+// merchantAccountTypes are the accounts shown to a merchant.
+var merchantAccountTypes = []string{constants.LEDGER_ACCOUNT_MERCHANT, constants.LEDGER_ACCOUNT_RESERVED}
+
+type ledgerLine struct {
+	owner       uuid.UUID
+	accountType string
+	amount      *big.Int
+}
+
+// CreditDeposit credits the merchant owning the destination address. It is
+// a no-op for transfers to addresses the gateway does not know.
+func (r *LedgerRepo) CreditDeposit(tx *gorm.DB, transaction *models.Transaction) (*models.LedgerEntry, error) {
+	query := tx.Model(&models.WalletAddress{}).Where("chain_id = ?", transaction.ChainID)
+	if transaction.ChainID.IsEVM() {
+		query = query.Where("LOWER(address) = LOWER(?)", transaction.ToAddress)
+	} else {
+		query = query.Where("address = ?", transaction.ToAddress)
+	}
+
+	var owner models.WalletAddress
+	err := query.First(&owner).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	amount, ok := new(big.Int).SetString(transaction.Amount, 10)
+	if !ok || amount.Sign() <= 0 {
+		return nil, nil
+	}
+
+	// Gas top-ups from the gas station are gateway funds, not deposits.
+	var funding int64
+	if err := tx.Model(&models.Sweep{}).
+		Where("chain_id = ? AND LOWER(funding_tx_hash) = LOWER(?)", transaction.ChainID, transaction.Hash).
+		Count(&funding).Error; err != nil {
+		return nil, err
+	}
+	if funding > 0 {
+		return nil, nil
+	}
+
+	asset := transaction.Symbol
+	if transaction.Token != nil {
+		asset = *transaction.Token
+	}
+
+	transfer := types.LedgerTransfer{
+		Key:           "deposit:" + transaction.ID.String(),
+		MerchantID:    owner.MerchantID,
+		TransactionID: &transaction.ID,
+		ChainID:       transaction.ChainID,
+		Asset:         asset,
+		Symbol:        transaction.Symbol,
+		Decimals:      transaction.Decimals,
+		Amount:        amount,
+		Description:   "deposit " + transaction.Hash,
+	}
+
+	return r.post(tx, constants.LEDGER_ENTRY_DEPOSIT, transfer, nil, []ledgerLine{
+		{uuid.Nil, constants.LEDGER_ACCOUNT_CUSTODY, new(big.Int).Neg(amount)},
+		{owner.MerchantID, constants.LEDGER_ACCOUNT_MERCHANT, amount},
+	}, false)
+}
+
+// Withdraw debits the merchant for funds leaving custody.
+func (r *LedgerRepo) Withdraw(tx *gorm.DB, transfer types.LedgerTransfer) (*models.LedgerEntry, error) {
+	return r.post(tx, constants.LEDGER_ENTRY_WITHDRAWAL, transfer, nil, []ledgerLine{
+		{transfer.MerchantID, constants.LEDGER_ACCOUNT_MERCHANT, new(big.Int).Neg(transfer.Amount)},
+		{uuid.Nil, constants.LEDGER_ACCOUNT_CUSTODY, transfer.Amount},
+	}, false)
+}
+
+// Hold moves available merchant funds to the merchant reserved account.
+func (r *LedgerRepo) Hold(tx *gorm.DB, transfer types.LedgerTransfer) (*models.LedgerEntry, error) {
+	return r.post(tx, constants.LEDGER_ENTRY_HOLD, transfer, nil, []ledgerLine{
+		{transfer.MerchantID, constants.LEDGER_ACCOUNT_MERCHANT, new(big.Int).Neg(transfer.Amount)},
+		{transfer.MerchantID, constants.LEDGER_ACCOUNT_RESERVED, transfer.Amount},
+	}, false)
+}
+
+// SettleHold pays held funds out of custody. A hold that is not settled is
+// released with Reverse.
+func (r *LedgerRepo) SettleHold(tx *gorm.DB, transfer types.LedgerTransfer) (*models.LedgerEntry, error) {
+	return r.post(tx, constants.LEDGER_ENTRY_WITHDRAWAL, transfer, nil, []ledgerLine{
+		{transfer.MerchantID, constants.LEDGER_ACCOUNT_RESERVED, new(big.Int).Neg(transfer.Amount)},
+		{uuid.Nil, constants.LEDGER_ACCOUNT_CUSTODY, transfer.Amount},
+	}, false)
+}
+
+// ChargeFee debits the merchant for a service fee.
+func (r *LedgerRepo) ChargeFee(tx *gorm.DB, transfer types.LedgerTransfer) (*models.LedgerEntry, error) {
+	return r.post(tx, constants.LEDGER_ENTRY_FEE, transfer, nil, []ledgerLine{
+		{transfer.MerchantID, constants.LEDGER_ACCOUNT_MERCHANT, new(big.Int).Neg(transfer.Amount)},
+		{uuid.Nil, constants.LEDGER_ACCOUNT_FEE_INCOME, transfer.Amount},
+	}, false)
+}
+
+// RecordNetworkFee books gas paid from custody as a gateway expense.
+func (r *LedgerRepo) RecordNetworkFee(tx *gorm.DB, transfer types.LedgerTransfer) (*models.LedgerEntry, error) {
+	return r.post(tx, constants.LEDGER_ENTRY_NETWORK_FEE, transfer, nil, []ledgerLine{
+		{uuid.Nil, constants.LEDGER_ACCOUNT_NETWORK_FEES, new(big.Int).Neg(transfer.Amount)},
+		{uuid.Nil, constants.LEDGER_ACCOUNT_CUSTODY, transfer.Amount},
+	}, false)
+}
+
+// Reverse posts the mirror image of the entry with the given key. Reversals
+// may overdraw a merchant, a reorged deposit could already have been spent.
+func (r *LedgerRepo) Reverse(tx *gorm.DB, key, description string) (*models.LedgerEntry, error) {
+	var original models.LedgerEntry
+	err := tx.Preload("Postings.Account").Where("idempotency_key = ?", key).First(&original).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(original.Postings) == 0 {
+		return nil, nil
+	}
+
+	account := original.Postings[0].Account
+	transfer := types.LedgerTransfer{
+		Key:           "reversal:" + key,
+		MerchantID:    original.MerchantID,
+		TransactionID: original.TransactionID,
+		ChainID:       account.ChainID,
+		Asset:         account.Asset,
+		Symbol:        account.Symbol,
+		Decimals:      account.Decimals,
+		Description:   description,
+	}
+
+	lines := make([]ledgerLine, 0, len(original.Postings))
+	for _, posting := range original.Postings {
+		amount, ok := new(big.Int).SetString(posting.Amount, 10)
+		if !ok {
+			return nil, ErrLedgerUnbalanced
+		}
+		lines = append(lines, ledgerLine{posting.Account.MerchantID, posting.Account.Type, amount.Neg(amount)})
+	}
+
+	return r.post(tx, constants.LEDGER_ENTRY_REVERSAL, transfer, &original.ID, lines, true)
+}
+
+// post writes a balanced entry. Posting a key twice returns the entry
+// stored the first time.
+func (r *LedgerRepo) post(tx *gorm.DB, entryType string, transfer types.LedgerTransfer, reversalOf *uuid.UUID, lines []ledgerLine, overdraft bool) (*models.LedgerEntry, error) {
+	sum := new(big.Int)
+	for _, line := range lines {
+		sum.Add(sum, line.amount)
+	}
+	if transfer.Key == "" || sum.Sign() != 0 {
+		return nil, ErrLedgerUnbalanced
+	}
+
+	now := time.Now()
+	entry := &models.LedgerEntry{
+		ID:             uuid.New(),
+		IdempotencyKey: transfer.Key,
+		Type:           entryType,
+		MerchantID:     transfer.MerchantID,
+		TransactionID:  transfer.TransactionID,
+		ReversalOf:     reversalOf,
+		Description:    transfer.Description,
+		CreatedAt:      now,
+	}
+
+	result := tx.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "idempotency_key"}},
+		DoNothing: true,
+	}).Create(entry)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		var existing models.LedgerEntry
+		if err := tx.Preload("Postings").Where("idempotency_key = ?", transfer.Key).First(&existing).Error; err != nil {
+			return nil, err
+		}
+		return &existing, nil
+	}
+
+	// Lock accounts in a fixed order so concurrent entries cannot deadlock.
+	sort.SliceStable(lines, func(i, j int) bool {
+		if lines[i].owner != lines[j].owner {
+			return lines[i].owner.String() < lines[j].owner.String()
+		}
+		return lines[i].accountType < lines[j].accountType
+	})
+
+	accounts := make(map[string]*models.LedgerAccount)
+	for _, line := range lines {
+		key := line.owner.String() + "/" + line.accountType
+		account, ok := accounts[key]
+		if !ok {
+			var err error
+			account, err = lockLedgerAccount(tx, line.owner, line.accountType, transfer)
+			if err != nil {
+				return nil, err
+			}
+			accounts[key] = account
+		}
+
+		balance, _ := new(big.Int).SetString(account.Balance, 10)
+		balance.Add(balance, line.amount)
+		if !overdraft && account.Type == constants.LEDGER_ACCOUNT_MERCHANT && line.amount.Sign() < 0 && balance.Sign() < 0 {
+			return nil, ErrInsufficientBalance
+		}
+		account.Balance = balance.String()
+
+		posting := models.LedgerPosting{
+			ID:           uuid.New(),
+			EntryID:      entry.ID,
+			AccountID:    account.ID,
+			Amount:       line.amount.String(),
+			BalanceAfter: account.Balance,
+			CreatedAt:    now,
+		}
+		if err := tx.Create(&posting).Error; err != nil {
+			return nil, err
+		}
+		entry.Postings = append(entry.Postings, posting)
+	}
+
+	for _, account := range accounts {
+		if err := tx.Model(account).Updates(map[string]interface{}{
+			"balance":    account.Balance,
+			"updated_at": now,
+		}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return entry, nil
+}
+
+func lockLedgerAccount(tx *gorm.DB, owner uuid.UUID, accountType string, transfer types.LedgerTransfer) (*models.LedgerAccount, error) {
+	account := models.LedgerAccount{
+		ID:         uuid.New(),
+		MerchantID: owner,
+		Type:       accountType,
+		ChainID:    transfer.ChainID,
+		Asset:      strings.ToLower(transfer.Asset),
+		Symbol:     transfer.Symbol,
+		Decimals:   transfer.Decimals,
+		Balance:    "0",
+	}
+
+	if err := tx.Clauses(clause.OnConflict{
+		Columns: []clause.Column{
+			{Name: "merchant_id"}, {Name: "type"}, {Name: "chain_id"}, {Name: "asset"},
+		},
+		DoNothing: true,
+	}).Create(&account).Error; err != nil {
+		return nil, err
+	}
+
+	var locked models.LedgerAccount
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("merchant_id = ? AND type = ? AND chain_id = ? AND asset = ?",
+			owner, accountType, transfer.ChainID, account.Asset).
+		First(&locked).Error; err != nil {
+		return nil, err
+	}
+	return &locked, nil
+}
+
+func (r *LedgerRepo) Balances(params types.LedgerParams) ([]models.LedgerAccount, error) {
+	if err := params.ValidateBalances(); err != nil {
+		return nil, err
+	}
+
+	query := r.DB().WithContext(params.Context).
+		Where("merchant_id = ? AND type IN ?", *params.MerchantID, merchantAccountTypes)
+	if params.ChainID != nil {
+		query = query.Where("chain_id = ?", *params.ChainID)
+	}
+	if params.Asset != nil && *params.Asset != "" {
+		query = query.Where("(UPPER(symbol) = UPPER(?) OR asset = LOWER(?))", *params.Asset, *params.Asset)
+	}
+
+	var accounts []models.LedgerAccount
+	if err := query.Order("chain_id, symbol, type").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+// Statement returns the postings on the merchant accounts (available and
+// reserved), newest first.
+func (r *LedgerRepo) Statement(params types.LedgerParams) ([]models.LedgerPosting, *string, error) {
+	if err := params.ValidateStatement(); err != nil {
+		return nil, nil, err
+	}
+
+	query := r.DB().WithContext(params.Context).
+		Model(&models.LedgerPosting{}).
+		Joins("JOIN ledger_accounts ON ledger_accounts.id = ledger_postings.account_id").
+		Where("ledger_accounts.merchant_id = ? AND ledger_accounts.type IN ?", *params.MerchantID, merchantAccountTypes)
+	if params.ChainID != nil {
+		query = query.Where("ledger_accounts.chain_id = ?", *params.ChainID)
+	}
+	if params.Asset != nil && *params.Asset != "" {
+		query = query.Where("(UPPER(ledger_accounts.symbol) = UPPER(?) OR ledger_accounts.asset = LOWER(?))", *params.Asset, *params.Asset)
+	}
+	if params.From != nil {
+		query = query.Where("ledger_postings.created_at >= ?", *params.From)
+	}
+	if params.To != nil {
+		query = query.Where("ledger_postings.created_at < ?", *params.To)
+	}
+
+	query, err := pageQuery(query, "ledger_postings", params.Pagination)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var postings []models.LedgerPosting
+	if err := query.Preload("Entry").Find(&postings).Error; err != nil {
+		return nil, nil, err
+	}
+
+	postings, cursor := trimPage(postings, params.Pagination, func(p models.LedgerPosting) (time.Time, uuid.UUID) {
+		return p.CreatedAt, p.ID
+	})
+	return postings, cursor, nil
+}
+
+// Audit recomputes every balance from the journal and reports accounts whose
+// cached balance disagrees and entries whose postings do not sum to zero.
+func (r *LedgerRepo) Audit(ctx context.Context) (*types.LedgerAudit, error) {
+	audit := &types.LedgerAudit{
+		MismatchedAccounts: []uuid.UUID{},
+		UnbalancedEntries:  []uuid.UUID{},
+	}
+
+	if err := r.DB().WithContext(ctx).Raw(`
+		SELECT a.id FROM ledger_accounts a
+		LEFT JOIN ledger_postings p ON p.account_id = a.id
+		GROUP BY a.id, a.balance
+		HAVING a.balance <> COALESCE(SUM(p.amount), 0)`).
+		Scan(&audit.MismatchedAccounts).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.DB().WithContext(ctx).Raw(`
+		SELECT entry_id FROM ledger_postings
+		GROUP BY entry_id
+		HAVING SUM(amount) <> 0`).
+		Scan(&audit.UnbalancedEntries).Error; err != nil {
+		return nil, err
+	}
+
+	return audit, nil
+}
@@ -0,0 +1,108 @@
+package repositories
+
+import (
+	"context"
+	"core/constants"
+	"core/models"
+	"core/types"
+	"errors"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+// Test_LedgerPosting needs a disposable Postgres database in
+// TEST_DATABASE_URL.
+func Test_LedgerPosting(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.LedgerAccount{}, &models.LedgerEntry{}, &models.LedgerPosting{}); err != nil {
+		t.Fatal(err)
+	}
+
+	ledger := NewLedgerRepo(db)
+	merchantID := uuid.New()
+	transfer := func(key string, amount int64) types.LedgerTransfer {
+		return types.LedgerTransfer{
+			Key:        key + ":" + merchantID.String(),
+			MerchantID: merchantID,
+			ChainID:    constants.TRON,
+			Asset:      "TRX",
+			Symbol:     "TRX",
+			Decimals:   6,
+			Amount:     big.NewInt(amount),
+		}
+	}
+	post := func(fn func(tx *gorm.DB) (*models.LedgerEntry, error)) (*models.LedgerEntry, error) {
+		var entry *models.LedgerEntry
+		err := db.Transaction(func(tx *gorm.DB) error {
+			var err error
+			entry, err = fn(tx)
+			return err
+		})
+		return entry, err
+	}
+	balance := func() string {
+		var account models.LedgerAccount
+		db.Where("merchant_id = ? AND type = ?", merchantID, constants.LEDGER_ACCOUNT_MERCHANT).First(&account)
+		return account.Balance
+	}
+
+	deposit := transfer("deposit", 100)
+	if _, err := post(func(tx *gorm.DB) (*models.LedgerEntry, error) {
+		return ledger.post(tx, constants.LEDGER_ENTRY_DEPOSIT, deposit, nil, []ledgerLine{
+			{uuid.Nil, constants.LEDGER_ACCOUNT_CUSTODY, big.NewInt(-100)},
+			{merchantID, constants.LEDGER_ACCOUNT_MERCHANT, big.NewInt(100)},
+		}, false)
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = post(func(tx *gorm.DB) (*models.LedgerEntry, error) {
+		return ledger.Withdraw(tx, transfer("too-much", 150))
+	})
+	if !errors.Is(err, ErrInsufficientBalance) {
+		t.Fatalf("expected ErrInsufficientBalance, got %v", err)
+	}
+
+	withdrawal := transfer("withdrawal", 40)
+	first, err := post(func(tx *gorm.DB) (*models.LedgerEntry, error) { return ledger.Withdraw(tx, withdrawal) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, err := post(func(tx *gorm.DB) (*models.LedgerEntry, error) { return ledger.Withdraw(tx, withdrawal) })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.ID != again.ID || balance() != "60" {
+		t.Fatalf("withdrawal not idempotent: %s vs %s, balance %s", first.ID, again.ID, balance())
+	}
+
+	if _, err := post(func(tx *gorm.DB) (*models.LedgerEntry, error) {
+		return ledger.Reverse(tx, deposit.Key, "reorg")
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if balance() != "-40" {
+		t.Fatalf("balance after reversal = %s, want -40", balance())
+	}
+
+	audit, err := ledger.Audit(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(audit.MismatchedAccounts) > 0 || len(audit.UnbalancedEntries) > 0 {
+		t.Fatalf("audit failed: %+v", audit)
+	}
+}
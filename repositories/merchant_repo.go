@@ -6,10 +6,12 @@ import (
 	"core/models"
 	"core/types"
 	"errors"
+	"time"
 
 	"github.com/google/uuid"
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type MerchantRepo struct {
@@ -53,7 +55,7 @@ func (r *MerchantRepo) Create(params types.MerchantParams) (*models.Merchant, er
 
 	if count > 0 {
 		tx.Rollback()
-		return nil, errors.New("email already exists")
+		return nil, ErrMerchantExists
 	}
 
 	// UUID v7
@@ -76,6 +78,8 @@ func (r *MerchantRepo) Create(params types.MerchantParams) (*models.Merchant, er
 		Name:     *params.Name,
 		Email:    *params.Email,
 		Password: string(hashedPassword),
+
+		PasswordCaseSensitive: true,
 	}
 
 	if err := tx.Create(merchant).Error; err != nil {
@@ -101,6 +105,9 @@ func (r *MerchantRepo) FindByEmail(params types.MerchantParams) (*models.Merchan
 		Where("LOWER(email) = LOWER(?)", *params.Email).
 		First(&merchant).Error
 
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMerchantNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -119,6 +126,9 @@ func (r *MerchantRepo) FindByID(params types.MerchantParams) (*models.Merchant,
 	err := r.db.WithContext(params.Context).
 		First(&merchant, "id = ?", *params.ID).Error
 
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMerchantNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -130,6 +140,9 @@ func (r *MerchantRepo) DeleteByEmail(params types.MerchantParams) error {
 	return r.db.WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
 		var merchant models.Merchant
 		err := tx.Where("LOWER(email) = LOWER(?)", params.Email).First(&merchant).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMerchantNotFound
+		}
 		if err != nil {
 			return err
 		}
@@ -146,9 +159,11 @@ func (r *MerchantRepo) DeleteByID(params types.MerchantParams) error {
 	}
 	return r.db.WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
 		var merchant models.Merchant
-		if err := tx.
-			Where("id = ?", *params.ID).
-			First(&merchant).Error; err != nil {
+		err := tx.Where("id = ?", *params.ID).First(&merchant).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMerchantNotFound
+		}
+		if err != nil {
 			return err
 		}
 		if err := tx.Delete(&merchant).Error; err != nil {
@@ -161,7 +176,7 @@ func (r *MerchantRepo) DeleteByID(params types.MerchantParams) error {
 func (r *MerchantRepo) Fetch(params types.MerchantParams) ([]models.Merchant, *uuid.UUID, error) {
 
 	if params.Context == nil {
-		return nil, nil, errors.New("context is required")
+		return nil, nil, types.NewValidationError("context", "context is required")
 	}
 
 	limit := params.Limit
@@ -197,48 +212,134 @@ func (r *MerchantRepo) Fetch(params types.MerchantParams) ([]models.Merchant, *u
 	return merchants, nextCursor, nil
 }
 
-func (r *MerchantRepo) CreateDomain() (*models.Domain, error) {
+func (r *MerchantRepo) ChangePassword(params types.MerchantParams) error {
+	if err := params.ValidatePasswordChange(); err != nil {
+		return err
+	}
 
-	env := "live" //test
-	domainURL := "coolvibes.io"
-	webhookURL := "https://coolvibes.io/api/webhook"
+	return r.db.WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		var merchant models.Merchant
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&merchant, "id = ?", *params.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMerchantNotFound
+		}
+		if err != nil {
+			return err
+		}
 
-	merchantID, _ := uuid.NewV7()
+		if err := checkPassword(tx, &merchant, *params.CurrentPassword); err != nil {
+			return err
+		}
 
-	keyID, apiKey, err := helpers.GenerateAPIKey(env)
-	if err != nil {
-		return nil, err
+		if err := setPassword(tx, &merchant, *params.Password); err != nil {
+			return err
+		}
+
+		return revokeSessions(tx, merchant.ID, params.SessionID)
+	})
+}
+
+// RequestPasswordReset issues a single-use reset token. Earlier unused tokens
+// of the merchant are invalidated. The plaintext token is returned once and
+// must be delivered out of band.
+func (r *MerchantRepo) RequestPasswordReset(params types.MerchantParams) (*models.Merchant, string, error) {
+	if err := params.ValidateEmail(); err != nil {
+		return nil, "", err
 	}
 
-	secret, err := helpers.GenerateSecret()
+	var merchant models.Merchant
+	var token string
+
+	err := r.db.WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("LOWER(email) = LOWER(?)", *params.Email).First(&merchant).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrMerchantNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if err := tx.Model(&models.PasswordReset{}).
+			Where("merchant_id = ? AND used_at IS NULL", merchant.ID).
+			Update("used_at", now).Error; err != nil {
+			return err
+		}
+
+		token, err = helpers.GenerateToken(helpers.ResetPref)
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(&models.PasswordReset{
+			ID:         uuid.New(),
+			MerchantID: merchant.ID,
+			TokenHash:  helpers.HashSHA256(token),
+			ExpiresAt:  now.Add(PasswordResetTTL),
+		}).Error
+	})
 	if err != nil {
-		return nil, err
+		return nil, "", err
 	}
 
-	apiKeyHash := helpers.HashSHA256(apiKey)
+	return &merchant, token, nil
+}
 
-	encryptedSecret, err := helpers.EncryptSecret(secret)
-	if err != nil {
-		return nil, err
+func (r *MerchantRepo) ResetPassword(params types.MerchantParams) error {
+	if err := params.ValidatePasswordReset(); err != nil {
+		return err
 	}
 
-	domain := &models.Domain{
-		ID:         uuid.Must(uuid.NewV7()),
-		MerchantID: merchantID,
-		DomainURL:  domainURL,
+	return r.db.WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		var reset models.PasswordReset
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ? AND used_at IS NULL", helpers.HashSHA256(*params.ResetToken)).
+			First(&reset).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		if err != nil {
+			return err
+		}
 
-		KeyID:     keyID,
-		APIKey:    apiKeyHash,
-		APISecret: encryptedSecret,
-		IsEnabled: true,
+		now := time.Now()
+		if now.After(reset.ExpiresAt) {
+			return ErrInvalidResetToken
+		}
 
-		WebhookURL: webhookURL,
-	}
+		var merchant models.Merchant
+		err = tx.First(&merchant, "id = ?", reset.MerchantID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		if err != nil {
+			return err
+		}
 
-	if err := r.db.Create(domain).Error; err != nil {
-		return nil, err
-	}
+		if err := tx.Model(&reset).Update("used_at", now).Error; err != nil {
+			return err
+		}
 
-	return domain, nil
+		if err := setPassword(tx, &merchant, *params.Password); err != nil {
+			return err
+		}
+
+		return revokeSessions(tx, merchant.ID, nil)
+	})
+}
+
+func setPassword(tx *gorm.DB, merchant *models.Merchant, password string) error {
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
 
+	return tx.Model(merchant).Updates(map[string]interface{}{
+		"password":                string(hashedPassword),
+		"password_case_sensitive": true,
+		"password_changed_at":     time.Now(),
+		"failed_login_attempts":   0,
+		"locked_until":            nil,
+	}).Error
 }
@@ -0,0 +1,261 @@
+package repositories
+
+import (
+	"context"
+	"core/blockchain"
+	"core/constants"
+	"core/models"
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// OutgoingTxRepo keeps the attempts of the transactions the gateway
+// broadcast until one of them is mined.
+type OutgoingTxRepo struct {
+	merchantRepo *MerchantRepo
+}
+
+func NewOutgoingTxRepo(merchantRepo *MerchantRepo) *OutgoingTxRepo {
+	return &OutgoingTxRepo{merchantRepo: merchantRepo}
+}
+
+func (r *OutgoingTxRepo) DB() *gorm.DB {
+	return r.merchantRepo.DB()
+}
+
+// Attempt builds the first attempt of a transaction broadcast for a
+// withdrawal, sweep or gas top-up, signed by the given HD key. It is
+// recorded with the broadcast of what it pays for, so the tracker follows
+// every transaction that left. Results of chains that cannot replace
+// transactions carry no Sent, they are only followed until mined.
+func (r *OutgoingTxRepo) Attempt(ctx context.Context, chainName, kind string, referenceID uuid.UUID, hdAccountID, hdAddressID uint32, result *blockchain.TransactionResult) (*models.OutgoingTx, error) {
+	chain, err := r.merchantRepo.Blockchains().GetChain(chainName)
+	if err != nil {
+		return nil, err
+	}
+	head, err := chain.BlockNumber(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	id := uuid.New()
+	attempt := outgoingAttempt(result, head)
+	attempt.ID = id
+	attempt.RootID = id
+	attempt.ChainID = chain.ChainID()
+	attempt.Chain = chain.Name()
+	attempt.Kind = kind
+	attempt.ReferenceID = referenceID
+	attempt.HDAccountID = hdAccountID
+	attempt.HDAddressId = hdAddressID
+	return attempt, nil
+}
+
+// InFlight returns the latest attempt of transactions none of whose
+// attempts is known to be mined, oldest first.
+func (r *OutgoingTxRepo) InFlight(ctx context.Context, limit int) ([]models.OutgoingTx, error) {
+	var attempts []models.OutgoingTx
+	err := r.DB().WithContext(ctx).
+		Where("status = ?", constants.OUTGOING_STATUS_PENDING).
+		Order("broadcast_at ASC").
+		Limit(limit).
+		Find(&attempts).Error
+	return attempts, err
+}
+
+// Attempts returns the attempts of a transaction that may still be mined,
+// first attempt first.
+func (r *OutgoingTxRepo) Attempts(ctx context.Context, rootID uuid.UUID) ([]models.OutgoingTx, error) {
+	var attempts []models.OutgoingTx
+	err := r.DB().WithContext(ctx).
+		Where("root_id = ? AND status IN ?", rootID,
+			[]string{constants.OUTGOING_STATUS_PENDING, constants.OUTGOING_STATUS_REPLACED}).
+		Order("broadcast_at ASC").
+		Find(&attempts).Error
+	return attempts, err
+}
+
+// Replaced records that latest was replaced by the transaction in result,
+// a cancellation when cancel is set or latest already was one.
+func (r *OutgoingTxRepo) Replaced(ctx context.Context, latest *models.OutgoingTx, result *blockchain.TransactionResult, head uint64, cancel bool) (*models.OutgoingTx, error) {
+	replacement := outgoingAttempt(result, head)
+	err := r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current models.OutgoingTx
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, "id = ?", latest.ID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrOutgoingTxNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		current.Status = constants.OUTGOING_STATUS_REPLACED
+		current.Action = nil
+		current.UpdatedAt = time.Now()
+		if err := tx.Save(&current).Error; err != nil {
+			return err
+		}
+
+		replacement.ID = uuid.New()
+		replacement.RootID = current.RootID
+		replacement.ReplacesID = &current.ID
+		replacement.ChainID = current.ChainID
+		replacement.Chain = current.Chain
+		replacement.Kind = current.Kind
+		replacement.ReferenceID = current.ReferenceID
+		replacement.HDAccountID = current.HDAccountID
+		replacement.HDAddressId = current.HDAddressId
+		replacement.Cancel = cancel || current.Cancel
+		return tx.Create(replacement).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return replacement, nil
+}
+
+// Mined records the attempt the chain included, failed when it reverted,
+// and drops the other attempts of its transaction.
+func (r *OutgoingTxRepo) Mined(ctx context.Context, attempt *models.OutgoingTx, inclusion *blockchain.TxInclusion) error {
+	return r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		status := constants.OUTGOING_STATUS_MINED
+		if !inclusion.Success {
+			status = constants.OUTGOING_STATUS_FAILED
+		}
+
+		if err := tx.Model(&models.OutgoingTx{}).
+			Where("id = ?", attempt.ID).
+			Updates(map[string]interface{}{
+				"status":      status,
+				"action":      nil,
+				"mined_block": inclusion.BlockNumber,
+				"mined_at":    now,
+				"updated_at":  now,
+			}).Error; err != nil {
+			return err
+		}
+		return dropAttempts(tx, attempt.RootID)
+	})
+}
+
+// Unsettled returns the mined attempts whose withdrawal or sweep is not
+// settled yet, oldest first.
+func (r *OutgoingTxRepo) Unsettled(ctx context.Context, limit int) ([]models.OutgoingTx, error) {
+	var attempts []models.OutgoingTx
+	err := r.DB().WithContext(ctx).
+		Where("status IN ? AND settled_at IS NULL",
+			[]string{constants.OUTGOING_STATUS_MINED, constants.OUTGOING_STATUS_FAILED}).
+		Order("mined_at ASC").
+		Limit(limit).
+		Find(&attempts).Error
+	return attempts, err
+}
+
+// Settled records that the withdrawal or sweep of the mined attempt was
+// settled.
+func (r *OutgoingTxRepo) Settled(ctx context.Context, id uuid.UUID) error {
+	now := time.Now()
+	return r.DB().WithContext(ctx).Model(&models.OutgoingTx{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{"settled_at": now, "updated_at": now}).Error
+}
+
+// Unmined reopens the transaction of an attempt whose block a reorg
+// dropped before it was settled: its newest attempt is pending again and
+// the others replaced, any of them may still be mined.
+func (r *OutgoingTxRepo) Unmined(ctx context.Context, attempt *models.OutgoingTx) error {
+	return r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var attempts []models.OutgoingTx
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("root_id = ?", attempt.RootID).
+			Order("broadcast_at DESC").
+			Find(&attempts).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		for i := range attempts {
+			status := constants.OUTGOING_STATUS_REPLACED
+			if i == 0 {
+				status = constants.OUTGOING_STATUS_PENDING
+			}
+			if err := tx.Model(&attempts[i]).Updates(map[string]interface{}{
+				"status":      status,
+				"mined_block": nil,
+				"mined_at":    nil,
+				"updated_at":  now,
+			}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Dropped gives up on every attempt of a transaction, e.g. when another
+// transaction took its nonce.
+func (r *OutgoingTxRepo) Dropped(ctx context.Context, rootID uuid.UUID) error {
+	return dropAttempts(r.DB().WithContext(ctx), rootID)
+}
+
+// requestOutgoingAction asks for the pending transaction of a withdrawal or
+// sweep to be sped up or cancelled on the next check. Only attempts that
+// record what was sent can be replaced, see OutgoingTx.Replaceable.
+func requestOutgoingAction(tx *gorm.DB, kind string, referenceID uuid.UUID, action string) error {
+	result := tx.Model(&models.OutgoingTx{}).
+		Where("kind = ? AND reference_id = ? AND status = ? AND gas_limit > 0",
+			kind, referenceID, constants.OUTGOING_STATUS_PENDING).
+		Updates(map[string]interface{}{"action": action, "updated_at": time.Now()})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrTxNotReplaceable
+	}
+	return nil
+}
+
+func dropAttempts(tx *gorm.DB, rootID uuid.UUID) error {
+	return tx.Model(&models.OutgoingTx{}).
+		Where("root_id = ? AND status IN ?", rootID,
+			[]string{constants.OUTGOING_STATUS_PENDING, constants.OUTGOING_STATUS_REPLACED}).
+		Updates(map[string]interface{}{
+			"status":     constants.OUTGOING_STATUS_DROPPED,
+			"action":     nil,
+			"updated_at": time.Now(),
+		}).Error
+}
+
+func outgoingAttempt(result *blockchain.TransactionResult, head uint64) *models.OutgoingTx {
+	sent := result.Sent
+	if sent == nil {
+		sent = &blockchain.SentTx{GasPrice: new(big.Int)}
+	}
+	attempt := &models.OutgoingTx{
+		FromAddress:    sent.From,
+		ToAddress:      sent.To,
+		Nonce:          sent.Nonce,
+		Value:          "0",
+		Data:           sent.Data,
+		GasLimit:       sent.GasLimit,
+		GasPrice:       sent.GasPrice.String(),
+		TxHash:         result.TxHash,
+		Status:         constants.OUTGOING_STATUS_PENDING,
+		BroadcastBlock: head,
+		BroadcastAt:    time.Now(),
+	}
+	if sent.Value != nil {
+		attempt.Value = sent.Value.String()
+	}
+	if sent.GasTipCap != nil {
+		tip := sent.GasTipCap.String()
+		attempt.GasTipCap = &tip
+	}
+	return attempt
+}
@@ -0,0 +1,40 @@
+package repositories
+
+import (
+	"core/helpers"
+	"core/types"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// pageQuery orders newest first and continues after the cursor. It fetches
+// one extra row so trimPage can tell whether another page exists.
+func pageQuery(query *gorm.DB, table string, page types.Pagination) (*gorm.DB, error) {
+	if page.Cursor != nil && *page.Cursor != "" {
+		createdAt, id, err := helpers.DecodeCursor(*page.Cursor)
+		if err != nil {
+			return nil, types.NewValidationError("cursor", "invalid cursor")
+		}
+		query = query.Where("("+table+".created_at, "+table+".id) < (?, ?)", createdAt, id)
+	}
+
+	return query.
+		Order(table + ".created_at DESC").
+		Order(table + ".id DESC").
+		Limit(page.PageSize() + 1), nil
+}
+
+// trimPage drops the extra row fetched by pageQuery and returns the cursor of
+// the next page, nil on the last one.
+func trimPage[T any](items []T, page types.Pagination, key func(T) (time.Time, uuid.UUID)) ([]T, *string) {
+	limit := page.PageSize()
+	if len(items) <= limit {
+		return items, nil
+	}
+
+	items = items[:limit]
+	cursor := helpers.EncodeCursor(key(items[limit-1]))
+	return items, &cursor
+}
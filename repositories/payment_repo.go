@@ -0,0 +1,492 @@
+package repositories
+
+import (
+	"context"
+	"core/asset"
+	"core/blockchain"
+	"core/constants"
+	"core/helpers"
+	"core/models"
+	"core/pricing"
+	"core/types"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PaymentRequestRepo struct {
+	walletRepo *WalletRepo
+	assets     *asset.Registry
+	prices     *pricing.Oracle
+}
+
+func (r *PaymentRequestRepo) DB() *gorm.DB {
+	return r.walletRepo.DB()
+}
+
+func NewPaymentRequestRepo(walletRepo *WalletRepo, assets *asset.Registry, prices *pricing.Oracle) *PaymentRequestRepo {
+	return &PaymentRequestRepo{walletRepo: walletRepo, assets: assets, prices: prices}
+}
+
+func (r *PaymentRequestRepo) Create(params types.PaymentRequestParams) (*models.PaymentRequest, error) {
+	if err := params.ValidateCreate(); err != nil {
+		return nil, err
+	}
+
+	chain, err := r.walletRepo.Domain().MerchantRepo().Blockchains().GetChain(*params.Chain)
+	if err != nil {
+		return nil, types.NewValidationError("chain", "unknown chain")
+	}
+
+	assetInfo, ok := resolveAsset(r.assets, chain.ChainID(), *params.Asset)
+	if !ok {
+		return nil, types.NewValidationError("asset", "unknown asset on "+chain.Name())
+	}
+
+	var amount *big.Int
+	var quote *pricing.Quote
+	var fiatAmount, fiatCurrency string
+	tolerance := 0
+
+	if params.FiatAmount != nil && *params.FiatAmount != "" {
+		cents, err := helpers.ParseUnits(*params.FiatAmount, constants.FIAT_DECIMALS)
+		if err != nil || cents.Sign() <= 0 {
+			return nil, types.NewValidationError("fiat_amount", "fiat_amount must be a positive decimal with at most 2 decimals")
+		}
+
+		fiatCurrency = strings.ToUpper(*params.FiatCurrency)
+		quote, err = r.prices.Quote(params.Context, assetInfo.GetSymbol(), fiatCurrency)
+		if err != nil {
+			return nil, err
+		}
+
+		amount, err = quote.ToUnits(new(big.Rat).SetFrac(cents, big.NewInt(100)), assetInfo.GetDecimals())
+		if err != nil {
+			return nil, err
+		}
+		fiatAmount = helpers.FormatUnits(cents, constants.FIAT_DECIMALS)
+		tolerance = constants.PAYMENT_DEFAULT_FIAT_TOLERANCE_BPS
+	} else {
+		amount, err = helpers.ParseUnits(*params.Amount, assetInfo.GetDecimals())
+		if err != nil || amount.Sign() <= 0 {
+			return nil, types.NewValidationError("amount",
+				fmt.Sprintf("amount must be a positive decimal with at most %d decimals", assetInfo.GetDecimals()))
+		}
+	}
+	if params.ToleranceBps != nil {
+		tolerance = *params.ToleranceBps
+	}
+
+	expiresIn := constants.PAYMENT_DEFAULT_EXPIRY
+	if params.ExpiresIn != nil {
+		expiresIn = time.Duration(*params.ExpiresIn) * time.Second
+	}
+
+	merchantUUID, _ := uuid.Parse(*params.MerchantID)
+
+	var request *models.PaymentRequest
+	err = r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		// The domain row lock serializes address selection within the domain.
+		domain, err := lockMerchantDomain(tx, merchantUUID, *params.DomainID)
+		if err != nil {
+			return err
+		}
+		if !domain.IsEnabled {
+			return ErrDomainDisabled
+		}
+
+		var count int64
+		if err := tx.Model(&models.PaymentRequest{}).
+			Where("domain_id = ? AND order_id = ?", domain.ID, *params.OrderID).
+			Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return ErrPaymentRequestExists
+		}
+
+		walletID, address, err := r.depositAddress(params.Context, tx, domain, chain, params.ReuseAddress)
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		request = &models.PaymentRequest{
+			ID:         uuid.New(),
+			MerchantID: domain.MerchantID,
+			DomainID:   domain.ID,
+			WalletID:   walletID,
+			OrderID:    *params.OrderID,
+			ChainID:    chain.ChainID(),
+			Address:    address,
+			Asset:      assetInfo.GetSymbol(),
+			Decimals:   assetInfo.GetDecimals(),
+			Amount:     amount.String(),
+			AmountPaid: "0",
+			Status:     constants.PAYMENT_STATUS_PENDING,
+			Metadata:   params.Metadata,
+			ExpiresAt:  now.Add(expiresIn),
+			CreatedAt:  now,
+			UpdatedAt:  now,
+
+			ToleranceBps: uint16(tolerance),
+		}
+		if quote != nil {
+			request.FiatCurrency = &fiatCurrency
+			request.FiatAmount = &fiatAmount
+			request.Rate = &quote.Rate
+			request.RateProvider = &quote.Provider
+			request.RateLockedAt = &quote.FetchedAt
+		}
+		if !assetInfo.IsNative() {
+			token := assetInfo.GetIdentifier()
+			request.Token = &token
+		}
+
+		if err := tx.Create(request).Error; err != nil {
+			return err
+		}
+		return recordPaymentEvent(tx, request)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+func (r *PaymentRequestRepo) Fetch(params types.PaymentRequestParams) (*models.PaymentRequest, error) {
+	if err := params.ValidateLookup(); err != nil {
+		return nil, err
+	}
+
+	query := r.DB().WithContext(params.Context).
+		Where("merchant_id = ? AND domain_id = ?", *params.MerchantID, *params.DomainID)
+	if params.PaymentRequestID != nil && *params.PaymentRequestID != "" {
+		query = query.Where("id = ?", *params.PaymentRequestID)
+	} else {
+		query = query.Where("order_id = ?", *params.OrderID)
+	}
+
+	var request models.PaymentRequest
+	err := query.First(&request).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrPaymentRequestNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &request, nil
+}
+
+func (r *PaymentRequestRepo) List(params types.PaymentRequestParams) ([]models.PaymentRequest, *string, error) {
+	if err := params.ValidateList(); err != nil {
+		return nil, nil, err
+	}
+
+	query := r.DB().WithContext(params.Context).
+		Where("payment_requests.merchant_id = ? AND payment_requests.domain_id = ?", *params.MerchantID, *params.DomainID)
+	if params.Status != nil {
+		query = query.Where("payment_requests.status = ?", *params.Status)
+	}
+
+	query, err := pageQuery(query, "payment_requests", params.Pagination)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var requests []models.PaymentRequest
+	if err := query.Find(&requests).Error; err != nil {
+		return nil, nil, err
+	}
+
+	requests, cursor := trimPage(requests, params.Pagination, func(p models.PaymentRequest) (time.Time, uuid.UUID) {
+		return p.CreatedAt, p.ID
+	})
+	return requests, cursor, nil
+}
+
+// MatchActive matches new transfers against every request that has not
+// expired yet and returns how many requests changed.
+func (r *PaymentRequestRepo) MatchActive(ctx context.Context) (int, error) {
+	var ids []uuid.UUID
+	if err := r.DB().WithContext(ctx).
+		Model(&models.PaymentRequest{}).
+		Where("status <> ? AND expires_at > ?", constants.PAYMENT_STATUS_EXPIRED, time.Now()).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, id := range ids {
+		updated, err := r.settle(ctx, id, false)
+		if err != nil {
+			return changed, err
+		}
+		if updated {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// ExpireDue runs a last match on unpaid requests past their expiry and marks
+// the ones still short as expired.
+func (r *PaymentRequestRepo) ExpireDue(ctx context.Context) (int, error) {
+	var ids []uuid.UUID
+	if err := r.DB().WithContext(ctx).
+		Model(&models.PaymentRequest{}).
+		Where("status IN ? AND expires_at <= ?",
+			[]string{constants.PAYMENT_STATUS_PENDING, constants.PAYMENT_STATUS_PARTIALLY_PAID}, time.Now()).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	changed := 0
+	for _, id := range ids {
+		updated, err := r.settle(ctx, id, true)
+		if err != nil {
+			return changed, err
+		}
+		if updated {
+			changed++
+		}
+	}
+	return changed, nil
+}
+
+// settle locks the request, assigns unmatched transfers to it and moves it
+// to its new state. With expire set, a request still short is expired.
+func (r *PaymentRequestRepo) settle(ctx context.Context, id uuid.UUID, expire bool) (bool, error) {
+	changed := false
+
+	err := r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var request models.PaymentRequest
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&request, "id = ?", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if request.Status == constants.PAYMENT_STATUS_EXPIRED {
+			return nil
+		}
+
+		paid, err := matchTransfers(tx, &request)
+		if err != nil {
+			return err
+		}
+
+		status := paymentStatus(&request, paid)
+		if expire && (status == constants.PAYMENT_STATUS_PENDING || status == constants.PAYMENT_STATUS_PARTIALLY_PAID) {
+			status = constants.PAYMENT_STATUS_EXPIRED
+		}
+
+		if status == request.Status && paid.String() == request.AmountPaid {
+			return nil
+		}
+
+		updates := map[string]interface{}{
+			"status":      status,
+			"amount_paid": paid.String(),
+		}
+		if request.PaidAt == nil && (status == constants.PAYMENT_STATUS_PAID || status == constants.PAYMENT_STATUS_OVERPAID) {
+			updates["paid_at"] = time.Now()
+		}
+		if err := tx.Model(&request).Updates(updates).Error; err != nil {
+			return err
+		}
+
+		changed = true
+		return recordPaymentEvent(tx, &request)
+	})
+
+	return changed, err
+}
+
+// matchTransfers assigns transfers of the request asset to its address,
+// received between creation and expiry, and returns the new paid total.
+// Transfers received outside that window are never taken, even while no
+// other request is open on the address.
+func matchTransfers(tx *gorm.DB, request *models.PaymentRequest) (*big.Int, error) {
+	paid, ok := new(big.Int).SetString(request.AmountPaid, 10)
+	if !ok {
+		paid = big.NewInt(0)
+	}
+
+	query := tx.Model(&models.Transaction{}).
+		Where("payment_request_id IS NULL AND chain_id = ? AND status NOT IN ?", request.ChainID,
+			[]string{constants.TX_STATUS_FAILED, constants.TX_STATUS_ORPHANED}).
+		Where("created_at >= ? AND created_at <= ?", request.CreatedAt, request.ExpiresAt).
+		Where(notGasFunding)
+	if request.ChainID.IsEVM() {
+		query = query.Where("LOWER(to_address) = LOWER(?)", request.Address)
+	} else {
+		query = query.Where("to_address = ?", request.Address)
+	}
+	if request.Token != nil {
+		query = query.Where("LOWER(token) = LOWER(?)", *request.Token)
+	} else {
+		query = query.Where("token IS NULL AND symbol = ?", request.Asset)
+	}
+
+	var transfers []models.Transaction
+	if err := query.
+		Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+		Find(&transfers).Error; err != nil {
+		return nil, err
+	}
+	if len(transfers) == 0 {
+		return paid, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(transfers))
+	for _, transfer := range transfers {
+		value, ok := new(big.Int).SetString(transfer.Amount, 10)
+		if !ok {
+			continue
+		}
+		paid.Add(paid, value)
+		ids = append(ids, transfer.ID)
+	}
+
+	if len(ids) > 0 {
+		if err := tx.Model(&models.Transaction{}).
+			Where("id IN ?", ids).
+			Update("payment_request_id", request.ID).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return paid, nil
+}
+
+// unmatchTransfer takes a transfer that did not make it on chain off the
+// request it paid. An expired request stays expired.
+func unmatchTransfer(tx *gorm.DB, transfer *models.Transaction) error {
+	if transfer.PaymentRequestID == nil {
+		return nil
+	}
+
+	var request models.PaymentRequest
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&request, "id = ?", *transfer.PaymentRequestID).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(transfer).Update("payment_request_id", nil).Error; err != nil {
+		return err
+	}
+
+	paid, _ := new(big.Int).SetString(request.AmountPaid, 10)
+	value, _ := new(big.Int).SetString(transfer.Amount, 10)
+	if paid == nil || value == nil {
+		return nil
+	}
+	paid.Sub(paid, value)
+	if paid.Sign() < 0 {
+		paid.SetInt64(0)
+	}
+
+	status := request.Status
+	if status != constants.PAYMENT_STATUS_EXPIRED {
+		status = paymentStatus(&request, paid)
+	}
+	if err := tx.Model(&request).Updates(map[string]interface{}{
+		"status":      status,
+		"amount_paid": paid.String(),
+	}).Error; err != nil {
+		return err
+	}
+	return recordPaymentEvent(tx, &request)
+}
+
+// paymentStatus judges the paid total against the expected amount, where
+// anything within the request tolerance on either side counts as paid.
+func paymentStatus(request *models.PaymentRequest, paid *big.Int) string {
+	amount, _ := new(big.Int).SetString(request.Amount, 10)
+
+	margin := new(big.Int).Mul(amount, big.NewInt(int64(request.ToleranceBps)))
+	margin.Quo(margin, big.NewInt(10000))
+	lower := new(big.Int).Sub(amount, margin)
+	upper := new(big.Int).Add(amount, margin)
+
+	switch {
+	case paid.Sign() == 0:
+		return constants.PAYMENT_STATUS_PENDING
+	case paid.Cmp(lower) < 0:
+		return constants.PAYMENT_STATUS_PARTIALLY_PAID
+	case paid.Cmp(upper) <= 0:
+		return constants.PAYMENT_STATUS_PAID
+	default:
+		return constants.PAYMENT_STATUS_OVERPAID
+	}
+}
+
+// depositAddress picks the address the request is paid to, an idle one
+// with reuse, otherwise one of a new wallet.
+func (r *PaymentRequestRepo) depositAddress(ctx context.Context, tx *gorm.DB, domain *models.Domain, chain blockchain.Chain, reuse bool) (uuid.UUID, string, error) {
+	if reuse {
+		idle, err := idleDepositAddress(tx, domain.ID, chain.ChainID())
+		if err == nil {
+			return idle.WalletID, idle.Address, nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return uuid.Nil, "", err
+		}
+	}
+
+	wallet, err := r.walletRepo.createWallet(ctx, tx, domain, []string{chain.Name()})
+	if err != nil {
+		return uuid.Nil, "", err
+	}
+
+	address, _ := wallet.Address(chain.ChainID())
+	return wallet.ID, address, nil
+}
+
+// idleDepositAddress only considers wallets created for earlier payment
+// requests whose last request expired at least PAYMENT_REUSE_COOLDOWN ago,
+// so static deposit addresses are never shared and the windows of requests
+// on one address never touch.
+func idleDepositAddress(tx *gorm.DB, domainID uuid.UUID, chainID constants.ChainID) (*models.WalletAddress, error) {
+	var idle models.WalletAddress
+	err := tx.
+		Where("domain_id = ? AND chain_id = ?", domainID, chainID).
+		Where("wallet_id IN (SELECT wallet_id FROM payment_requests WHERE domain_id = ?)", domainID).
+		Where(`NOT EXISTS (
+			SELECT 1 FROM payment_requests pr
+			WHERE pr.chain_id = wallet_addresses.chain_id
+			AND pr.address = wallet_addresses.address
+			AND pr.expires_at > ?)`, time.Now().Add(-constants.PAYMENT_REUSE_COOLDOWN)).
+		Order("created_at").
+		First(&idle).Error
+	if err != nil {
+		return nil, err
+	}
+	return &idle, nil
+}
+
+// resolveAsset accepts a token contract, a native identifier or a symbol.
+func resolveAsset(assets *asset.Registry, chainID constants.ChainID, identifier string) (asset.Asset, bool) {
+	if a, ok := assets.Get(chainID, identifier); ok {
+		return a, true
+	}
+	return assets.GetBySymbol(chainID, identifier)
+}
+
+func recordPaymentEvent(tx *gorm.DB, request *models.PaymentRequest) error {
+	return tx.Create(&models.PaymentRequestEvent{
+		ID:               uuid.New(),
+		PaymentRequestID: request.ID,
+		DomainID:         request.DomainID,
+		Status:           request.Status,
+		AmountPaid:       request.AmountPaid,
+	}).Error
+}
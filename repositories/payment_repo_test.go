@@ -0,0 +1,199 @@
+package repositories
+
+import (
+	"context"
+	"core/constants"
+	"core/models"
+	"math/big"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func Test_PaymentStatus(t *testing.T) {
+	request := &models.PaymentRequest{Amount: "10000", ToleranceBps: 50}
+
+	cases := []struct {
+		paid   int64
+		status string
+	}{
+		{0, constants.PAYMENT_STATUS_PENDING},
+		{9949, constants.PAYMENT_STATUS_PARTIALLY_PAID},
+		{9950, constants.PAYMENT_STATUS_PAID},
+		{10000, constants.PAYMENT_STATUS_PAID},
+		{10050, constants.PAYMENT_STATUS_PAID},
+		{10051, constants.PAYMENT_STATUS_OVERPAID},
+	}
+	for _, c := range cases {
+		if status := paymentStatus(request, big.NewInt(c.paid)); status != c.status {
+			t.Errorf("paid %d: expected %s, got %s", c.paid, c.status, status)
+		}
+	}
+}
+
+// Test_PaymentMatching needs a disposable Postgres database in
+// TEST_DATABASE_URL.
+func Test_PaymentMatching(t *testing.T) {
+	dsn := os.Getenv("TEST_DATABASE_URL")
+	if dsn == "" {
+		t.Skip("TEST_DATABASE_URL not set")
+	}
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{DisableForeignKeyConstraintWhenMigrating: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.AutoMigrate(&models.PaymentRequest{}, &models.PaymentRequestEvent{},
+		&models.Transaction{}, &models.Sweep{}, &models.WalletAddress{}); err != nil {
+		t.Fatal(err)
+	}
+
+	repo := NewPaymentRequestRepo(NewWalletRepo(NewDomainRepo(NewMerchantRepo(db, nil))), nil, nil)
+	ctx := context.Background()
+	address := "T" + uuid.NewString()[:33]
+	domainID, walletID := uuid.New(), uuid.New()
+	now := time.Now()
+
+	if err := db.Create(&models.WalletAddress{
+		ID:             uuid.New(),
+		WalletID:       walletID,
+		MerchantID:     uuid.New(),
+		DomainID:       domainID,
+		ChainID:        constants.TRON,
+		Chain:          "tron",
+		Address:        address,
+		DerivationPath: "m/44'/195'/0'/0/0",
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+	idle := func() bool {
+		_, err := idleDepositAddress(db, domainID, constants.TRON)
+		if err != nil && err != gorm.ErrRecordNotFound {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+
+	request := func(created, expires time.Time) *models.PaymentRequest {
+		r := &models.PaymentRequest{
+			ID:         uuid.New(),
+			MerchantID: uuid.New(),
+			DomainID:   domainID,
+			WalletID:   walletID,
+			OrderID:    uuid.NewString(),
+			ChainID:    constants.TRON,
+			Address:    address,
+			Asset:      "TRX",
+			Decimals:   6,
+			Amount:     "100",
+			AmountPaid: "0",
+			Status:     constants.PAYMENT_STATUS_PENDING,
+			ExpiresAt:  expires,
+			CreatedAt:  created,
+		}
+		if err := db.Create(r).Error; err != nil {
+			t.Fatal(err)
+		}
+		return r
+	}
+	transfer := func(amount string, received time.Time) *models.Transaction {
+		hash := uuid.NewString()
+		tx := &models.Transaction{
+			ID:          uuid.New(),
+			ChainID:     constants.TRON,
+			UniqueHash:  hash,
+			Hash:        hash[:32],
+			BlockNumber: "1",
+			Symbol:      "TRX",
+			Decimals:    6,
+			FromAddress: "sender",
+			ToAddress:   address,
+			Amount:      amount,
+			Status:      constants.TX_STATUS_CONFIRMED,
+			CreatedAt:   received,
+		}
+		if err := db.Create(tx).Error; err != nil {
+			t.Fatal(err)
+		}
+		return tx
+	}
+	reload := func(r *models.PaymentRequest) *models.PaymentRequest {
+		var fresh models.PaymentRequest
+		if err := db.First(&fresh, "id = ?", r.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		return &fresh
+	}
+	matchedTo := func(tx *models.Transaction) *uuid.UUID {
+		var fresh models.Transaction
+		if err := db.First(&fresh, "id = ?", tx.ID).Error; err != nil {
+			t.Fatal(err)
+		}
+		return fresh.PaymentRequestID
+	}
+
+	// An expired request and a late payment for it. The address is not
+	// reused before the cooldown, then the next request gets it.
+	expired := request(now.Add(-time.Hour), now.Add(-30*time.Minute))
+	if idle() {
+		t.Fatal("address reused within the cooldown")
+	}
+	if err := db.Model(expired).Updates(map[string]interface{}{
+		"created_at": now.Add(-constants.PAYMENT_REUSE_COOLDOWN - time.Hour),
+		"expires_at": now.Add(-constants.PAYMENT_REUSE_COOLDOWN - time.Minute),
+	}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if !idle() {
+		t.Fatal("address not reused after the cooldown")
+	}
+	late := transfer("100", now.Add(-constants.PAYMENT_REUSE_COOLDOWN))
+	next := request(now.Add(-10*time.Minute), now.Add(time.Hour))
+
+	if _, err := repo.ExpireDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if status := reload(expired).Status; status != constants.PAYMENT_STATUS_EXPIRED {
+		t.Fatalf("expected the unpaid request to expire, got %s", status)
+	}
+
+	partial := transfer("40", now.Add(-5*time.Minute))
+	if _, err := repo.MatchActive(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if id := matchedTo(late); id != nil {
+		t.Fatalf("late transfer matched to %s", id)
+	}
+	if id := matchedTo(partial); id == nil || *id != next.ID {
+		t.Fatalf("expected the transfer to match the open request, got %v", id)
+	}
+	if got := reload(next); got.Status != constants.PAYMENT_STATUS_PARTIALLY_PAID || got.AmountPaid != "40" {
+		t.Fatalf("expected partially_paid 40, got %s %s", got.Status, got.AmountPaid)
+	}
+
+	transfer("60", now.Add(-time.Minute))
+	if _, err := repo.MatchActive(ctx); err != nil {
+		t.Fatal(err)
+	}
+	got := reload(next)
+	if got.Status != constants.PAYMENT_STATUS_PAID || got.AmountPaid != "100" || got.PaidAt == nil {
+		t.Fatalf("expected paid 100, got %s %s", got.Status, got.AmountPaid)
+	}
+
+	if _, err := repo.ExpireDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if status := reload(next).Status; status != constants.PAYMENT_STATUS_PAID {
+		t.Fatalf("a paid request must not expire, got %s", status)
+	}
+
+	var events int64
+	db.Model(&models.PaymentRequestEvent{}).Where("payment_request_id = ?", next.ID).Count(&events)
+	if events != 2 {
+		t.Fatalf("expected 2 events, got %d", events)
+	}
+}
@@ -0,0 +1,314 @@
+package repositories
+
+import (
+	"core/helpers"
+	"core/models"
+	"core/types"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	AccessTokenTTL   = 15 * time.Minute
+	RefreshTokenTTL  = 30 * 24 * time.Hour
+	MaxFailedLogins  = 5
+	LoginLockoutTime = 15 * time.Minute
+	PasswordResetTTL = time.Hour
+)
+
+var (
+	dummyHashOnce sync.Once
+	dummyHash     []byte
+)
+
+// dummyPasswordHash is compared against when the email is unknown so that
+// response timing does not reveal which accounts exist.
+func dummyPasswordHash() []byte {
+	dummyHashOnce.Do(func() {
+		dummyHash, _ = bcrypt.GenerateFromPassword([]byte("gateway-dummy-password"), bcrypt.DefaultCost)
+	})
+	return dummyHash
+}
+
+type SessionRepo struct {
+	merchantRepo *MerchantRepo
+}
+
+func (r *SessionRepo) DB() *gorm.DB {
+	return r.merchantRepo.DB()
+}
+
+func NewSessionRepo(merchantRepo *MerchantRepo) *SessionRepo {
+	return &SessionRepo{merchantRepo: merchantRepo}
+}
+
+func (r *SessionRepo) Login(params types.SessionParams) (*types.SessionTokens, error) {
+	if err := params.ValidateLogin(); err != nil {
+		return nil, err
+	}
+
+	var tokens *types.SessionTokens
+	var loginErr error
+
+	err := r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		var merchant models.Merchant
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("LOWER(email) = LOWER(?)", *params.Email).
+			First(&merchant).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			bcrypt.CompareHashAndPassword(dummyPasswordHash(), []byte(*params.Password))
+			loginErr = ErrInvalidCredentials
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if merchant.LockedUntil != nil && merchant.LockedUntil.After(now) {
+			loginErr = ErrAccountLocked
+			return nil
+		}
+
+		if err := checkPassword(tx, &merchant, *params.Password); errors.Is(err, ErrInvalidCredentials) {
+			// Commit the counter, report the failure after the transaction.
+			loginErr = ErrInvalidCredentials
+			return registerFailedLogin(tx, &merchant, now)
+		} else if err != nil {
+			return err
+		}
+
+		if merchant.TOTPEnabled {
+			if params.OTP == nil || *params.OTP == "" {
+				loginErr = ErrTwoFactorRequired
+				return nil
+			}
+			if twoFactorLocked(&merchant, now) {
+				loginErr = ErrTwoFactorLocked
+				return nil
+			}
+
+			err := verifySecondFactor(tx, &merchant, *params.OTP)
+			if errors.Is(err, ErrInvalidTwoFactor) {
+				loginErr = ErrInvalidTwoFactor
+				return registerFailedLogin(tx, &merchant, now)
+			}
+			if err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Model(&merchant).Updates(map[string]interface{}{
+			"failed_login_attempts": 0,
+			"locked_until":          nil,
+		}).Error; err != nil {
+			return err
+		}
+
+		tokens, err = createSession(tx, merchant.ID, params)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if loginErr != nil {
+		return nil, loginErr
+	}
+
+	return tokens, nil
+}
+
+func (r *SessionRepo) Authenticate(params types.SessionParams) (*models.Session, error) {
+	if err := params.ValidateAccessToken(); err != nil {
+		return nil, err
+	}
+
+	var session models.Session
+	err := r.DB().WithContext(params.Context).
+		Where("access_token_hash = ? AND revoked_at IS NULL", helpers.HashSHA256(*params.AccessToken)).
+		First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().After(session.AccessExpiresAt) {
+		return nil, ErrSessionExpired
+	}
+
+	var count int64
+	if err := r.DB().WithContext(params.Context).
+		Model(&models.Merchant{}).
+		Where("id = ?", session.MerchantID).
+		Count(&count).Error; err != nil {
+		return nil, err
+	}
+	if count == 0 {
+		return nil, ErrSessionNotFound
+	}
+
+	return &session, nil
+}
+
+func (r *SessionRepo) Refresh(params types.SessionParams) (*types.SessionTokens, error) {
+	if err := params.ValidateRefresh(); err != nil {
+		return nil, err
+	}
+
+	var tokens *types.SessionTokens
+
+	err := r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		var session models.Session
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("refresh_token_hash = ? AND revoked_at IS NULL", helpers.HashSHA256(*params.RefreshToken)).
+			First(&session).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		if time.Now().After(session.RefreshExpiresAt) {
+			return ErrSessionExpired
+		}
+
+		// Rotate both tokens so a leaked refresh token is only usable once.
+		accessToken, refreshToken, err := newTokenPair()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		session.AccessTokenHash = helpers.HashSHA256(accessToken)
+		session.RefreshTokenHash = helpers.HashSHA256(refreshToken)
+		session.AccessExpiresAt = now.Add(AccessTokenTTL)
+		session.RefreshExpiresAt = now.Add(RefreshTokenTTL)
+		if err := tx.Save(&session).Error; err != nil {
+			return err
+		}
+
+		tokens = &types.SessionTokens{
+			SessionID:        session.ID,
+			AccessToken:      accessToken,
+			AccessExpiresAt:  session.AccessExpiresAt,
+			RefreshToken:     refreshToken,
+			RefreshExpiresAt: session.RefreshExpiresAt,
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+func (r *SessionRepo) Logout(params types.SessionParams) error {
+	if params.SessionID == nil {
+		return ErrSessionNotFound
+	}
+
+	return r.DB().WithContext(params.Context).
+		Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", *params.SessionID).
+		Update("revoked_at", time.Now()).Error
+}
+
+func registerFailedLogin(tx *gorm.DB, merchant *models.Merchant, now time.Time) error {
+	attempts := merchant.FailedLoginAttempts + 1
+	updates := map[string]interface{}{"failed_login_attempts": attempts}
+	if attempts >= MaxFailedLogins {
+		updates["failed_login_attempts"] = 0
+		updates["locked_until"] = now.Add(LoginLockoutTime)
+	}
+	return tx.Model(merchant).Updates(updates).Error
+}
+
+func newTokenPair() (string, string, error) {
+	accessToken, err := helpers.GenerateToken(helpers.AccessPref)
+	if err != nil {
+		return "", "", err
+	}
+	refreshToken, err := helpers.GenerateToken(helpers.RefreshPref)
+	if err != nil {
+		return "", "", err
+	}
+	return accessToken, refreshToken, nil
+}
+
+func createSession(tx *gorm.DB, merchantID uuid.UUID, params types.SessionParams) (*types.SessionTokens, error) {
+	accessToken, refreshToken, err := newTokenPair()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	session := &models.Session{
+		ID:               uuid.New(),
+		MerchantID:       merchantID,
+		AccessTokenHash:  helpers.HashSHA256(accessToken),
+		RefreshTokenHash: helpers.HashSHA256(refreshToken),
+		AccessExpiresAt:  now.Add(AccessTokenTTL),
+		RefreshExpiresAt: now.Add(RefreshTokenTTL),
+		IP:               params.IP,
+		UserAgent:        params.UserAgent,
+	}
+
+	if err := tx.Create(session).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.SessionTokens{
+		SessionID:        session.ID,
+		AccessToken:      accessToken,
+		AccessExpiresAt:  session.AccessExpiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: session.RefreshExpiresAt,
+	}, nil
+}
+
+// revokeSessions revokes every active session of the merchant except keep (if given).
+func revokeSessions(tx *gorm.DB, merchantID uuid.UUID, keep *uuid.UUID) error {
+	query := tx.Model(&models.Session{}).
+		Where("merchant_id = ? AND revoked_at IS NULL", merchantID)
+	if keep != nil {
+		query = query.Where("id <> ?", *keep)
+	}
+	return query.Update("revoked_at", time.Now()).Error
+}
+
+// checkPassword compares password with the merchant's hash and fails with
+// ErrInvalidCredentials. A password stored before passwords were
+// case-sensitive was lowercased on the way in, so it also matches
+// lowercased, and is then rehashed as typed.
+func checkPassword(tx *gorm.DB, merchant *models.Merchant, password string) error {
+	err := bcrypt.CompareHashAndPassword([]byte(merchant.Password), []byte(password))
+	if err != nil && !merchant.PasswordCaseSensitive {
+		err = bcrypt.CompareHashAndPassword([]byte(merchant.Password), []byte(strings.ToLower(password)))
+	}
+	if err != nil {
+		return ErrInvalidCredentials
+	}
+	if merchant.PasswordCaseSensitive {
+		return nil
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+	return tx.Model(merchant).Updates(map[string]interface{}{
+		"password":                string(hashedPassword),
+		"password_case_sensitive": true,
+	}).Error
+}
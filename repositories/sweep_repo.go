@@ -0,0 +1,527 @@
+package repositories
+
+import (
+	"context"
+	"core/asset"
+	"core/blockchain"
+	"core/constants"
+	"core/helpers"
+	"core/models"
+	"core/types"
+	"errors"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Deposits looked at per policy and tick. Addresses beyond it wait for the
+// next tick, oldest deposits first.
+const sweepScanLimit = 1000
+
+// notGasFunding filters out transfers from the gas station to a deposit
+// address. They look like native deposits but belong to the gateway.
+const notGasFunding = `NOT EXISTS (SELECT 1 FROM sweeps WHERE sweeps.chain_id = transactions.chain_id
+	AND LOWER(sweeps.funding_tx_hash) = LOWER(transactions.hash))`
+
+// SweepRepo plans sweeps of deposit addresses to the treasury and follows
+// them until the tracker sees them mined deep enough.
+type SweepRepo struct {
+	merchantRepo *MerchantRepo
+	ledgerRepo   *LedgerRepo
+	assets       *asset.Registry
+}
+
+func (r *SweepRepo) DB() *gorm.DB {
+	return r.merchantRepo.DB()
+}
+
+func NewSweepRepo(merchantRepo *MerchantRepo, ledgerRepo *LedgerRepo, assets *asset.Registry) *SweepRepo {
+	return &SweepRepo{merchantRepo: merchantRepo, ledgerRepo: ledgerRepo, assets: assets}
+}
+
+// sweepDeposit is a confirmed, not yet swept deposit with the HD indexes of
+// the wallet that received it.
+type sweepDeposit struct {
+	ID          uuid.UUID
+	Amount      string
+	WalletID    uuid.UUID
+	Address     string
+	HDAccountID uint32
+	HDAddressId uint32
+}
+
+// Plan claims the confirmed deposits of up to limit addresses whose balance
+// of the policy asset exceeds the dust threshold, and returns one pending
+// sweep per address. Claimed deposits are not planned again unless the
+// sweep fails.
+func (r *SweepRepo) Plan(ctx context.Context, policy types.SweepPolicy, limit int) ([]models.Sweep, error) {
+	if err := policy.Validate(); err != nil {
+		return nil, err
+	}
+	if limit <= 0 {
+		return nil, nil
+	}
+
+	chain, err := r.merchantRepo.Blockchains().GetChain(policy.Chain)
+	if err != nil {
+		return nil, err
+	}
+	if !chain.ValidateAddress(policy.Treasury) {
+		return nil, types.NewValidationError("treasury", "invalid treasury address for "+policy.Chain)
+	}
+	sweepAsset, ok := resolveAsset(r.assets, chain.ChainID(), policy.Asset)
+	if !ok {
+		return nil, types.NewValidationError("asset", "unsupported asset on "+policy.Chain)
+	}
+
+	dust := big.NewInt(0)
+	if policy.Dust != "" {
+		dust, err = helpers.ParseUnits(policy.Dust, sweepAsset.GetDecimals())
+		if err != nil {
+			return nil, types.NewValidationError("dust", "invalid dust amount")
+		}
+	}
+
+	template := models.Sweep{
+		ChainID:     chain.ChainID(),
+		Chain:       policy.Chain,
+		Asset:       sweepAsset.GetSymbol(),
+		Decimals:    sweepAsset.GetDecimals(),
+		Destination: policy.Treasury,
+	}
+	if !sweepAsset.IsNative() {
+		token := sweepAsset.GetIdentifier()
+		template.Token = &token
+	}
+
+	deposits, err := r.unsweptDeposits(ctx, &template)
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		deposit sweepDeposit
+		ids     []uuid.UUID
+		total   *big.Int
+	}
+	byAddress := make(map[string]*candidate)
+	var candidates []*candidate
+	for _, deposit := range deposits {
+		amount, ok := new(big.Int).SetString(deposit.Amount, 10)
+		if !ok || amount.Sign() <= 0 {
+			continue
+		}
+		c, ok := byAddress[deposit.Address]
+		if !ok {
+			c = &candidate{deposit: deposit, total: new(big.Int)}
+			byAddress[deposit.Address] = c
+			candidates = append(candidates, c)
+		}
+		c.ids = append(c.ids, deposit.ID)
+		c.total.Add(c.total, amount)
+	}
+
+	// Largest balances first, they are worth the network fee the most.
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].total.Cmp(candidates[j].total) > 0
+	})
+
+	var planned []models.Sweep
+	for _, c := range candidates {
+		if len(planned) == limit || c.total.Cmp(dust) <= 0 {
+			break
+		}
+
+		sweep := template
+		sweep.WalletID = c.deposit.WalletID
+		sweep.HDAccountID = c.deposit.HDAccountID
+		sweep.HDAddressId = c.deposit.HDAddressId
+		sweep.Address = c.deposit.Address
+
+		claimed, err := r.claim(ctx, &sweep, c.ids, dust)
+		if err != nil {
+			return planned, err
+		}
+		if claimed {
+			planned = append(planned, sweep)
+		}
+	}
+
+	return planned, nil
+}
+
+// unsweptDeposits lists deposits of the sweep asset to gateway addresses
+// that have no sweep in flight and no recently failed one. Only deposits
+// the confirmation worker has confirmed at depth are swept.
+func (r *SweepRepo) unsweptDeposits(ctx context.Context, template *models.Sweep) ([]sweepDeposit, error) {
+	addressJoin := "JOIN wallet_addresses ON wallet_addresses.chain_id = transactions.chain_id AND wallet_addresses.address = transactions.to_address"
+	if template.ChainID.IsEVM() {
+		addressJoin = "JOIN wallet_addresses ON wallet_addresses.chain_id = transactions.chain_id AND LOWER(wallet_addresses.address) = LOWER(transactions.to_address)"
+	}
+
+	query := r.DB().WithContext(ctx).
+		Table("transactions").
+		Select("transactions.id, transactions.amount, wallet_addresses.wallet_id, wallet_addresses.address, wallets.hd_account_id, wallets.hd_address_id").
+		Joins(addressJoin).
+		Joins("JOIN wallets ON wallets.id = wallet_addresses.wallet_id").
+		Where("transactions.chain_id = ? AND transactions.status = ? AND transactions.sweep_id IS NULL",
+			template.ChainID, constants.TX_STATUS_CONFIRMED).
+		Where(notGasFunding).
+		// A gas top-up in flight, or a send not recorded, blocks every asset
+		// of the address, a native sweep would take the gas along.
+		Where(`NOT EXISTS (SELECT 1 FROM sweeps WHERE sweeps.chain_id = wallet_addresses.chain_id
+			AND sweeps.address = wallet_addresses.address
+			AND (sweeps.status IN ? OR (sweeps.asset = ?
+				AND (sweeps.status IN ? OR (sweeps.status = ? AND sweeps.updated_at > ?)))))`,
+			[]string{constants.SWEEP_STATUS_FUNDING, constants.SWEEP_STATUS_UNKNOWN},
+			template.Asset,
+			[]string{constants.SWEEP_STATUS_PENDING, constants.SWEEP_STATUS_BROADCAST},
+			constants.SWEEP_STATUS_FAILED, time.Now().Add(-constants.SWEEP_RETRY_AFTER))
+	if template.Token != nil {
+		query = query.Where("LOWER(transactions.token) = LOWER(?)", *template.Token)
+	} else {
+		query = query.Where("transactions.token IS NULL AND transactions.symbol = ?", template.Asset)
+	}
+
+	var deposits []sweepDeposit
+	err := query.
+		Order("transactions.created_at").
+		Limit(sweepScanLimit).
+		Scan(&deposits).Error
+	return deposits, err
+}
+
+// claim creates the pending sweep and points the deposits at it. It reports
+// false when another worker took the deposits first or what is left of them
+// no longer exceeds the dust threshold.
+func (r *SweepRepo) claim(ctx context.Context, sweep *models.Sweep, ids []uuid.UUID, dust *big.Int) (bool, error) {
+	claimed := false
+
+	err := r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var deposits []models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("id IN ? AND sweep_id IS NULL AND status = ?", ids, constants.TX_STATUS_CONFIRMED).
+			Find(&deposits).Error; err != nil {
+			return err
+		}
+
+		total := new(big.Int)
+		locked := make([]uuid.UUID, 0, len(deposits))
+		for _, deposit := range deposits {
+			amount, ok := new(big.Int).SetString(deposit.Amount, 10)
+			if !ok {
+				continue
+			}
+			total.Add(total, amount)
+			locked = append(locked, deposit.ID)
+		}
+		if total.Cmp(dust) <= 0 {
+			return nil
+		}
+
+		now := time.Now()
+		sweep.ID = uuid.New()
+		sweep.Amount = total.String()
+		sweep.Status = constants.SWEEP_STATUS_PENDING
+		sweep.CreatedAt = now
+		sweep.UpdatedAt = now
+		if err := tx.Create(sweep).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.Transaction{}).
+			Where("id IN ?", locked).
+			Update("sweep_id", sweep.ID).Error; err != nil {
+			return err
+		}
+
+		claimed = true
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return claimed, nil
+}
+
+// MarkBroadcast records the transaction of attempt sent for the sweep,
+// together with the attempt the tracker follows it by.
+func (r *SweepRepo) MarkBroadcast(ctx context.Context, id uuid.UUID, fee *big.Int, attempt *models.OutgoingTx) error {
+	return r.transition(ctx, id, func(tx *gorm.DB, sweep *models.Sweep) error {
+		if sweep.Status != constants.SWEEP_STATUS_PENDING {
+			return nil
+		}
+
+		now := time.Now()
+		sweep.Status = constants.SWEEP_STATUS_BROADCAST
+		sweep.TxHash = &attempt.TxHash
+		sweep.BroadcastAt = &now
+		addSweepFee(sweep, fee)
+		sweep.UpdatedAt = now
+		if err := tx.Save(sweep).Error; err != nil {
+			return err
+		}
+		return tx.Create(attempt).Error
+	})
+}
+
+// MarkFunded records that the gas top-up of a sweep in funding, the first
+// attempt or the replacement txHash, was mined deep enough. Funded hands
+// the sweep back to the sweeper.
+func (r *SweepRepo) MarkFunded(ctx context.Context, id uuid.UUID, txHash string) error {
+	return r.transition(ctx, id, func(tx *gorm.DB, sweep *models.Sweep) error {
+		if sweep.Status != constants.SWEEP_STATUS_FUNDING || sweep.FundedAt != nil {
+			return nil
+		}
+
+		now := time.Now()
+		sweep.FundingTxHash = &txHash
+		sweep.FundedAt = &now
+		sweep.UpdatedAt = now
+		return tx.Save(sweep).Error
+	})
+}
+
+// MarkFunding records the gas top-up of attempt sent to the sweep address,
+// together with the attempt the tracker follows it by, and pins the sweep
+// to the fee it pays for. The sweep waits in funding until the tracker sees
+// the top-up mined deep enough.
+func (r *SweepRepo) MarkFunding(ctx context.Context, id uuid.UUID, fee *blockchain.SweepFee, fundingFee *big.Int, attempt *models.OutgoingTx) error {
+	return r.transition(ctx, id, func(tx *gorm.DB, sweep *models.Sweep) error {
+		if sweep.Status != constants.SWEEP_STATUS_PENDING {
+			return nil
+		}
+
+		gasPrice := fee.GasPrice.String()
+		sweep.Status = constants.SWEEP_STATUS_FUNDING
+		sweep.FundingTxHash = &attempt.TxHash
+		sweep.GasLimit = fee.GasLimit
+		sweep.GasPrice = &gasPrice
+		addSweepFee(sweep, fundingFee)
+		sweep.UpdatedAt = time.Now()
+		if err := tx.Save(sweep).Error; err != nil {
+			return err
+		}
+		return tx.Create(attempt).Error
+	})
+}
+
+// MarkUnknown parks a pending sweep whose transaction or gas top-up left
+// but could not be recorded for the tracker. Its deposits stay claimed, so
+// they are not swept twice, until it is checked on chain.
+func (r *SweepRepo) MarkUnknown(ctx context.Context, id uuid.UUID, reason string) error {
+	return r.transition(ctx, id, func(tx *gorm.DB, sweep *models.Sweep) error {
+		if sweep.Status != constants.SWEEP_STATUS_PENDING {
+			return nil
+		}
+		if len(reason) > 255 {
+			reason = reason[:255]
+		}
+
+		sweep.Status = constants.SWEEP_STATUS_UNKNOWN
+		sweep.FailureReason = &reason
+		sweep.UpdatedAt = time.Now()
+		return tx.Save(sweep).Error
+	})
+}
+
+// Funded moves sweeps whose gas top-up was mined back to pending and
+// returns them, ready to send.
+func (r *SweepRepo) Funded(ctx context.Context) ([]models.Sweep, error) {
+	var ids []uuid.UUID
+	if err := r.DB().WithContext(ctx).
+		Model(&models.Sweep{}).
+		Where("status = ? AND funded_at IS NOT NULL", constants.SWEEP_STATUS_FUNDING).
+		Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+
+	var funded []models.Sweep
+	for _, id := range ids {
+		err := r.transition(ctx, id, func(tx *gorm.DB, sweep *models.Sweep) error {
+			if sweep.Status != constants.SWEEP_STATUS_FUNDING {
+				return nil
+			}
+
+			sweep.Status = constants.SWEEP_STATUS_PENDING
+			sweep.UpdatedAt = time.Now()
+			if err := tx.Save(sweep).Error; err != nil {
+				return err
+			}
+			funded = append(funded, *sweep)
+			return nil
+		})
+		if err != nil {
+			return funded, err
+		}
+	}
+	return funded, nil
+}
+
+// DustDue lists confirmed token sweeps that were topped up with gas and
+// whose leftover has not been recovered yet. Addresses holding unswept
+// native deposits are skipped, their native sweep takes the leftover along.
+func (r *SweepRepo) DustDue(ctx context.Context, olderThan time.Duration, limit int) ([]models.Sweep, error) {
+	var sweeps []models.Sweep
+	err := r.DB().WithContext(ctx).
+		Where("status = ? AND funding_tx_hash IS NOT NULL AND recovered_at IS NULL AND confirmed_at < ?",
+			constants.SWEEP_STATUS_CONFIRMED, time.Now().Add(-olderThan)).
+		Where(`NOT EXISTS (SELECT 1 FROM transactions WHERE transactions.chain_id = sweeps.chain_id
+			AND LOWER(transactions.to_address) = LOWER(sweeps.address) AND transactions.token IS NULL
+			AND transactions.sweep_id IS NULL AND transactions.status NOT IN ?
+			AND `+notGasFunding+`)`,
+			[]string{constants.TX_STATUS_FAILED, constants.TX_STATUS_ORPHANED}).
+		Order("confirmed_at").
+		Limit(limit).
+		Find(&sweeps).Error
+	return sweeps, err
+}
+
+// MarkRecovered closes the gas top-up of a sweep. txHash is nil when the
+// leftover was not worth a transaction.
+func (r *SweepRepo) MarkRecovered(ctx context.Context, id uuid.UUID, txHash *string) error {
+	return r.transition(ctx, id, func(tx *gorm.DB, sweep *models.Sweep) error {
+		if sweep.RecoveredAt != nil {
+			return nil
+		}
+
+		now := time.Now()
+		sweep.RecoveryTxHash = txHash
+		sweep.RecoveredAt = &now
+		sweep.UpdatedAt = now
+		return tx.Save(sweep).Error
+	})
+}
+
+// MarkFailed fails the sweep and releases its deposits for a later sweep.
+func (r *SweepRepo) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	return r.transition(ctx, id, func(tx *gorm.DB, sweep *models.Sweep) error {
+		return failSweep(tx, sweep, reason)
+	})
+}
+
+// ReleaseStale fails sweeps left pending by a worker that stopped between
+// claiming and broadcasting, so their deposits are swept again.
+func (r *SweepRepo) ReleaseStale(ctx context.Context, olderThan time.Duration) (int, error) {
+	var ids []uuid.UUID
+	if err := r.DB().WithContext(ctx).
+		Model(&models.Sweep{}).
+		Where("status = ? AND updated_at < ?", constants.SWEEP_STATUS_PENDING, time.Now().Add(-olderThan)).
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+
+	released := 0
+	for _, id := range ids {
+		err := r.transition(ctx, id, func(tx *gorm.DB, sweep *models.Sweep) error {
+			if sweep.Status != constants.SWEEP_STATUS_PENDING {
+				return nil
+			}
+			return failSweep(tx, sweep, "interrupted before broadcast")
+		})
+		if err != nil {
+			return released, err
+		}
+		released++
+	}
+	return released, nil
+}
+
+// Confirm settles a broadcast sweep whose transaction txHash, the first
+// attempt or a replacement of it, was mined deep enough.
+func (r *SweepRepo) Confirm(ctx context.Context, id uuid.UUID, txHash string) error {
+	return r.transition(ctx, id, func(tx *gorm.DB, sweep *models.Sweep) error {
+		if sweep.Status != constants.SWEEP_STATUS_BROADCAST {
+			return nil
+		}
+
+		now := time.Now()
+		sweep.Status = constants.SWEEP_STATUS_CONFIRMED
+		sweep.TxHash = &txHash
+		sweep.ConfirmedAt = &now
+		sweep.UpdatedAt = now
+		if err := tx.Save(sweep).Error; err != nil {
+			return err
+		}
+		return r.recordSweepFee(tx, sweep)
+	})
+}
+
+// recordSweepFee books the gas of a confirmed sweep as a gateway expense.
+func (r *SweepRepo) recordSweepFee(tx *gorm.DB, sweep *models.Sweep) error {
+	if sweep.Fee == nil {
+		return nil
+	}
+	fee, ok := new(big.Int).SetString(*sweep.Fee, 10)
+	if !ok || fee.Sign() <= 0 {
+		return nil
+	}
+	native, ok := r.assets.GetNative(sweep.ChainID)
+	if !ok {
+		return nil
+	}
+
+	_, err := r.ledgerRepo.RecordNetworkFee(tx, types.LedgerTransfer{
+		Key:         "sweep:fee:" + sweep.ID.String(),
+		ChainID:     sweep.ChainID,
+		Asset:       native.GetSymbol(),
+		Symbol:      native.GetSymbol(),
+		Decimals:    native.GetDecimals(),
+		Amount:      fee,
+		Description: "sweep of " + sweep.Address,
+	})
+	return err
+}
+
+func (r *SweepRepo) transition(ctx context.Context, id uuid.UUID, apply func(tx *gorm.DB, sweep *models.Sweep) error) error {
+	return r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var sweep models.Sweep
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&sweep, "id = ?", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSweepNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return apply(tx, &sweep)
+	})
+}
+
+func addSweepFee(sweep *models.Sweep, fee *big.Int) {
+	if fee == nil {
+		return
+	}
+	total := new(big.Int).Set(fee)
+	if sweep.Fee != nil {
+		if previous, ok := new(big.Int).SetString(*sweep.Fee, 10); ok {
+			total.Add(total, previous)
+		}
+	}
+	value := total.String()
+	sweep.Fee = &value
+}
+
+func failSweep(tx *gorm.DB, sweep *models.Sweep, reason string) error {
+	if sweep.Status == constants.SWEEP_STATUS_CONFIRMED || sweep.Status == constants.SWEEP_STATUS_FAILED {
+		return nil
+	}
+	if len(reason) > 255 {
+		reason = reason[:255]
+	}
+
+	sweep.Status = constants.SWEEP_STATUS_FAILED
+	sweep.FailureReason = &reason
+	sweep.UpdatedAt = time.Now()
+	if err := tx.Save(sweep).Error; err != nil {
+		return err
+	}
+
+	return tx.Model(&models.Transaction{}).
+		Where("sweep_id = ?", sweep.ID).
+		Update("sweep_id", nil).Error
+}
@@ -1,6 +1,8 @@
 package repositories
 
 import (
+	"context"
+	"core/constants"
 	"core/models"
 	"core/types"
 	"errors"
@@ -13,15 +15,16 @@ import (
 )
 
 type TransactionRepo struct {
-	db *gorm.DB
+	db         *gorm.DB
+	ledgerRepo *LedgerRepo
 }
 
 func (r *TransactionRepo) DB() *gorm.DB {
 	return r.db
 }
 
-func NewTransactionRepo(db *gorm.DB) *TransactionRepo {
-	return &TransactionRepo{db: db}
+func NewTransactionRepo(db *gorm.DB, ledgerRepo *LedgerRepo) *TransactionRepo {
+	return &TransactionRepo{db: db, ledgerRepo: ledgerRepo}
 }
 
 func (r *TransactionRepo) Create(params types.TransactionParam) error {
@@ -35,6 +38,11 @@ func (r *TransactionRepo) Create(params types.TransactionParam) error {
 		return errors.New("from/to required")
 	}
 
+	status := constants.TX_STATUS_PENDING
+	if params.Status != nil {
+		status = *params.Status
+	}
+
 	return r.DB().Transaction(func(tx *gorm.DB) error {
 		logIndexStr := ""
 		if params.LogIndex != nil {
@@ -62,17 +70,98 @@ func (r *TransactionRepo) Create(params types.TransactionParam) error {
 			ToAddress:   *params.To,
 			Amount:      *params.Amount,
 			UniqueHash:  uniqueHash,
+			Status:      status,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
 		}
 
-		if err := tx.Clauses(clause.OnConflict{
+		result := tx.Clauses(clause.OnConflict{
 			Columns:   []clause.Column{{Name: "unique_hash"}},
 			DoNothing: true,
-		}).Create(txModel).Error; err != nil {
-			return err
+		}).Create(txModel)
+		if result.Error != nil {
+			return result.Error
+		}
+
+		// Transfers reported as already final are credited right away.
+		if result.RowsAffected > 0 && status == constants.TX_STATUS_CONFIRMED {
+			if _, err := r.ledgerRepo.CreditDeposit(tx, txModel); err != nil {
+				return err
+			}
 		}
 
 		return nil
 	})
 }
+
+// Confirm marks the transfer confirmed and credits the receiving merchant.
+// Confirming twice is harmless, the ledger entry is keyed by the transfer.
+func (r *TransactionRepo) Confirm(ctx context.Context, id uuid.UUID) error {
+	return r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var transaction models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&transaction, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if transaction.Status == constants.TX_STATUS_FAILED || transaction.Status == constants.TX_STATUS_ORPHANED {
+			return nil
+		}
+
+		if transaction.Status != constants.TX_STATUS_CONFIRMED {
+			transaction.Status = constants.TX_STATUS_CONFIRMED
+			if err := tx.Model(&transaction).Update("status", transaction.Status).Error; err != nil {
+				return err
+			}
+		}
+
+		_, err := r.ledgerRepo.CreditDeposit(tx, &transaction)
+		return err
+	})
+}
+
+// Pending returns the pending transfers of a chain mined at or below
+// upToBlock, the oldest first.
+func (r *TransactionRepo) Pending(ctx context.Context, chainID constants.ChainID, upToBlock uint64, limit int) ([]models.Transaction, error) {
+	var transactions []models.Transaction
+	err := r.DB().WithContext(ctx).
+		Where("chain_id = ? AND status = ?", chainID, constants.TX_STATUS_PENDING).
+		Where("CAST(block_number AS numeric) <= ?", upToBlock).
+		Order("CAST(block_number AS numeric), created_at").
+		Limit(limit).
+		Find(&transactions).Error
+	return transactions, err
+}
+
+// Orphan marks the transfer orphaned after a reorg dropped its block and
+// reverses the deposit credited for it.
+func (r *TransactionRepo) Orphan(ctx context.Context, id uuid.UUID) error {
+	return r.drop(ctx, id, constants.TX_STATUS_ORPHANED, "reorg at block %s")
+}
+
+// Fail marks the transfer failed, its transaction was mined but reverted.
+func (r *TransactionRepo) Fail(ctx context.Context, id uuid.UUID) error {
+	return r.drop(ctx, id, constants.TX_STATUS_FAILED, "reverted in block %s")
+}
+
+func (r *TransactionRepo) drop(ctx context.Context, id uuid.UUID, status, description string) error {
+	return r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var transaction models.Transaction
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			First(&transaction, "id = ?", id).Error; err != nil {
+			return err
+		}
+		if transaction.Status == constants.TX_STATUS_FAILED || transaction.Status == constants.TX_STATUS_ORPHANED {
+			return nil
+		}
+
+		if err := tx.Model(&transaction).Update("status", status).Error; err != nil {
+			return err
+		}
+		if err := unmatchTransfer(tx, &transaction); err != nil {
+			return err
+		}
+		_, err := r.ledgerRepo.Reverse(tx, "deposit:"+transaction.ID.String(),
+			fmt.Sprintf(description, transaction.BlockNumber))
+		return err
+	})
+}
@@ -0,0 +1,327 @@
+package repositories
+
+import (
+	"context"
+	"core/constants"
+	"core/helpers"
+	"core/models"
+	"core/types"
+	"errors"
+	"os"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const (
+	RecoveryCodeCount    = 10
+	MaxFailedTwoFactor   = 5
+	TwoFactorLockoutTime = 15 * time.Minute
+)
+
+type TwoFactorRepo struct {
+	merchantRepo *MerchantRepo
+}
+
+func (r *TwoFactorRepo) DB() *gorm.DB {
+	return r.merchantRepo.DB()
+}
+
+func NewTwoFactorRepo(merchantRepo *MerchantRepo) *TwoFactorRepo {
+	return &TwoFactorRepo{merchantRepo: merchantRepo}
+}
+
+// Enroll generates a new pending TOTP secret. It only becomes active after Confirm.
+func (r *TwoFactorRepo) Enroll(params types.TwoFactorParams) (*types.TwoFactorEnrollment, error) {
+	if err := params.ValidateMerchant(); err != nil {
+		return nil, err
+	}
+
+	var enrollment *types.TwoFactorEnrollment
+
+	err := r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		merchant, err := lockMerchant(tx, *params.MerchantID)
+		if err != nil {
+			return err
+		}
+		if merchant.TOTPEnabled {
+			return ErrTwoFactorEnabled
+		}
+
+		secret, err := helpers.GenerateTOTPSecret()
+		if err != nil {
+			return err
+		}
+
+		encryptedSecret, err := helpers.EncryptSecret(secret)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Model(merchant).Updates(map[string]interface{}{
+			"totp_secret":    encryptedSecret,
+			"totp_last_step": 0,
+		}).Error; err != nil {
+			return err
+		}
+
+		enrollment = &types.TwoFactorEnrollment{
+			Secret:          secret,
+			ProvisioningURI: helpers.TOTPProvisioningURI(totpIssuer(), merchant.Email, secret),
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return enrollment, nil
+}
+
+// Confirm activates the pending secret and returns fresh recovery codes.
+func (r *TwoFactorRepo) Confirm(params types.TwoFactorParams) (*types.RecoveryCodes, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	var codes *types.RecoveryCodes
+
+	err := r.transaction(params.Context, *params.MerchantID, func(tx *gorm.DB, merchant *models.Merchant) error {
+		if merchant.TOTPEnabled {
+			return ErrTwoFactorEnabled
+		}
+		if merchant.TOTPSecret == "" {
+			return ErrTwoFactorNotEnabled
+		}
+
+		if err := verifyTOTP(tx, merchant, *params.Code); err != nil {
+			return err
+		}
+
+		if err := tx.Model(merchant).Updates(map[string]interface{}{
+			"totp_enabled":      true,
+			"totp_confirmed_at": time.Now(),
+		}).Error; err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, merchant.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+func (r *TwoFactorRepo) Disable(params types.TwoFactorParams) error {
+	if err := params.Validate(); err != nil {
+		return err
+	}
+
+	return r.transaction(params.Context, *params.MerchantID, func(tx *gorm.DB, merchant *models.Merchant) error {
+		if !merchant.TOTPEnabled {
+			return ErrTwoFactorNotEnabled
+		}
+
+		if err := verifySecondFactor(tx, merchant, *params.Code); err != nil {
+			return err
+		}
+
+		if err := tx.Model(merchant).Updates(map[string]interface{}{
+			"totp_secret":       "",
+			"totp_enabled":      false,
+			"totp_confirmed_at": nil,
+			"totp_last_step":    0,
+		}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("merchant_id = ?", merchant.ID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+func (r *TwoFactorRepo) RegenerateRecoveryCodes(params types.TwoFactorParams) (*types.RecoveryCodes, error) {
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	var codes *types.RecoveryCodes
+
+	err := r.transaction(params.Context, *params.MerchantID, func(tx *gorm.DB, merchant *models.Merchant) error {
+		if !merchant.TOTPEnabled {
+			return ErrTwoFactorNotEnabled
+		}
+
+		// Only a TOTP code may mint new recovery codes.
+		if err := verifyTOTP(tx, merchant, *params.Code); err != nil {
+			return err
+		}
+
+		var err error
+		codes, err = replaceRecoveryCodes(tx, merchant.ID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// Verify enforces the second factor for sensitive operations. Merchants
+// without 2FA pass; enrolled merchants must present a TOTP or recovery code.
+func (r *TwoFactorRepo) Verify(params types.TwoFactorParams) error {
+	if err := params.ValidateMerchant(); err != nil {
+		return err
+	}
+
+	return r.transaction(params.Context, *params.MerchantID, func(tx *gorm.DB, merchant *models.Merchant) error {
+		if !merchant.TOTPEnabled {
+			return nil
+		}
+		if params.Code == nil || *params.Code == "" {
+			return ErrTwoFactorRequired
+		}
+
+		return verifySecondFactor(tx, merchant, *params.Code)
+	})
+}
+
+// transaction runs fn on the locked merchant. An invalid code rolls fn back
+// but is counted; MaxFailedTwoFactor of them in a row lock the second factor
+// for TwoFactorLockoutTime, so a stolen session cannot brute-force it.
+func (r *TwoFactorRepo) transaction(ctx context.Context, merchantID uuid.UUID, fn func(tx *gorm.DB, merchant *models.Merchant) error) error {
+	err := r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		merchant, err := lockMerchant(tx, merchantID)
+		if err != nil {
+			return err
+		}
+		if twoFactorLocked(merchant, time.Now()) {
+			return ErrTwoFactorLocked
+		}
+
+		if err := fn(tx, merchant); err != nil {
+			return err
+		}
+		if merchant.FailedTOTPAttempts == 0 {
+			return nil
+		}
+		return tx.Model(merchant).Update("failed_totp_attempts", 0).Error
+	})
+	if !errors.Is(err, ErrInvalidTwoFactor) {
+		return err
+	}
+
+	if countErr := r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		merchant, err := lockMerchant(tx, merchantID)
+		if err != nil {
+			return err
+		}
+		return registerFailedTwoFactor(tx, merchant, time.Now())
+	}); countErr != nil {
+		return countErr
+	}
+	return err
+}
+
+func twoFactorLocked(merchant *models.Merchant, now time.Time) bool {
+	return merchant.TOTPLockedUntil != nil && merchant.TOTPLockedUntil.After(now)
+}
+
+func registerFailedTwoFactor(tx *gorm.DB, merchant *models.Merchant, now time.Time) error {
+	attempts := merchant.FailedTOTPAttempts + 1
+	updates := map[string]interface{}{"failed_totp_attempts": attempts}
+	if attempts >= MaxFailedTwoFactor {
+		updates["failed_totp_attempts"] = 0
+		updates["totp_locked_until"] = now.Add(TwoFactorLockoutTime)
+	}
+	return tx.Model(merchant).Updates(updates).Error
+}
+
+func totpIssuer() string {
+	if issuer := os.Getenv("TOTP_ISSUER"); issuer != "" {
+		return issuer
+	}
+	return constants.APPLICATION_NAME
+}
+
+func lockMerchant(tx *gorm.DB, merchantID uuid.UUID) (*models.Merchant, error) {
+	var merchant models.Merchant
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		First(&merchant, "id = ?", merchantID).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrMerchantNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &merchant, nil
+}
+
+// verifyTOTP accepts a code only once: steps at or before the last used step are replays.
+func verifyTOTP(tx *gorm.DB, merchant *models.Merchant, code string) error {
+	secret, err := helpers.DecryptSecret(merchant.TOTPSecret)
+	if err != nil {
+		return err
+	}
+
+	step, ok := helpers.ValidateTOTP(secret, code, time.Now())
+	if !ok || step <= merchant.TOTPLastStep {
+		return ErrInvalidTwoFactor
+	}
+
+	merchant.TOTPLastStep = step
+	return tx.Model(merchant).Update("totp_last_step", step).Error
+}
+
+// verifySecondFactor accepts either a TOTP code or an unused recovery code.
+func verifySecondFactor(tx *gorm.DB, merchant *models.Merchant, code string) error {
+	err := verifyTOTP(tx, merchant, code)
+	if !errors.Is(err, ErrInvalidTwoFactor) {
+		return err
+	}
+
+	result := tx.Model(&models.RecoveryCode{}).
+		Where("merchant_id = ? AND code_hash = ? AND used_at IS NULL", merchant.ID, helpers.HashSHA256(helpers.NormalizeRecoveryCode(code))).
+		Update("used_at", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrInvalidTwoFactor
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, merchantID uuid.UUID) (*types.RecoveryCodes, error) {
+	if err := tx.Where("merchant_id = ?", merchantID).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, err
+	}
+
+	codes := make([]string, 0, RecoveryCodeCount)
+	rows := make([]models.RecoveryCode, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code, err := helpers.GenerateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		rows = append(rows, models.RecoveryCode{
+			ID:         uuid.New(),
+			MerchantID: merchantID,
+			CodeHash:   helpers.HashSHA256(code),
+		})
+	}
+
+	if err := tx.Create(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.RecoveryCodes{Codes: codes}, nil
+}
@@ -2,15 +2,20 @@ package repositories
 
 import (
 	"context"
+	"core/blockchain"
+	"core/constants"
 	"core/models"
 	"core/types"
 	"errors"
 	"fmt"
+	"log"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type WalletRepo struct {
@@ -29,41 +34,6 @@ func NewWalletRepo(domainRepo *DomainRepo) *WalletRepo {
 	return &WalletRepo{domainRepo: domainRepo}
 }
 
-func (r *WalletRepo) GetNextHDIndex(ctx context.Context, merchantID, domainID uuid.UUID) (uint32, error) {
-	var maxIndex uint32
-	err := r.DB().WithContext(ctx).
-		Model(&models.Wallet{}).
-		Where("merchant_id = ? AND domain_id = ?", merchantID, domainID).
-		Select("COALESCE(MAX(hd_address_id), 0)").
-		Scan(&maxIndex).Error
-	if err != nil {
-		return 0, err
-	}
-	return maxIndex + 1, nil
-}
-
-func (r *WalletRepo) CreateEx(params types.WalletParams) (*models.Wallet, error) {
-
-	domainId, err := uuid.Parse(*params.DomainId)
-	if err != nil {
-		return nil, errors.New("invalid domain id")
-	}
-
-	merchantId, err := uuid.Parse(*params.MerchantId)
-	if err != nil {
-		return nil, errors.New("invalid merchant id")
-	}
-
-	nextIndex, err := r.GetNextHDIndex(params.Context, merchantId, domainId)
-	if err != nil {
-		return nil, err
-	}
-
-	fmt.Println("CODER", nextIndex)
-
-	return nil, nil
-}
-
 func (r *WalletRepo) Create(params types.WalletParams) (*models.Wallet, error) {
 	tx := r.DB().WithContext(params.Context).Begin()
 	if tx.Error != nil {
@@ -79,13 +49,12 @@ func (r *WalletRepo) Create(params types.WalletParams) (*models.Wallet, error) {
 	merchantUUID, err := uuid.Parse(*params.MerchantId)
 	if err != nil {
 		tx.Rollback()
-		return nil, errors.New("invalid merchant id")
+		return nil, types.NewValidationError("merchant_id", "invalid merchant id")
 	}
 
-	domainUUID, err := uuid.Parse(*params.DomainId)
-	if err != nil {
+	if _, err := uuid.Parse(*params.DomainId); err != nil {
 		tx.Rollback()
-		return nil, errors.New("invalid domain id")
+		return nil, types.NewValidationError("domain_id", "invalid domain id")
 	}
 
 	domainParams := types.DomainParams{
@@ -95,50 +64,302 @@ func (r *WalletRepo) Create(params types.WalletParams) (*models.Wallet, error) {
 
 	domain, err := r.domainRepo.FindByID(domainParams)
 	if err != nil {
-		fmt.Println("Domain bulunamadı:", err)
+		tx.Rollback()
 		return nil, err
 	}
 
-	hdAccountId, err := r.GetNextHDIndex(params.Context, merchantUUID, domainUUID)
+	if domain.MerchantID != merchantUUID {
+		tx.Rollback()
+		return nil, ErrDomainNotFound
+	}
+
+	if !domain.IsEnabled {
+		tx.Rollback()
+		return nil, ErrDomainDisabled
+	}
+
+	wallet, err := r.createWallet(params.Context, tx, domain, params.Chains)
 	if err != nil {
 		tx.Rollback()
 		return nil, err
 	}
 
-	walletsMap, errorsMap := r.domainRepo.MerchantRepo().blockchains.CreateHDWallets(params.Context, int(domain.HDAccountID), int(hdAccountId))
-	if len(errorsMap) > 0 {
-		tx.Rollback()
-		errStrings := make([]string, 0, len(errorsMap))
-		for chainName, err := range errorsMap {
-			errStrings = append(errStrings, chainName+": "+err.Error())
-		}
-		return nil, fmt.Errorf("failed to create wallets: %s", strings.Join(errStrings, "; "))
+	if err := tx.Commit().Error; err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// createWallet allocates the next HD index of the domain and derives the
+// wallet addresses inside the caller's transaction.
+func (r *WalletRepo) createWallet(ctx context.Context, tx *gorm.DB, domain *models.Domain, chains []string) (*models.Wallet, error) {
+	hdAddressId, err := allocateHDIndex(tx, hdScopeWallet(domain.ID.String()),
+		"SELECT COALESCE(MAX(hd_address_id), 0) FROM wallets WHERE domain_id = ?", domain.ID)
+	if err != nil {
+		return nil, err
 	}
 
 	wallet := &models.Wallet{
-		ID:               uuid.New(),
-		HDAddressId:      hdAccountId,
-		HDAccountID:      domain.HDAccountID,
-		MerchantID:       merchantUUID,
-		DomainID:         domainUUID,
-		BitcoinAddress:   walletsMap["bitcoin"].Address,
-		EthereumAddress:  walletsMap["ethereum"].Address,
-		AvalancheAddress: walletsMap["avalanche"].Address,
-		TronAddress:      walletsMap["tron"].Address,
-		SolanaAddress:    walletsMap["solana"].Address,
-		ChilizAddress:    walletsMap["chiliz"].Address,
-		CreatedAt:        time.Now(),
-		UpdatedAt:        time.Now(),
+		ID:          uuid.New(),
+		HDAddressId: hdAddressId,
+		HDAccountID: domain.HDAccountID,
+		MerchantID:  domain.MerchantID,
+		DomainID:    domain.ID,
+		CreatedAt:   time.Now(),
+		UpdatedAt:   time.Now(),
 	}
 
 	if err := tx.Create(wallet).Error; err != nil {
-		tx.Rollback()
 		return nil, err
 	}
 
-	if err := tx.Commit().Error; err != nil {
+	if err := r.deriveAddresses(ctx, tx, wallet, chains); err != nil {
 		return nil, err
 	}
 
 	return wallet, nil
 }
+
+// DeriveAddresses adds the requested chains to an existing wallet. Chains
+// already derived are left untouched.
+func (r *WalletRepo) DeriveAddresses(params types.WalletParams) (*models.Wallet, error) {
+	if err := params.ValidateDerive(); err != nil {
+		return nil, err
+	}
+
+	var wallet models.Wallet
+	err := r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		query := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND merchant_id = ?", *params.WalletId, *params.MerchantId)
+		if params.DomainId != nil && *params.DomainId != "" {
+			query = query.Where("domain_id = ?", *params.DomainId)
+		}
+
+		err := query.First(&wallet).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWalletNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var domain models.Domain
+		if err := tx.Select("id", "is_enabled").First(&domain, "id = ?", wallet.DomainID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrDomainNotFound
+			}
+			return err
+		}
+		if !domain.IsEnabled {
+			return ErrDomainDisabled
+		}
+
+		if err := tx.Where("wallet_id = ?", wallet.ID).Find(&wallet.Addresses).Error; err != nil {
+			return err
+		}
+
+		return r.deriveAddresses(params.Context, tx, &wallet, params.Chains)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &wallet, nil
+}
+
+// deriveAddresses derives and stores the wallet address of each chain not yet
+// present on wallet.Addresses. With no explicit chains every registered chain
+// is tried and failing ones are skipped, as long as one succeeds.
+func (r *WalletRepo) deriveAddresses(ctx context.Context, tx *gorm.DB, wallet *models.Wallet, chains []string) error {
+	factory := r.domainRepo.MerchantRepo().Blockchains()
+
+	strict := len(chains) > 0
+	if !strict {
+		chains = factory.ListChains()
+		sort.Strings(chains)
+	}
+
+	selected := make([]blockchain.Chain, 0, len(chains))
+	for _, name := range chains {
+		chain, err := factory.GetChain(name)
+		if err != nil {
+			return types.NewValidationError("chains", "unknown chain: "+name)
+		}
+		if _, ok := wallet.Address(chain.ChainID()); ok {
+			continue
+		}
+		selected = append(selected, chain)
+	}
+
+	var failures []string
+	for _, chain := range selected {
+		details, err := chain.CreateHDWallet(ctx, int(wallet.HDAccountID), int(wallet.HDAddressId))
+		if err != nil {
+			failures = append(failures, chain.Name()+": "+err.Error())
+			continue
+		}
+
+		address := models.WalletAddress{
+			ID:             uuid.New(),
+			WalletID:       wallet.ID,
+			MerchantID:     wallet.MerchantID,
+			DomainID:       wallet.DomainID,
+			ChainID:        chain.ChainID(),
+			Chain:          chain.Name(),
+			Address:        details.Address,
+			DerivationPath: details.DerivationPath,
+		}
+		if err := tx.Create(&address).Error; err != nil {
+			return err
+		}
+		wallet.Addresses = append(wallet.Addresses, address)
+	}
+
+	if len(failures) == 0 {
+		return nil
+	}
+	if strict || len(wallet.Addresses) == 0 {
+		return fmt.Errorf("%w: failed to derive addresses: %s", blockchain.ErrChainUnavailable, strings.Join(failures, "; "))
+	}
+
+	log.Printf("[wallet] %s: skipped chains: %s\n", wallet.ID, strings.Join(failures, "; "))
+	return nil
+}
+
+func (r *WalletRepo) List(params types.WalletParams) ([]models.Wallet, *string, error) {
+	if err := params.ValidateList(); err != nil {
+		return nil, nil, err
+	}
+
+	query, err := pageQuery(
+		r.DB().WithContext(params.Context).
+			Where("wallets.merchant_id = ? AND wallets.domain_id = ?", *params.MerchantId, *params.DomainId),
+		"wallets",
+		params.Pagination,
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var wallets []models.Wallet
+	if err := query.Preload("Addresses").Find(&wallets).Error; err != nil {
+		return nil, nil, err
+	}
+
+	wallets, cursor := trimPage(wallets, params.Pagination, func(w models.Wallet) (time.Time, uuid.UUID) {
+		return w.CreatedAt, w.ID
+	})
+	return wallets, cursor, nil
+}
+
+// FindByAddress resolves an address of any chain to the wallet owning it.
+func (r *WalletRepo) FindByAddress(params types.WalletParams) (*models.Wallet, error) {
+	if err := params.ValidateAddressLookup(); err != nil {
+		return nil, err
+	}
+
+	address := strings.TrimSpace(*params.Address)
+	matches := r.DB().
+		Where("address = ?", address).
+		Or("chain_id IN ? AND LOWER(address) = LOWER(?)", constants.EVMChains, address)
+
+	query := r.DB().WithContext(params.Context).
+		Model(&models.WalletAddress{}).
+		Where("merchant_id = ?", *params.MerchantId).
+		Where(matches)
+	if params.DomainId != nil && *params.DomainId != "" {
+		query = query.Where("domain_id = ?", *params.DomainId)
+	}
+
+	var walletAddress models.WalletAddress
+	err := query.First(&walletAddress).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var wallet models.Wallet
+	if err := r.DB().WithContext(params.Context).
+		Preload("Addresses").
+		First(&wallet, "id = ?", walletAddress.WalletID).Error; err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// History returns the transfers received by the wallet, newest first.
+func (r *WalletRepo) History(params types.WalletParams) ([]models.Transaction, *string, error) {
+	if err := params.ValidateHistory(); err != nil {
+		return nil, nil, err
+	}
+
+	walletQuery := r.DB().WithContext(params.Context).
+		Where("id = ? AND merchant_id = ?", *params.WalletId, *params.MerchantId)
+	if params.DomainId != nil && *params.DomainId != "" {
+		walletQuery = walletQuery.Where("domain_id = ?", *params.DomainId)
+	}
+
+	var wallet models.Wallet
+	err := walletQuery.Preload("Addresses").First(&wallet).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil, ErrWalletNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	destinations := r.DB()
+	matched := 0
+	for _, address := range wallet.Addresses {
+		if params.ChainID != nil && *params.ChainID != address.ChainID {
+			continue
+		}
+		matched++
+		if address.ChainID.IsEVM() {
+			destinations = destinations.Or("chain_id = ? AND LOWER(to_address) = LOWER(?)", address.ChainID, address.Address)
+		} else {
+			destinations = destinations.Or("chain_id = ? AND to_address = ?", address.ChainID, address.Address)
+		}
+	}
+
+	if matched == 0 {
+		return []models.Transaction{}, nil, nil
+	}
+
+	query := r.DB().WithContext(params.Context).
+		Model(&models.Transaction{}).
+		Where(destinations)
+	if params.ChainID != nil {
+		query = query.Where("transactions.chain_id = ?", *params.ChainID)
+	}
+	if params.Asset != nil && *params.Asset != "" {
+		query = query.Where("(transactions.symbol = ? OR LOWER(transactions.token) = LOWER(?))", *params.Asset, *params.Asset)
+	}
+	if params.Status != nil {
+		query = query.Where("transactions.status = ?", *params.Status)
+	}
+	if params.From != nil {
+		query = query.Where("transactions.created_at >= ?", *params.From)
+	}
+	if params.To != nil {
+		query = query.Where("transactions.created_at < ?", *params.To)
+	}
+
+	query, err = pageQuery(query, "transactions", params.Pagination)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var transactions []models.Transaction
+	if err := query.Find(&transactions).Error; err != nil {
+		return nil, nil, err
+	}
+
+	transactions, cursor := trimPage(transactions, params.Pagination, func(t models.Transaction) (time.Time, uuid.UUID) {
+		return t.CreatedAt, t.ID
+	})
+	return transactions, cursor, nil
+}
@@ -0,0 +1,553 @@
+package repositories
+
+import (
+	"context"
+	"core/asset"
+	"core/constants"
+	"core/helpers"
+	"core/models"
+	"core/types"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type WithdrawalRepo struct {
+	merchantRepo *MerchantRepo
+	ledgerRepo   *LedgerRepo
+	assets       *asset.Registry
+}
+
+func (r *WithdrawalRepo) DB() *gorm.DB {
+	return r.merchantRepo.DB()
+}
+
+func NewWithdrawalRepo(merchantRepo *MerchantRepo, ledgerRepo *LedgerRepo, assets *asset.Registry) *WithdrawalRepo {
+	return &WithdrawalRepo{merchantRepo: merchantRepo, ledgerRepo: ledgerRepo, assets: assets}
+}
+
+// Create accepts a withdrawal and holds its amount on the ledger. Replaying
+// an idempotency key returns the withdrawal created the first time.
+func (r *WithdrawalRepo) Create(params types.WithdrawalParams) (*models.Withdrawal, error) {
+	if err := params.ValidateCreate(); err != nil {
+		return nil, err
+	}
+
+	chain, err := r.merchantRepo.Blockchains().GetChain(*params.Chain)
+	if err != nil {
+		return nil, types.NewValidationError("chain", "unknown chain")
+	}
+	if !chain.CanSend() {
+		return nil, types.NewValidationError("chain", "withdrawals are not supported on "+chain.Name())
+	}
+
+	destination := strings.TrimSpace(*params.Destination)
+	if !chain.ValidateAddress(destination) {
+		return nil, types.NewValidationError("destination", "invalid "+chain.Name()+" address")
+	}
+
+	assetInfo, ok := resolveAsset(r.assets, chain.ChainID(), *params.Asset)
+	if !ok {
+		return nil, types.NewValidationError("asset", "unknown asset on "+chain.Name())
+	}
+
+	amount, err := helpers.ParseUnits(*params.Amount, assetInfo.GetDecimals())
+	if err != nil || amount.Sign() <= 0 {
+		return nil, types.NewValidationError("amount",
+			fmt.Sprintf("amount must be a positive decimal with at most %d decimals", assetInfo.GetDecimals()))
+	}
+
+	merchantUUID, _ := uuid.Parse(*params.MerchantID)
+
+	var withdrawal *models.Withdrawal
+	// replay looks up the withdrawal of the idempotency key, reporting
+	// whether there is one.
+	replay := func(tx *gorm.DB) (bool, error) {
+		var existing models.Withdrawal
+		err := tx.Where("merchant_id = ? AND idempotency_key = ?", merchantUUID, *params.IdempotencyKey).
+			First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if existing.ChainID != chain.ChainID() || existing.Asset != assetInfo.GetSymbol() ||
+			existing.Amount != amount.String() || existing.Destination != destination {
+			return true, ErrIdempotencyConflict
+		}
+		withdrawal = &existing
+		return true, nil
+	}
+
+	err = r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		if found, err := replay(tx); found || err != nil {
+			return err
+		}
+
+		if err := checkAllowlist(tx, merchantUUID, chain.ChainID(), destination); err != nil {
+			return err
+		}
+
+		var domainID *uuid.UUID
+		if params.DomainID != nil && *params.DomainID != "" {
+			var domain models.Domain
+			err := tx.Where("id = ? AND merchant_id = ?", *params.DomainID, merchantUUID).First(&domain).Error
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return ErrDomainNotFound
+			}
+			if err != nil {
+				return err
+			}
+			if !domain.IsEnabled {
+				return ErrDomainDisabled
+			}
+			domainID = &domain.ID
+		}
+
+		now := time.Now()
+		withdrawal = &models.Withdrawal{
+			ID:             uuid.New(),
+			MerchantID:     merchantUUID,
+			DomainID:       domainID,
+			IdempotencyKey: *params.IdempotencyKey,
+			ChainID:        chain.ChainID(),
+			Chain:          chain.Name(),
+			Asset:          assetInfo.GetSymbol(),
+			Decimals:       assetInfo.GetDecimals(),
+			Amount:         amount.String(),
+			Destination:    destination,
+			Status:         constants.WITHDRAWAL_STATUS_REQUESTED,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+		}
+		if !assetInfo.IsNative() {
+			token := assetInfo.GetIdentifier()
+			withdrawal.Token = &token
+		}
+
+		// A concurrent request with the same key may insert first. The
+		// insert then waits for it and does nothing, and its withdrawal is
+		// replayed.
+		result := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "merchant_id"}, {Name: "idempotency_key"}},
+			DoNothing: true,
+		}).Create(withdrawal)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			withdrawal = nil
+			if found, err := replay(tx); found || err != nil {
+				return err
+			}
+			return ErrIdempotencyConflict
+		}
+		if err := recordWithdrawalEvent(tx, withdrawal, "", params.Actor, params.ActorID, ""); err != nil {
+			return err
+		}
+
+		if _, err := r.ledgerRepo.Hold(tx, withdrawalTransfer(withdrawal, "hold")); err != nil {
+			return err
+		}
+
+		autoApprove, err := belowThreshold(tx, withdrawal, amount)
+		if err != nil {
+			return err
+		}
+		if autoApprove {
+			return transitionWithdrawal(tx, withdrawal, constants.WITHDRAWAL_STATUS_APPROVED,
+				constants.ACTOR_SYSTEM, nil, "auto-approved below threshold")
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawal, nil
+}
+
+func (r *WithdrawalRepo) Fetch(params types.WithdrawalParams) (*models.Withdrawal, error) {
+	if err := params.ValidateLookup(); err != nil {
+		return nil, err
+	}
+
+	var withdrawal models.Withdrawal
+	err := r.ownedQuery(r.DB().WithContext(params.Context), params).First(&withdrawal).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrWithdrawalNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &withdrawal, nil
+}
+
+func (r *WithdrawalRepo) List(params types.WithdrawalParams) ([]models.Withdrawal, *string, error) {
+	if err := params.ValidateList(); err != nil {
+		return nil, nil, err
+	}
+
+	query := r.DB().WithContext(params.Context).
+		Where("withdrawals.merchant_id = ?", *params.MerchantID)
+	if params.DomainID != nil && *params.DomainID != "" {
+		query = query.Where("withdrawals.domain_id = ?", *params.DomainID)
+	}
+	if params.Status != nil {
+		query = query.Where("withdrawals.status = ?", *params.Status)
+	}
+
+	query, err := pageQuery(query, "withdrawals", params.Pagination)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var withdrawals []models.Withdrawal
+	if err := query.Find(&withdrawals).Error; err != nil {
+		return nil, nil, err
+	}
+
+	withdrawals, cursor := trimPage(withdrawals, params.Pagination, func(w models.Withdrawal) (time.Time, uuid.UUID) {
+		return w.CreatedAt, w.ID
+	})
+	return withdrawals, cursor, nil
+}
+
+func (r *WithdrawalRepo) Approve(params types.WithdrawalParams) (*models.Withdrawal, error) {
+	if err := params.ValidateLookup(); err != nil {
+		return nil, err
+	}
+
+	return r.decide(params, func(tx *gorm.DB, withdrawal *models.Withdrawal) error {
+		// The allowlist may have been turned on, or the address removed,
+		// since the withdrawal was requested.
+		if err := checkAllowlist(tx, withdrawal.MerchantID, withdrawal.ChainID, withdrawal.Destination); err != nil {
+			return err
+		}
+		return transitionWithdrawal(tx, withdrawal, constants.WITHDRAWAL_STATUS_APPROVED,
+			params.Actor, params.ActorID, "")
+	})
+}
+
+// Reject refuses a requested withdrawal and releases its held funds.
+func (r *WithdrawalRepo) Reject(params types.WithdrawalParams) (*models.Withdrawal, error) {
+	if err := params.ValidateReject(); err != nil {
+		return nil, err
+	}
+
+	note := ""
+	if params.Reason != nil {
+		note = *params.Reason
+	}
+
+	return r.decide(params, func(tx *gorm.DB, withdrawal *models.Withdrawal) error {
+		if err := transitionWithdrawal(tx, withdrawal, constants.WITHDRAWAL_STATUS_REJECTED,
+			params.Actor, params.ActorID, note); err != nil {
+			return err
+		}
+		_, err := r.ledgerRepo.Reverse(tx, withdrawalKey(withdrawal, "hold"), "withdrawal rejected")
+		return err
+	})
+}
+
+// SpeedUp asks for the transaction of a broadcast withdrawal to be
+// replaced by one paying higher fees.
+func (r *WithdrawalRepo) SpeedUp(params types.WithdrawalParams) (*models.Withdrawal, error) {
+	return r.requestReplacement(params, constants.OUTGOING_ACTION_SPEED_UP)
+}
+
+// Cancel asks for the transaction of a broadcast withdrawal to be replaced
+// by a transfer of nothing to the hot wallet itself. The withdrawal fails
+// and its funds are released once the cancellation is mined.
+func (r *WithdrawalRepo) Cancel(params types.WithdrawalParams) (*models.Withdrawal, error) {
+	return r.requestReplacement(params, constants.OUTGOING_ACTION_CANCEL)
+}
+
+func (r *WithdrawalRepo) requestReplacement(params types.WithdrawalParams, action string) (*models.Withdrawal, error) {
+	if err := params.ValidateLookup(); err != nil {
+		return nil, err
+	}
+
+	return r.decide(params, func(tx *gorm.DB, withdrawal *models.Withdrawal) error {
+		if withdrawal.Status != constants.WITHDRAWAL_STATUS_BROADCAST {
+			return ErrWithdrawalState
+		}
+		if err := requestOutgoingAction(tx, constants.OUTGOING_KIND_WITHDRAWAL, withdrawal.ID, action); err != nil {
+			return err
+		}
+		return recordWithdrawalEvent(tx, withdrawal, withdrawal.Status, params.Actor, params.ActorID, action+" requested")
+	})
+}
+
+func (r *WithdrawalRepo) decide(params types.WithdrawalParams, apply func(tx *gorm.DB, withdrawal *models.Withdrawal) error) (*models.Withdrawal, error) {
+	var withdrawal models.Withdrawal
+	err := r.DB().WithContext(params.Context).Transaction(func(tx *gorm.DB) error {
+		err := r.ownedQuery(tx.Clauses(clause.Locking{Strength: "UPDATE"}), params).First(&withdrawal).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWithdrawalNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return apply(tx, &withdrawal)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &withdrawal, nil
+}
+
+func (r *WithdrawalRepo) ownedQuery(query *gorm.DB, params types.WithdrawalParams) *gorm.DB {
+	query = query.Where("id = ? AND merchant_id = ?", *params.WithdrawalID, *params.MerchantID)
+	if params.DomainID != nil && *params.DomainID != "" {
+		query = query.Where("domain_id = ?", *params.DomainID)
+	}
+	return query
+}
+
+// SetThreshold sets the amount up to which withdrawals of an asset are
+// approved without a manual decision. Zero disables auto-approval.
+func (r *WithdrawalRepo) SetThreshold(params types.WithdrawalParams) (*models.WithdrawalThreshold, error) {
+	if err := params.ValidateThreshold(); err != nil {
+		return nil, err
+	}
+
+	chain, err := r.merchantRepo.Blockchains().GetChain(*params.Chain)
+	if err != nil {
+		return nil, types.NewValidationError("chain", "unknown chain")
+	}
+
+	assetInfo, ok := resolveAsset(r.assets, chain.ChainID(), *params.Asset)
+	if !ok {
+		return nil, types.NewValidationError("asset", "unknown asset on "+chain.Name())
+	}
+
+	maxAmount, err := helpers.ParseUnits(*params.MaxAmount, assetInfo.GetDecimals())
+	if err != nil {
+		return nil, types.NewValidationError("max_amount",
+			fmt.Sprintf("max_amount must be a decimal with at most %d decimals", assetInfo.GetDecimals()))
+	}
+
+	now := time.Now()
+	threshold := &models.WithdrawalThreshold{
+		ID:         uuid.New(),
+		MerchantID: uuid.MustParse(*params.MerchantID),
+		ChainID:    chain.ChainID(),
+		Asset:      strings.ToLower(assetInfo.GetIdentifier()),
+		MaxAmount:  maxAmount.String(),
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+
+	err = r.DB().WithContext(params.Context).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "merchant_id"}, {Name: "chain_id"}, {Name: "asset"}},
+		DoUpdates: clause.AssignmentColumns([]string{"max_amount", "updated_at"}),
+	}).Create(threshold).Error
+	if err != nil {
+		return nil, err
+	}
+	return threshold, nil
+}
+
+// ClaimApproved moves up to limit approved withdrawals to signing and
+// returns them. A withdrawal left in signing by a crash or a send whose
+// outcome is unknown needs a manual check on chain before it is failed or
+// retried, it may have been sent.
+func (r *WithdrawalRepo) ClaimApproved(ctx context.Context, limit int) ([]models.Withdrawal, error) {
+	var withdrawals []models.Withdrawal
+
+	err := r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ?", constants.WITHDRAWAL_STATUS_APPROVED).
+			Order("created_at").
+			Limit(limit).
+			Find(&withdrawals).Error; err != nil {
+			return err
+		}
+
+		for i := range withdrawals {
+			if err := transitionWithdrawal(tx, &withdrawals[i], constants.WITHDRAWAL_STATUS_SIGNING,
+				constants.ACTOR_SYSTEM, nil, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return withdrawals, nil
+}
+
+// MarkBroadcast records that the transaction of attempt left for the
+// withdrawal, together with the attempt the tracker follows it by.
+func (r *WithdrawalRepo) MarkBroadcast(ctx context.Context, id uuid.UUID, attempt *models.OutgoingTx) error {
+	return r.systemTransition(ctx, id, func(tx *gorm.DB, withdrawal *models.Withdrawal) error {
+		now := time.Now()
+		withdrawal.TxHash = &attempt.TxHash
+		withdrawal.BroadcastAt = &now
+		if err := transitionWithdrawal(tx, withdrawal, constants.WITHDRAWAL_STATUS_BROADCAST,
+			constants.ACTOR_SYSTEM, nil, attempt.TxHash); err != nil {
+			return err
+		}
+		return tx.Create(attempt).Error
+	})
+}
+
+// FlagForReview notes on the audit log of a withdrawal that its outcome on
+// chain is unknown: in signing its transaction may have been broadcast,
+// broadcast it may have been paid by a transaction the tracker lost. It
+// keeps its status, neither retried nor failed, until it is checked on
+// chain.
+func (r *WithdrawalRepo) FlagForReview(ctx context.Context, id uuid.UUID, reason string) error {
+	return r.systemTransition(ctx, id, func(tx *gorm.DB, withdrawal *models.Withdrawal) error {
+		note := "needs review: " + reason
+		if len(note) > 255 {
+			note = note[:255]
+		}
+		return recordWithdrawalEvent(tx, withdrawal, withdrawal.Status, constants.ACTOR_SYSTEM, nil, note)
+	})
+}
+
+// MarkFailed fails the withdrawal and releases its held funds.
+func (r *WithdrawalRepo) MarkFailed(ctx context.Context, id uuid.UUID, reason string) error {
+	return r.systemTransition(ctx, id, func(tx *gorm.DB, withdrawal *models.Withdrawal) error {
+		return r.fail(tx, withdrawal, reason)
+	})
+}
+
+func (r *WithdrawalRepo) fail(tx *gorm.DB, withdrawal *models.Withdrawal, reason string) error {
+	if len(reason) > 255 {
+		reason = reason[:255]
+	}
+	withdrawal.FailureReason = &reason
+	if err := transitionWithdrawal(tx, withdrawal, constants.WITHDRAWAL_STATUS_FAILED,
+		constants.ACTOR_SYSTEM, nil, reason); err != nil {
+		return err
+	}
+	_, err := r.ledgerRepo.Reverse(tx, withdrawalKey(withdrawal, "hold"), "withdrawal failed")
+	return err
+}
+
+// Confirm settles a broadcast withdrawal whose transaction txHash, the
+// first attempt or a replacement of it, was mined deep enough.
+func (r *WithdrawalRepo) Confirm(ctx context.Context, id uuid.UUID, txHash string) error {
+	return r.systemTransition(ctx, id, func(tx *gorm.DB, withdrawal *models.Withdrawal) error {
+		if withdrawal.Status != constants.WITHDRAWAL_STATUS_BROADCAST {
+			return nil
+		}
+
+		note := ""
+		if withdrawal.TxHash == nil || !strings.EqualFold(*withdrawal.TxHash, txHash) {
+			withdrawal.TxHash = &txHash
+			note = "mined as " + txHash
+		}
+		now := time.Now()
+		withdrawal.ConfirmedAt = &now
+		if err := transitionWithdrawal(tx, withdrawal, constants.WITHDRAWAL_STATUS_CONFIRMED,
+			constants.ACTOR_SYSTEM, nil, note); err != nil {
+			return err
+		}
+		_, err := r.ledgerRepo.SettleHold(tx, withdrawalTransfer(withdrawal, "settle"))
+		return err
+	})
+}
+
+func (r *WithdrawalRepo) systemTransition(ctx context.Context, id uuid.UUID, apply func(tx *gorm.DB, withdrawal *models.Withdrawal) error) error {
+	return r.DB().WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var withdrawal models.Withdrawal
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&withdrawal, "id = ?", id).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrWithdrawalNotFound
+		}
+		if err != nil {
+			return err
+		}
+		return apply(tx, &withdrawal)
+	})
+}
+
+// transitionWithdrawal persists the new status together with any field set
+// on withdrawal by the caller, and appends the audit event.
+func transitionWithdrawal(tx *gorm.DB, withdrawal *models.Withdrawal, to, actor string, actorID *uuid.UUID, note string) error {
+	from := withdrawal.Status
+	if !constants.CanTransitionWithdrawal(from, to) {
+		return ErrWithdrawalState
+	}
+
+	now := time.Now()
+	withdrawal.Status = to
+	withdrawal.UpdatedAt = now
+	if to == constants.WITHDRAWAL_STATUS_APPROVED {
+		withdrawal.ApprovedAt = &now
+	}
+
+	if err := tx.Save(withdrawal).Error; err != nil {
+		return err
+	}
+	return recordWithdrawalEvent(tx, withdrawal, from, actor, actorID, note)
+}
+
+func recordWithdrawalEvent(tx *gorm.DB, withdrawal *models.Withdrawal, from, actor string, actorID *uuid.UUID, note string) error {
+	if actor == "" {
+		actor = constants.ACTOR_SYSTEM
+	}
+	return tx.Create(&models.WithdrawalEvent{
+		ID:           uuid.New(),
+		WithdrawalID: withdrawal.ID,
+		FromStatus:   from,
+		ToStatus:     withdrawal.Status,
+		Actor:        actor,
+		ActorID:      actorID,
+		Note:         note,
+	}).Error
+}
+
+func belowThreshold(tx *gorm.DB, withdrawal *models.Withdrawal, amount *big.Int) (bool, error) {
+	var threshold models.WithdrawalThreshold
+	err := tx.Where("merchant_id = ? AND chain_id = ? AND asset = ?",
+		withdrawal.MerchantID, withdrawal.ChainID, strings.ToLower(withdrawalAsset(withdrawal))).
+		First(&threshold).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	maxAmount, ok := new(big.Int).SetString(threshold.MaxAmount, 10)
+	return ok && amount.Cmp(maxAmount) <= 0, nil
+}
+
+func withdrawalAsset(withdrawal *models.Withdrawal) string {
+	if withdrawal.Token != nil {
+		return *withdrawal.Token
+	}
+	return withdrawal.Asset
+}
+
+func withdrawalKey(withdrawal *models.Withdrawal, step string) string {
+	return "withdrawal:" + step + ":" + withdrawal.ID.String()
+}
+
+func withdrawalTransfer(withdrawal *models.Withdrawal, step string) types.LedgerTransfer {
+	amount, _ := new(big.Int).SetString(withdrawal.Amount, 10)
+	return types.LedgerTransfer{
+		Key:         withdrawalKey(withdrawal, step),
+		MerchantID:  withdrawal.MerchantID,
+		ChainID:     withdrawal.ChainID,
+		Asset:       withdrawalAsset(withdrawal),
+		Symbol:      withdrawal.Asset,
+		Decimals:    withdrawal.Decimals,
+		Amount:      amount,
+		Description: "withdrawal to " + withdrawal.Destination,
+	}
+}
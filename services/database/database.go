@@ -3,15 +3,22 @@ package database
 import (
 	"context"
 	"core/application"
+	"core/blockchain"
+	"core/constants"
+	"core/helpers"
 	"core/models"
+	"encoding/hex"
 	"fmt"
 	"log"
 	"os"
+	"strings"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/joho/godotenv"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -97,12 +104,199 @@ func Migrate(app *application.App) error {
 
 		&models.ChainState{},
 		&models.Domain{},
+		&models.HDCounter{},
+		&models.APIKey{},
 		&models.Merchant{},
+		&models.Session{},
+		&models.PasswordReset{},
+		&models.RecoveryCode{},
 		&models.Transaction{},
 		&models.Wallet{},
+		&models.WalletAddress{},
+		&models.PaymentRequest{},
+		&models.PaymentRequestEvent{},
+		&models.LedgerAccount{},
+		&models.LedgerEntry{},
+		&models.LedgerPosting{},
+		&models.Withdrawal{},
+		&models.WithdrawalEvent{},
+		&models.WithdrawalThreshold{},
+		&models.WithdrawalAddress{},
+		&models.Sweep{},
+		&models.OutgoingTx{},
+		&models.AddressNonce{},
+		&models.NonceReservation{},
 	)
+	if err != nil {
+		return err
+	}
+
+	if err := migrateLegacyDomainKeys(app.DB); err != nil {
+		return err
+	}
+
+	return migrateLegacyWalletAddresses(app.DB, app.Router.Blockchains())
+}
+
+// migrateLegacyWalletAddresses copies the fixed address columns of wallets
+// into wallet_addresses and drops them.
+func migrateLegacyWalletAddresses(db *gorm.DB, factory *blockchain.ChainFactory) error {
+	if !db.Migrator().HasColumn(&models.Wallet{}, "bitcoin_address") {
+		return nil
+	}
+
+	fmt.Println("Migration:LegacyWalletAddresses")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID               uuid.UUID
+			MerchantID       uuid.UUID
+			DomainID         uuid.UUID
+			HDAccountID      uint32
+			HDAddressId      uint32
+			BitcoinAddress   string
+			EthereumAddress  string
+			AvalancheAddress string
+			TronAddress      string
+			SolanaAddress    string
+			ChilizAddress    string
+		}
+		if err := tx.Table("wallets").Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			legacy := map[string]string{
+				"bitcoin":   row.BitcoinAddress,
+				"ethereum":  row.EthereumAddress,
+				"avalanche": row.AvalancheAddress,
+				"tron":      row.TronAddress,
+				"solana":    row.SolanaAddress,
+				"chiliz":    row.ChilizAddress,
+			}
+
+			for name, address := range legacy {
+				if address == "" {
+					continue
+				}
+
+				chain, err := factory.GetChain(name)
+				if err != nil {
+					return err
+				}
+
+				walletAddress := models.WalletAddress{
+					ID:             uuid.New(),
+					WalletID:       row.ID,
+					MerchantID:     row.MerchantID,
+					DomainID:       row.DomainID,
+					ChainID:        chain.ChainID(),
+					Chain:          name,
+					Address:        address,
+					DerivationPath: chain.DerivationPath(int(row.HDAccountID), int(row.HDAddressId)),
+				}
+				if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&walletAddress).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		for _, column := range []string{"bitcoin_address", "ethereum_address", "avalanche_address", "tron_address", "solana_address", "chiliz_address"} {
+			if tx.Migrator().HasColumn(&models.Wallet{}, column) {
+				if err := tx.Migrator().DropColumn(&models.Wallet{}, column); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
+}
+
+// migrateLegacyDomainKeys moves the single key stored on the domains table
+// into api_keys (hashed) and drops the old columns. Older rows also kept the
+// encrypted webhook secret in api_secret and the plaintext in webhook_secret.
+func migrateLegacyDomainKeys(db *gorm.DB) error {
+	if !db.Migrator().HasColumn(&models.Domain{}, "api_key") {
+		return nil
+	}
+
+	fmt.Println("Migration:LegacyDomainKeys")
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rows []struct {
+			ID            uuid.UUID
+			MerchantID    uuid.UUID
+			KeyID         string
+			APIKey        string
+			APISecret     string
+			WebhookSecret string
+		}
+		if err := tx.Table("domains").
+			Select("id, merchant_id, key_id, api_key, api_secret, webhook_secret").
+			Scan(&rows).Error; err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			keyHash := row.APIKey
+			if _, err := hex.DecodeString(row.APIKey); err != nil || len(row.APIKey) != 64 {
+				keyHash = helpers.HashSHA256(row.APIKey)
+			}
 
-	return err
+			keyID := row.KeyID
+			if keyID == "" {
+				keyID, _ = helpers.ExtractKeyID(row.APIKey)
+			}
+
+			env := constants.API_KEY_ENV_LIVE
+			if strings.HasPrefix(row.APIKey, helpers.TestPrefix) {
+				env = constants.API_KEY_ENV_TEST
+			}
+
+			secretEnc := row.APISecret
+			if plain, err := helpers.DecryptSecret(row.APISecret); err == nil && plain == row.WebhookSecret {
+				if err := tx.Table("domains").
+					Where("id = ?", row.ID).
+					Update("webhook_secret", row.APISecret).Error; err != nil {
+					return err
+				}
+
+				secret, err := helpers.GenerateSecret()
+				if err != nil {
+					return err
+				}
+				if secretEnc, err = helpers.EncryptSecret(secret); err != nil {
+					return err
+				}
+			}
+
+			key := models.APIKey{
+				ID:          uuid.New(),
+				MerchantID:  row.MerchantID,
+				DomainID:    row.ID,
+				KeyID:       keyID,
+				KeyHash:     keyHash,
+				SecretEnc:   secretEnc,
+				Environment: env,
+				Label:       "legacy",
+				Scopes:      constants.DefaultScopes,
+			}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&key).Error; err != nil {
+				return err
+			}
+		}
+
+		for _, column := range []string{"api_key", "key_id", "api_secret"} {
+			if tx.Migrator().HasColumn(&models.Domain{}, column) {
+				if err := tx.Migrator().DropColumn(&models.Domain{}, column); err != nil {
+					return err
+				}
+			}
+		}
+
+		return nil
+	})
 }
 
 func Seed(app *application.App) error {
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"core/models"
+	"core/repositories"
+	"core/types"
+)
+
+type AllowlistService struct {
+	allowlistRepo *repositories.AllowlistRepo
+}
+
+func NewAllowlistService(allowlistRepo *repositories.AllowlistRepo) *AllowlistService {
+	return &AllowlistService{allowlistRepo: allowlistRepo}
+}
+
+func (s *AllowlistService) ServiceName() string {
+	return "AllowlistService"
+}
+
+func (s *AllowlistService) Add(params types.AllowlistParams) (*models.WithdrawalAddress, error) {
+	return s.allowlistRepo.Add(params)
+}
+
+func (s *AllowlistService) Remove(params types.AllowlistParams) error {
+	return s.allowlistRepo.Remove(params)
+}
+
+func (s *AllowlistService) List(params types.AllowlistParams) ([]models.WithdrawalAddress, *string, error) {
+	return s.allowlistRepo.List(params)
+}
+
+func (s *AllowlistService) SetEnabled(params types.AllowlistParams, enabled bool) (*models.Merchant, error) {
+	return s.allowlistRepo.SetEnabled(params, enabled)
+}
@@ -0,0 +1,40 @@
+package services
+
+import (
+	"context"
+	"core/models"
+	"core/repositories"
+	"core/types"
+)
+
+type APIKeyService struct {
+	apiKeyRepo *repositories.APIKeyRepo
+}
+
+func NewAPIKeyService(apiKeyRepo *repositories.APIKeyRepo) *APIKeyService {
+	return &APIKeyService{apiKeyRepo: apiKeyRepo}
+}
+
+func (s *APIKeyService) ServiceName() string {
+	return "APIKeyService"
+}
+
+func (s *APIKeyService) Create(params types.APIKeyParams) (*models.APIKey, error) {
+	return s.apiKeyRepo.Create(params)
+}
+
+func (s *APIKeyService) List(params types.APIKeyParams) ([]models.APIKey, error) {
+	return s.apiKeyRepo.List(params)
+}
+
+func (s *APIKeyService) Rotate(params types.APIKeyParams) (*models.APIKey, error) {
+	return s.apiKeyRepo.Rotate(params)
+}
+
+func (s *APIKeyService) Revoke(params types.APIKeyParams) error {
+	return s.apiKeyRepo.Revoke(params)
+}
+
+func (s *APIKeyService) Authenticate(ctx context.Context, apiKey string) (*models.APIKey, error) {
+	return s.apiKeyRepo.Authenticate(ctx, apiKey)
+}
@@ -30,10 +30,26 @@ func (s *DomainService) FindByAPIKey(params types.DomainParams) (*models.Domain,
 	return s.domainRepo.FindByAPIKey(params)
 }
 
-func (s *DomainService) FindBySecret(params types.DomainParams) (*models.Domain, error) {
-	return s.domainRepo.FindByAPIKey(params)
-}
-
 func (s *DomainService) FindByURL(params types.DomainParams) (*models.Domain, error) {
 	return s.domainRepo.FindByURL(params)
 }
+
+func (s *DomainService) Fetch(params types.DomainParams) (*models.Domain, error) {
+	return s.domainRepo.Fetch(params)
+}
+
+func (s *DomainService) List(params types.DomainParams) ([]models.Domain, *string, error) {
+	return s.domainRepo.List(params)
+}
+
+func (s *DomainService) Update(params types.DomainParams) (*models.Domain, error) {
+	return s.domainRepo.Update(params)
+}
+
+func (s *DomainService) SetEnabled(params types.DomainParams, enabled bool) (*models.Domain, error) {
+	return s.domainRepo.SetEnabled(params, enabled)
+}
+
+func (s *DomainService) Delete(params types.DomainParams) error {
+	return s.domainRepo.Delete(params)
+}
@@ -0,0 +1,22 @@
+package services
+
+import (
+	"core/repositories"
+	"core/types"
+)
+
+type FeeService struct {
+	feeRepo *repositories.FeeRepo
+}
+
+func NewFeeService(feeRepo *repositories.FeeRepo) *FeeService {
+	return &FeeService{feeRepo: feeRepo}
+}
+
+func (s *FeeService) ServiceName() string {
+	return "FeeService"
+}
+
+func (s *FeeService) Estimate(params types.FeeParams) (*types.FeeQuote, error) {
+	return s.feeRepo.Estimate(params)
+}
@@ -0,0 +1,27 @@
+package services
+
+import (
+	"core/models"
+	"core/repositories"
+	"core/types"
+)
+
+type LedgerService struct {
+	ledgerRepo *repositories.LedgerRepo
+}
+
+func NewLedgerService(ledgerRepo *repositories.LedgerRepo) *LedgerService {
+	return &LedgerService{ledgerRepo: ledgerRepo}
+}
+
+func (s *LedgerService) ServiceName() string {
+	return "LedgerService"
+}
+
+func (s *LedgerService) Balances(params types.LedgerParams) ([]models.LedgerAccount, error) {
+	return s.ledgerRepo.Balances(params)
+}
+
+func (s *LedgerService) Statement(params types.LedgerParams) ([]models.LedgerPosting, *string, error) {
+	return s.ledgerRepo.Statement(params)
+}
@@ -1,15 +1,24 @@
 package services
 
 import (
+	"context"
 	"core/models"
 	"core/repositories"
 	"core/types"
+	"errors"
+	"log"
 
 	"github.com/google/uuid"
 )
 
+// PasswordResetNotifier delivers reset tokens to the merchant (e-mail, SMS...).
+type PasswordResetNotifier interface {
+	NotifyPasswordReset(ctx context.Context, merchant *models.Merchant, token string) error
+}
+
 type MerchantService struct {
-	merchantRepo *repositories.MerchantRepo
+	merchantRepo  *repositories.MerchantRepo
+	resetNotifier PasswordResetNotifier
 }
 
 func NewMerchantService(merchantRepo *repositories.MerchantRepo) *MerchantService {
@@ -43,3 +52,33 @@ func (s *MerchantService) DeleteByID(params types.MerchantParams) error {
 func (s *MerchantService) Fetch(params types.MerchantParams) ([]models.Merchant, *uuid.UUID, error) {
 	return s.merchantRepo.Fetch(params)
 }
+
+func (s *MerchantService) SetResetNotifier(notifier PasswordResetNotifier) {
+	s.resetNotifier = notifier
+}
+
+func (s *MerchantService) ChangePassword(params types.MerchantParams) error {
+	return s.merchantRepo.ChangePassword(params)
+}
+
+// RequestPasswordReset never reports whether the e-mail is registered.
+func (s *MerchantService) RequestPasswordReset(params types.MerchantParams) error {
+	merchant, token, err := s.merchantRepo.RequestPasswordReset(params)
+	if errors.Is(err, repositories.ErrMerchantNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	if s.resetNotifier == nil {
+		log.Printf("[%s] password reset requested for %s but no notifier is configured\n", s.ServiceName(), merchant.ID)
+		return nil
+	}
+
+	return s.resetNotifier.NotifyPasswordReset(params.Context, merchant, token)
+}
+
+func (s *MerchantService) ResetPassword(params types.MerchantParams) error {
+	return s.merchantRepo.ResetPassword(params)
+}
@@ -0,0 +1,31 @@
+package services
+
+import (
+	"core/models"
+	"core/repositories"
+	"core/types"
+)
+
+type PaymentRequestService struct {
+	paymentRepo *repositories.PaymentRequestRepo
+}
+
+func NewPaymentRequestService(paymentRepo *repositories.PaymentRequestRepo) *PaymentRequestService {
+	return &PaymentRequestService{paymentRepo: paymentRepo}
+}
+
+func (s *PaymentRequestService) ServiceName() string {
+	return "PaymentRequestService"
+}
+
+func (s *PaymentRequestService) Create(params types.PaymentRequestParams) (*models.PaymentRequest, error) {
+	return s.paymentRepo.Create(params)
+}
+
+func (s *PaymentRequestService) Fetch(params types.PaymentRequestParams) (*models.PaymentRequest, error) {
+	return s.paymentRepo.Fetch(params)
+}
+
+func (s *PaymentRequestService) List(params types.PaymentRequestParams) ([]models.PaymentRequest, *string, error) {
+	return s.paymentRepo.List(params)
+}
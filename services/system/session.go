@@ -0,0 +1,35 @@
+package services
+
+import (
+	"core/models"
+	"core/repositories"
+	"core/types"
+)
+
+type SessionService struct {
+	sessionRepo *repositories.SessionRepo
+}
+
+func NewSessionService(sessionRepo *repositories.SessionRepo) *SessionService {
+	return &SessionService{sessionRepo: sessionRepo}
+}
+
+func (s *SessionService) ServiceName() string {
+	return "SessionService"
+}
+
+func (s *SessionService) Login(params types.SessionParams) (*types.SessionTokens, error) {
+	return s.sessionRepo.Login(params)
+}
+
+func (s *SessionService) Refresh(params types.SessionParams) (*types.SessionTokens, error) {
+	return s.sessionRepo.Refresh(params)
+}
+
+func (s *SessionService) Logout(params types.SessionParams) error {
+	return s.sessionRepo.Logout(params)
+}
+
+func (s *SessionService) Authenticate(params types.SessionParams) (*models.Session, error) {
+	return s.sessionRepo.Authenticate(params)
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"core/repositories"
+	"core/types"
+)
+
+type TwoFactorService struct {
+	twoFactorRepo *repositories.TwoFactorRepo
+}
+
+func NewTwoFactorService(twoFactorRepo *repositories.TwoFactorRepo) *TwoFactorService {
+	return &TwoFactorService{twoFactorRepo: twoFactorRepo}
+}
+
+func (s *TwoFactorService) ServiceName() string {
+	return "TwoFactorService"
+}
+
+func (s *TwoFactorService) Enroll(params types.TwoFactorParams) (*types.TwoFactorEnrollment, error) {
+	return s.twoFactorRepo.Enroll(params)
+}
+
+func (s *TwoFactorService) Confirm(params types.TwoFactorParams) (*types.RecoveryCodes, error) {
+	return s.twoFactorRepo.Confirm(params)
+}
+
+func (s *TwoFactorService) Disable(params types.TwoFactorParams) error {
+	return s.twoFactorRepo.Disable(params)
+}
+
+func (s *TwoFactorService) RegenerateRecoveryCodes(params types.TwoFactorParams) (*types.RecoveryCodes, error) {
+	return s.twoFactorRepo.RegenerateRecoveryCodes(params)
+}
+
+func (s *TwoFactorService) Verify(params types.TwoFactorParams) error {
+	return s.twoFactorRepo.Verify(params)
+}
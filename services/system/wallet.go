@@ -21,3 +21,19 @@ func (s *WalletService) ServiceName() string {
 func (s *WalletService) Create(params types.WalletParams) (*models.Wallet, error) {
 	return s.walletRepo.Create(params)
 }
+
+func (s *WalletService) DeriveAddresses(params types.WalletParams) (*models.Wallet, error) {
+	return s.walletRepo.DeriveAddresses(params)
+}
+
+func (s *WalletService) List(params types.WalletParams) ([]models.Wallet, *string, error) {
+	return s.walletRepo.List(params)
+}
+
+func (s *WalletService) FindByAddress(params types.WalletParams) (*models.Wallet, error) {
+	return s.walletRepo.FindByAddress(params)
+}
+
+func (s *WalletService) History(params types.WalletParams) ([]models.Transaction, *string, error) {
+	return s.walletRepo.History(params)
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"core/models"
+	"core/repositories"
+	"core/types"
+)
+
+type WithdrawalService struct {
+	withdrawalRepo *repositories.WithdrawalRepo
+}
+
+func NewWithdrawalService(withdrawalRepo *repositories.WithdrawalRepo) *WithdrawalService {
+	return &WithdrawalService{withdrawalRepo: withdrawalRepo}
+}
+
+func (s *WithdrawalService) ServiceName() string {
+	return "WithdrawalService"
+}
+
+func (s *WithdrawalService) Create(params types.WithdrawalParams) (*models.Withdrawal, error) {
+	return s.withdrawalRepo.Create(params)
+}
+
+func (s *WithdrawalService) Fetch(params types.WithdrawalParams) (*models.Withdrawal, error) {
+	return s.withdrawalRepo.Fetch(params)
+}
+
+func (s *WithdrawalService) List(params types.WithdrawalParams) ([]models.Withdrawal, *string, error) {
+	return s.withdrawalRepo.List(params)
+}
+
+func (s *WithdrawalService) Approve(params types.WithdrawalParams) (*models.Withdrawal, error) {
+	return s.withdrawalRepo.Approve(params)
+}
+
+func (s *WithdrawalService) Reject(params types.WithdrawalParams) (*models.Withdrawal, error) {
+	return s.withdrawalRepo.Reject(params)
+}
+
+func (s *WithdrawalService) SpeedUp(params types.WithdrawalParams) (*models.Withdrawal, error) {
+	return s.withdrawalRepo.SpeedUp(params)
+}
+
+func (s *WithdrawalService) Cancel(params types.WithdrawalParams) (*models.Withdrawal, error) {
+	return s.withdrawalRepo.Cancel(params)
+}
+
+func (s *WithdrawalService) SetThreshold(params types.WithdrawalParams) (*models.WithdrawalThreshold, error) {
+	return s.withdrawalRepo.SetThreshold(params)
+}
@@ -0,0 +1,88 @@
+package types
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+)
+
+type AllowlistParams struct {
+	Context    context.Context `json:"-"`
+	MerchantID *string         `json:"-"`
+	DomainID   *string         `json:"-"`
+
+	AddressID *string `json:"address_id,omitempty"`
+	Chain     *string `json:"chain,omitempty"` // chain name, e.g. "tron"
+	Address   *string `json:"address,omitempty"`
+	Label     *string `json:"label,omitempty"`
+
+	Pagination
+}
+
+func (p *AllowlistParams) validateOwner(errs *ValidationErrors) {
+	if p.Context == nil {
+		errs.Add("context", "context is required")
+	}
+	if p.MerchantID == nil || *p.MerchantID == "" {
+		errs.Add("merchant_id", "merchant_id is required")
+	} else if _, err := uuid.Parse(*p.MerchantID); err != nil {
+		errs.Add("merchant_id", "invalid merchant_id format")
+	}
+}
+
+func (p *AllowlistParams) ValidateMerchant() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *AllowlistParams) ValidateAdd() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+
+	if p.Chain == nil || *p.Chain == "" {
+		errs.Add("chain", "chain is required")
+	}
+	if p.Address == nil || *p.Address == "" {
+		errs.Add("address", "address is required")
+	}
+	if p.Label != nil && len(*p.Label) > 64 {
+		errs.Add("label", "label must be at most 64 characters")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *AllowlistParams) ValidateRemove() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+
+	if p.AddressID == nil || *p.AddressID == "" {
+		errs.Add("address_id", "address_id is required")
+	} else if _, err := uuid.Parse(*p.AddressID); err != nil {
+		errs.Add("address_id", "invalid address_id format")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *AllowlistParams) ValidateList() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+	p.Pagination.validate(&errs)
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"context"
+	"core/constants"
+
+	"github.com/google/uuid"
+)
+
+type APIKeyParams struct {
+	Context    context.Context `json:"-"`
+	MerchantID *uuid.UUID      `json:"-"`
+
+	DomainID    *string  `json:"domain_id,omitempty"`
+	KeyID       *string  `json:"key_id,omitempty"`
+	Environment *string  `json:"environment,omitempty"`
+	Label       *string  `json:"label,omitempty"`
+	Scopes      []string `json:"scopes,omitempty"`
+
+	// Seconds the replaced key stays valid after a rotation.
+	GracePeriod *int64 `json:"grace_period,omitempty"`
+}
+
+func (p *APIKeyParams) validateOwner(errs *ValidationErrors) {
+	if p.Context == nil {
+		errs.Add("context", "context is required")
+	}
+	if p.MerchantID == nil || *p.MerchantID == uuid.Nil {
+		errs.Add("merchant_id", "merchant_id is required")
+	}
+}
+
+func (p *APIKeyParams) ValidateCreate() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+
+	if p.DomainID == nil {
+		errs.Add("domain_id", "domain_id is required")
+	} else if _, err := uuid.Parse(*p.DomainID); err != nil {
+		errs.Add("domain_id", "invalid domain_id format")
+	}
+
+	if p.Environment != nil && *p.Environment != constants.API_KEY_ENV_LIVE && *p.Environment != constants.API_KEY_ENV_TEST {
+		errs.Add("environment", "environment must be live or test")
+	}
+
+	if len(p.Scopes) == 0 {
+		errs.Add("scopes", "at least one scope is required")
+	}
+	for _, scope := range p.Scopes {
+		if !constants.IsKnownScope(scope) {
+			errs.Add("scopes", "unknown scope: "+scope)
+		}
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *APIKeyParams) ValidateList() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+
+	if p.DomainID == nil {
+		errs.Add("domain_id", "domain_id is required")
+	} else if _, err := uuid.Parse(*p.DomainID); err != nil {
+		errs.Add("domain_id", "invalid domain_id format")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *APIKeyParams) ValidateKey() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+
+	if p.KeyID == nil || *p.KeyID == "" {
+		errs.Add("key_id", "key_id is required")
+	}
+	if p.GracePeriod != nil && *p.GracePeriod < 0 {
+		errs.Add("grace_period", "grace_period must not be negative")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
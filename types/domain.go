@@ -2,7 +2,9 @@ package types
 
 import (
 	"context"
-	"errors"
+	"core/constants"
+
+	"github.com/google/uuid"
 )
 
 type DomainParams struct {
@@ -12,23 +14,94 @@ type DomainParams struct {
 	WebhookURL    *string         `json:"webhook_url,omitempty"`
 	WebhookSecret *string         `json:"webhook_secret,omitempty"`
 
-	DomainID  *string `json:"domain_id"`
-	APIKey    *string `json:"api_key,omitempty"`
-	APISecret *string `json:"api_secret,omitempty"`
+	DomainID    *string `json:"domain_id"`
+	APIKey      *string `json:"api_key,omitempty"`
+	Environment *string `json:"environment,omitempty"`
+
+	Pagination
 }
 
 func (d *DomainParams) Validate() error {
+	var errs ValidationErrors
+
 	if d.MerchantID == nil || *d.MerchantID == "" {
-		return errors.New("MerchantID is required")
+		errs.Add("merchant_id", "merchant_id is required")
 	}
 	if d.DomainURL == nil || *d.DomainURL == "" {
-		return errors.New("DomainURL is required")
+		errs.Add("domain_url", "domain_url is required")
 	}
 	if d.WebhookURL == nil || *d.WebhookURL == "" {
-		return errors.New("WebhookURL is required")
+		errs.Add("webhook_url", "webhook_url is required")
 	}
 	if d.WebhookSecret == nil || *d.WebhookSecret == "" {
-		return errors.New("WebhookSecret is required")
+		errs.Add("webhook_secret", "webhook_secret is required")
+	}
+	if d.Environment != nil && *d.Environment != constants.API_KEY_ENV_LIVE && *d.Environment != constants.API_KEY_ENV_TEST {
+		errs.Add("environment", "environment must be live or test")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (d *DomainParams) validateOwner(errs *ValidationErrors) {
+	if d.Context == nil {
+		errs.Add("context", "context is required")
+	}
+	if d.MerchantID == nil || *d.MerchantID == "" {
+		errs.Add("merchant_id", "merchant_id is required")
+	} else if _, err := uuid.Parse(*d.MerchantID); err != nil {
+		errs.Add("merchant_id", "invalid merchant_id format")
+	}
+}
+
+func (d *DomainParams) ValidateLookup() error {
+	var errs ValidationErrors
+	d.validateOwner(&errs)
+
+	if d.DomainID == nil || *d.DomainID == "" {
+		errs.Add("domain_id", "domain_id is required")
+	} else if _, err := uuid.Parse(*d.DomainID); err != nil {
+		errs.Add("domain_id", "invalid domain_id format")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (d *DomainParams) ValidateList() error {
+	var errs ValidationErrors
+	d.validateOwner(&errs)
+	d.Pagination.validate(&errs)
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (d *DomainParams) ValidateUpdate() error {
+	if err := d.ValidateLookup(); err != nil {
+		return err
+	}
+
+	var errs ValidationErrors
+	if d.WebhookURL == nil && d.WebhookSecret == nil {
+		errs.Add("webhook_url", "webhook_url or webhook_secret is required")
+	}
+	if d.WebhookURL != nil && *d.WebhookURL == "" {
+		errs.Add("webhook_url", "webhook_url must not be empty")
+	}
+	if d.WebhookSecret != nil && *d.WebhookSecret == "" {
+		errs.Add("webhook_secret", "webhook_secret must not be empty")
+	}
+
+	if errs.HasErrors() {
+		return errs
 	}
 	return nil
 }
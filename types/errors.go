@@ -0,0 +1,98 @@
+package types
+
+import (
+	"errors"
+	"net/http"
+)
+
+type ErrorCode string
+
+const (
+	ErrCodeInvalidRequest     ErrorCode = "INVALID_REQUEST"
+	ErrCodeValidationFailed   ErrorCode = "VALIDATION_FAILED"
+	ErrCodeUnknownAction      ErrorCode = "UNKNOWN_ACTION"
+	ErrCodeMerchantNotFound   ErrorCode = "MERCHANT_NOT_FOUND"
+	ErrCodeMerchantExists     ErrorCode = "MERCHANT_EXISTS"
+	ErrCodeUnauthorized       ErrorCode = "UNAUTHORIZED"
+	ErrCodeInvalidLogin       ErrorCode = "INVALID_CREDENTIALS"
+	ErrCodeAccountLocked      ErrorCode = "ACCOUNT_LOCKED"
+	ErrCodeSessionExpired     ErrorCode = "SESSION_EXPIRED"
+	ErrCodeInvalidToken       ErrorCode = "INVALID_TOKEN"
+	ErrCodeTwoFactorNeeded    ErrorCode = "TWO_FACTOR_REQUIRED"
+	ErrCodeInvalidTwoFactor   ErrorCode = "INVALID_TWO_FACTOR"
+	ErrCodeTwoFactorState     ErrorCode = "TWO_FACTOR_STATE"
+	ErrCodeTwoFactorLocked    ErrorCode = "TWO_FACTOR_LOCKED"
+	ErrCodeDomainNotFound     ErrorCode = "DOMAIN_NOT_FOUND"
+	ErrCodeDomainExists       ErrorCode = "DOMAIN_EXISTS"
+	ErrCodeDomainDisabled     ErrorCode = "DOMAIN_DISABLED"
+	ErrCodeWalletNotFound     ErrorCode = "WALLET_NOT_FOUND"
+	ErrCodePaymentNotFound    ErrorCode = "PAYMENT_REQUEST_NOT_FOUND"
+	ErrCodePaymentExists      ErrorCode = "PAYMENT_REQUEST_EXISTS"
+	ErrCodeAPIKeyNotFound     ErrorCode = "API_KEY_NOT_FOUND"
+	ErrCodeAPIKeyInactive     ErrorCode = "API_KEY_INACTIVE"
+	ErrCodeAPIKeyEnvironment  ErrorCode = "API_KEY_ENVIRONMENT"
+	ErrCodeForbiddenScope     ErrorCode = "FORBIDDEN_SCOPE"
+	ErrCodeWithdrawalNotFound ErrorCode = "WITHDRAWAL_NOT_FOUND"
+	ErrCodeWithdrawalState    ErrorCode = "WITHDRAWAL_STATE"
+	ErrCodeTxNotReplaceable   ErrorCode = "TX_NOT_REPLACEABLE"
+	ErrCodeDestinationDenied  ErrorCode = "DESTINATION_NOT_ALLOWED"
+	ErrCodeAllowlistNotFound  ErrorCode = "ALLOWLIST_ADDRESS_NOT_FOUND"
+	ErrCodeAllowlistExists    ErrorCode = "ALLOWLIST_ADDRESS_EXISTS"
+	ErrCodeIdempotency        ErrorCode = "IDEMPOTENCY_CONFLICT"
+	ErrCodeInsufficientFunds  ErrorCode = "INSUFFICIENT_BALANCE"
+	ErrCodePriceUnavailable   ErrorCode = "PRICE_UNAVAILABLE"
+	ErrCodeChainUnavailable   ErrorCode = "CHAIN_UNAVAILABLE"
+	ErrCodeFeesUnsupported    ErrorCode = "FEES_UNSUPPORTED"
+	ErrCodeInternal           ErrorCode = "INTERNAL_ERROR"
+)
+
+// APIError is the error shape returned to API clients. Code is stable and
+// meant for branching; Message is human readable and may change.
+type APIError struct {
+	Status  int          `json:"-"`
+	Code    ErrorCode    `json:"code"`
+	Message string       `json:"message"`
+	Fields  []FieldError `json:"fields,omitempty"`
+}
+
+type ErrorResponse struct {
+	Success bool      `json:"success"`
+	Error   *APIError `json:"error"`
+}
+
+func NewAPIError(status int, code ErrorCode, message string) *APIError {
+	return &APIError{
+		Status:  status,
+		Code:    code,
+		Message: message,
+	}
+}
+
+func (e *APIError) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+func (e *APIError) Response() ErrorResponse {
+	return ErrorResponse{Success: false, Error: e}
+}
+
+// AsAPIError converts errors that already carry a client facing shape
+// (APIError, ValidationErrors). It returns false for anything else.
+func AsAPIError(err error) (*APIError, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr, true
+	}
+
+	var validationErrs ValidationErrors
+	if errors.As(err, &validationErrs) {
+		return &APIError{
+			Status:  http.StatusBadRequest,
+			Code:    ErrCodeValidationFailed,
+			Message: validationErrs.Error(),
+			Fields:  validationErrs,
+		}, true
+	}
+
+	return nil, false
+}
@@ -0,0 +1,69 @@
+package types
+
+import (
+	"context"
+	"core/constants"
+)
+
+// FeeConfig overrides how one chain prices its transactions.
+type FeeConfig struct {
+	Chain  string `json:"chain"`             // chain name, e.g. "ethereum"
+	Legacy bool   `json:"legacy"`            // price with eth_gasPrice only
+	MaxFee string `json:"max_fee,omitempty"` // cap of the fee per gas in gwei, e.g. "300"
+}
+
+func (c *FeeConfig) Validate() error {
+	var errs ValidationErrors
+
+	if c.Chain == "" {
+		errs.Add("chain", "chain is required")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+type FeeParams struct {
+	Context context.Context `json:"-"`
+
+	Chain    *string `json:"chain,omitempty"`    // chain name, e.g. "ethereum"
+	Strategy *string `json:"strategy,omitempty"` // slow, normal or fast
+}
+
+func (p *FeeParams) ValidateEstimate() error {
+	var errs ValidationErrors
+
+	if p.Context == nil {
+		errs.Add("context", "context is required")
+	}
+	if p.Chain == nil || *p.Chain == "" {
+		errs.Add("chain", "chain is required")
+	}
+	if p.Strategy == nil || *p.Strategy == "" {
+		strategy := constants.FEE_STRATEGY_NORMAL
+		p.Strategy = &strategy
+	} else if !constants.IsFeeStrategy(*p.Strategy) {
+		errs.Add("strategy", "strategy must be slow, normal or fast")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+// FeeQuote is the network fee of a chain for a strategy. Amounts are in
+// base units of the native coin (wei), fees per gas unless named a
+// transfer fee.
+type FeeQuote struct {
+	Chain             string  `json:"chain"`
+	Strategy          string  `json:"strategy"`
+	Legacy            bool    `json:"legacy"`
+	BaseFee           *string `json:"base_fee,omitempty"`
+	MaxPriorityFee    *string `json:"max_priority_fee,omitempty"`
+	MaxFee            string  `json:"max_fee"`
+	NativeTransferFee string  `json:"native_transfer_fee"`
+	TokenTransferFee  string  `json:"token_transfer_fee"`
+}
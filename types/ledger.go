@@ -0,0 +1,82 @@
+package types
+
+import (
+	"context"
+	"core/constants"
+	"math/big"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// LedgerTransfer describes a movement of one asset to post to the ledger.
+// Key makes posting idempotent: the same key is only ever posted once.
+type LedgerTransfer struct {
+	Key           string
+	MerchantID    uuid.UUID
+	TransactionID *uuid.UUID
+
+	ChainID  constants.ChainID
+	Asset    string // token contract or native symbol
+	Symbol   string
+	Decimals uint8
+	Amount   *big.Int
+
+	Description string
+}
+
+type LedgerParams struct {
+	Context    context.Context `json:"-"`
+	MerchantID *string         `json:"-"`
+	DomainID   *string         `json:"-"`
+
+	ChainID *constants.ChainID `json:"chain_id,omitempty"`
+	Asset   *string            `json:"asset,omitempty"` // symbol or token contract
+	From    *time.Time         `json:"from,omitempty"`
+	To      *time.Time         `json:"to,omitempty"`
+
+	Pagination
+}
+
+func (p *LedgerParams) validateOwner(errs *ValidationErrors) {
+	if p.Context == nil {
+		errs.Add("context", "context is required")
+	}
+	if p.MerchantID == nil || *p.MerchantID == "" {
+		errs.Add("merchant_id", "merchant_id is required")
+	} else if _, err := uuid.Parse(*p.MerchantID); err != nil {
+		errs.Add("merchant_id", "invalid merchant_id format")
+	}
+}
+
+func (p *LedgerParams) ValidateBalances() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *LedgerParams) ValidateStatement() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+	p.Pagination.validate(&errs)
+
+	if p.From != nil && p.To != nil && p.From.After(*p.To) {
+		errs.Add("from", "from must be before to")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+// LedgerAudit lists journal inconsistencies; both slices are empty on a
+// healthy ledger.
+type LedgerAudit struct {
+	MismatchedAccounts []uuid.UUID `json:"mismatched_accounts"`
+	UnbalancedEntries  []uuid.UUID `json:"unbalanced_entries"`
+}
@@ -2,7 +2,6 @@ package types
 
 import (
 	"context"
-	"errors"
 
 	"github.com/google/uuid"
 )
@@ -17,17 +16,21 @@ type MerchantParams struct {
 	PasswordRepeat *string         `json:"password_repeat,omitempty"`
 	Captcha        *string         `json:"captcha,omitempty"`
 
+	CurrentPassword *string    `json:"current_password,omitempty"`
+	ResetToken      *string    `json:"reset_token,omitempty"`
+	SessionID       *uuid.UUID `json:"-"`
+
 	Cursor *uuid.UUID `json:"cursor,omitempty"`
 	Limit  int        `json:"limit,omitempty"`
 }
 
 func (p *MerchantParams) ValidateEmail() error {
 	if p.Context == nil {
-		return errors.New("context is required")
+		return NewValidationError("context", "context is required")
 	}
 
 	if p.Email == nil || *p.Email == "" {
-		return errors.New("email is required")
+		return NewValidationError("email", "email is required")
 	}
 
 	return nil
@@ -35,15 +38,15 @@ func (p *MerchantParams) ValidateEmail() error {
 
 func (p *MerchantParams) ValidateID() error {
 	if p.Context == nil {
-		return errors.New("context is required")
+		return NewValidationError("context", "context is required")
 	}
 
 	if p.ID == nil {
-		return errors.New("id is required")
+		return NewValidationError("id", "id is required")
 	}
 
 	if *p.ID == uuid.Nil {
-		return errors.New("invalid id")
+		return NewValidationError("id", "invalid id")
 	}
 
 	return nil
@@ -7,6 +7,8 @@ import (
 	"net/url"
 	"regexp"
 	"strings"
+
+	"github.com/google/uuid"
 )
 
 func (p *MerchantParams) Validate() error {
@@ -52,6 +54,46 @@ func (p *MerchantParams) Validate() error {
 	return nil
 }
 
+func (p *MerchantParams) ValidatePasswordChange() error {
+	var errs ValidationErrors
+
+	if p.ID == nil || *p.ID == uuid.Nil {
+		errs.Add("id", "id is required")
+	}
+	if p.CurrentPassword == nil || *p.CurrentPassword == "" {
+		errs.Add("current_password", "current_password is required")
+	}
+	p.validateNewPassword(&errs)
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *MerchantParams) ValidatePasswordReset() error {
+	var errs ValidationErrors
+
+	if p.ResetToken == nil || *p.ResetToken == "" {
+		errs.Add("reset_token", "reset_token is required")
+	}
+	p.validateNewPassword(&errs)
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *MerchantParams) validateNewPassword(errs *ValidationErrors) {
+	if p.Password == nil || len(*p.Password) < 6 {
+		errs.Add("password", "password must be at least 6 characters")
+	}
+	if p.PasswordRepeat == nil || p.Password == nil || *p.Password != *p.PasswordRepeat {
+		errs.Add("password_repeat", "passwords do not match")
+	}
+}
+
 func (r *MerchantParams) VerifyCaptcha(secret string, response string) (bool, error) {
 	type recaptchaResponse struct {
 		Success bool `json:"success"`
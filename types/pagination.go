@@ -0,0 +1,24 @@
+package types
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+type Pagination struct {
+	Cursor *string `json:"cursor,omitempty"`
+	Limit  *int    `json:"limit,omitempty"`
+}
+
+func (p *Pagination) validate(errs *ValidationErrors) {
+	if p.Limit != nil && (*p.Limit < 1 || *p.Limit > MaxPageSize) {
+		errs.Add("limit", "limit must be between 1 and 100")
+	}
+}
+
+func (p Pagination) PageSize() int {
+	if p.Limit == nil {
+		return DefaultPageSize
+	}
+	return *p.Limit
+}
@@ -0,0 +1,153 @@
+package types
+
+import (
+	"context"
+	"core/constants"
+	"encoding/json"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type PaymentRequestParams struct {
+	Context    context.Context `json:"-"`
+	MerchantID *string         `json:"-"`
+	DomainID   *string         `json:"domain_id,omitempty"`
+
+	PaymentRequestID *string `json:"payment_request_id,omitempty"`
+	OrderID          *string `json:"order_id,omitempty"`
+
+	Chain  *string `json:"chain,omitempty"`  // chain name, e.g. "tron"
+	Asset  *string `json:"asset,omitempty"`  // symbol or token contract
+	Amount *string `json:"amount,omitempty"` // decimal, in asset units
+
+	// Price in fiat instead of Amount; converted at the current rate.
+	FiatAmount   *string `json:"fiat_amount,omitempty"`
+	FiatCurrency *string `json:"fiat_currency,omitempty"`
+	// Accepted deviation from the expected amount, in basis points.
+	ToleranceBps *int `json:"tolerance_bps,omitempty"`
+
+	// Seconds until the request expires.
+	ExpiresIn *int64 `json:"expires_in,omitempty"`
+	// Reuse an idle address of the domain instead of deriving a new one.
+	ReuseAddress bool                   `json:"reuse_address,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+
+	Status *string `json:"status,omitempty"`
+
+	Pagination
+}
+
+func (p *PaymentRequestParams) validateOwner(errs *ValidationErrors) {
+	if p.Context == nil {
+		errs.Add("context", "context is required")
+	}
+	if p.MerchantID == nil || *p.MerchantID == "" {
+		errs.Add("merchant_id", "merchant_id is required")
+	} else if _, err := uuid.Parse(*p.MerchantID); err != nil {
+		errs.Add("merchant_id", "invalid merchant_id format")
+	}
+
+	if p.DomainID == nil || *p.DomainID == "" {
+		errs.Add("domain_id", "domain_id is required")
+	} else if _, err := uuid.Parse(*p.DomainID); err != nil {
+		errs.Add("domain_id", "invalid domain_id format")
+	}
+}
+
+func (p *PaymentRequestParams) ValidateCreate() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+
+	if p.OrderID == nil || *p.OrderID == "" {
+		errs.Add("order_id", "order_id is required")
+	} else if len(*p.OrderID) > 128 {
+		errs.Add("order_id", "order_id must be at most 128 characters")
+	}
+	if p.Chain == nil || *p.Chain == "" {
+		errs.Add("chain", "chain is required")
+	}
+	if p.Asset == nil || *p.Asset == "" {
+		errs.Add("asset", "asset is required")
+	}
+
+	hasAmount := p.Amount != nil && *p.Amount != ""
+	hasFiat := p.FiatAmount != nil && *p.FiatAmount != ""
+	switch {
+	case hasAmount && hasFiat:
+		errs.Add("amount", "amount and fiat_amount are mutually exclusive")
+	case !hasAmount && !hasFiat:
+		errs.Add("amount", "amount or fiat_amount is required")
+	case hasFiat:
+		if p.FiatCurrency == nil || *p.FiatCurrency == "" {
+			errs.Add("fiat_currency", "fiat_currency is required with fiat_amount")
+		} else if !constants.IsSupportedFiat(strings.ToUpper(*p.FiatCurrency)) {
+			errs.Add("fiat_currency", "unsupported fiat_currency")
+		}
+	}
+
+	if p.ToleranceBps != nil && (*p.ToleranceBps < 0 || *p.ToleranceBps > constants.PAYMENT_MAX_TOLERANCE_BPS) {
+		errs.Add("tolerance_bps", "tolerance_bps must be between 0 and 1000")
+	}
+
+	if p.ExpiresIn != nil {
+		expiresIn := time.Duration(*p.ExpiresIn) * time.Second
+		if expiresIn < constants.PAYMENT_MIN_EXPIRY || expiresIn > constants.PAYMENT_MAX_EXPIRY {
+			errs.Add("expires_in", "expires_in must be between 60 seconds and 7 days")
+		}
+	}
+
+	if p.Metadata != nil {
+		encoded, err := json.Marshal(p.Metadata)
+		if err != nil || len(encoded) > constants.PAYMENT_MAX_METADATA {
+			errs.Add("metadata", "metadata must be a JSON object of at most 4096 bytes")
+		}
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *PaymentRequestParams) ValidateLookup() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+
+	hasID := p.PaymentRequestID != nil && *p.PaymentRequestID != ""
+	hasOrder := p.OrderID != nil && *p.OrderID != ""
+	if !hasID && !hasOrder {
+		errs.Add("payment_request_id", "payment_request_id or order_id is required")
+	}
+	if hasID {
+		if _, err := uuid.Parse(*p.PaymentRequestID); err != nil {
+			errs.Add("payment_request_id", "invalid payment_request_id format")
+		}
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *PaymentRequestParams) ValidateList() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+	p.Pagination.validate(&errs)
+
+	if p.Status != nil {
+		switch *p.Status {
+		case constants.PAYMENT_STATUS_PENDING, constants.PAYMENT_STATUS_PARTIALLY_PAID,
+			constants.PAYMENT_STATUS_PAID, constants.PAYMENT_STATUS_OVERPAID, constants.PAYMENT_STATUS_EXPIRED:
+		default:
+			errs.Add("status", "unknown status")
+		}
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type SessionParams struct {
+	Context      context.Context `json:"-"`
+	Email        *string         `json:"email,omitempty"`
+	Password     *string         `json:"password,omitempty"`
+	RefreshToken *string         `json:"refresh_token,omitempty"`
+	OTP          *string         `json:"otp,omitempty"` // TOTP or recovery code when 2FA is enabled
+
+	// Filled by the server, never read from the request body.
+	AccessToken *string    `json:"-"`
+	SessionID   *uuid.UUID `json:"-"`
+	IP          string     `json:"-"`
+	UserAgent   string     `json:"-"`
+}
+
+type SessionTokens struct {
+	SessionID        uuid.UUID `json:"session_id"`
+	AccessToken      string    `json:"access_token"`
+	AccessExpiresAt  time.Time `json:"access_expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
+}
+
+func (p *SessionParams) ValidateLogin() error {
+	var errs ValidationErrors
+
+	if p.Context == nil {
+		errs.Add("context", "context is required")
+	}
+	if p.Email == nil || strings.TrimSpace(*p.Email) == "" {
+		errs.Add("email", "email is required")
+	}
+	if p.Password == nil || *p.Password == "" {
+		errs.Add("password", "password is required")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *SessionParams) ValidateRefresh() error {
+	var errs ValidationErrors
+
+	if p.Context == nil {
+		errs.Add("context", "context is required")
+	}
+	if p.RefreshToken == nil || *p.RefreshToken == "" {
+		errs.Add("refresh_token", "refresh_token is required")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *SessionParams) ValidateAccessToken() error {
+	if p.Context == nil {
+		return NewValidationError("context", "context is required")
+	}
+	if p.AccessToken == nil || *p.AccessToken == "" {
+		return NewValidationError("access_token", "access token is required")
+	}
+	return nil
+}
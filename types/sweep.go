@@ -0,0 +1,29 @@
+package types
+
+// SweepPolicy configures sweeping of one asset on one chain to a treasury
+// address. Deposit addresses holding no more than Dust are left alone.
+type SweepPolicy struct {
+	Chain    string `json:"chain"`    // chain name, e.g. "ethereum"
+	Asset    string `json:"asset"`    // symbol or token contract
+	Treasury string `json:"treasury"` // hot or cold wallet receiving the funds
+	Dust     string `json:"dust"`     // decimal amount of the asset, e.g. "5.5"
+}
+
+func (p *SweepPolicy) Validate() error {
+	var errs ValidationErrors
+
+	if p.Chain == "" {
+		errs.Add("chain", "chain is required")
+	}
+	if p.Asset == "" {
+		errs.Add("asset", "asset is required")
+	}
+	if p.Treasury == "" {
+		errs.Add("treasury", "treasury is required")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
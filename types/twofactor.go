@@ -0,0 +1,43 @@
+package types
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+)
+
+type TwoFactorParams struct {
+	Context    context.Context `json:"-"`
+	MerchantID *uuid.UUID      `json:"-"`
+	Code       *string         `json:"code,omitempty"`
+}
+
+type TwoFactorEnrollment struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+func (p *TwoFactorParams) ValidateMerchant() error {
+	if p.Context == nil {
+		return NewValidationError("context", "context is required")
+	}
+	if p.MerchantID == nil || *p.MerchantID == uuid.Nil {
+		return NewValidationError("merchant_id", "merchant_id is required")
+	}
+	return nil
+}
+
+func (p *TwoFactorParams) Validate() error {
+	if err := p.ValidateMerchant(); err != nil {
+		return err
+	}
+	if p.Code == nil || strings.TrimSpace(*p.Code) == "" {
+		return NewValidationError("code", "code is required")
+	}
+	return nil
+}
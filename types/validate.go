@@ -21,3 +21,7 @@ func (v *ValidationErrors) Add(field, message string) {
 func (v ValidationErrors) HasErrors() bool {
 	return len(v) > 0
 }
+
+func NewValidationError(field, message string) ValidationErrors {
+	return ValidationErrors{{Field: field, Message: message}}
+}
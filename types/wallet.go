@@ -2,7 +2,8 @@ package types
 
 import (
 	"context"
-	"errors"
+	"core/constants"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -11,21 +12,130 @@ type WalletParams struct {
 	Context    context.Context `json:"-"`
 	MerchantId *string         `json:"merchant_id,omitempty"`
 	DomainId   *string         `json:"domain_id,omitempty"`
+
+	WalletId *string  `json:"wallet_id,omitempty"`
+	Address  *string  `json:"address,omitempty"`
+	Chains   []string `json:"chains,omitempty"` // chain names to derive, all registered chains when empty
+
+	// History filters
+	ChainID *constants.ChainID `json:"chain_id,omitempty"`
+	Asset   *string            `json:"asset,omitempty"` // symbol or token contract
+	Status  *string            `json:"status,omitempty"`
+	From    *time.Time         `json:"from,omitempty"`
+	To      *time.Time         `json:"to,omitempty"`
+
+	Pagination
 }
 
 func (wp *WalletParams) Validate() error {
+	var errs ValidationErrors
+
 	if wp.MerchantId == nil || *wp.MerchantId == "" {
-		return errors.New("MerchantId is required")
+		errs.Add("merchant_id", "merchant_id is required")
+	} else if _, err := uuid.Parse(*wp.MerchantId); err != nil {
+		errs.Add("merchant_id", "invalid merchant_id format")
 	}
+
+	if wp.DomainId == nil || *wp.DomainId == "" {
+		errs.Add("domain_id", "domain_id is required")
+	} else if _, err := uuid.Parse(*wp.DomainId); err != nil {
+		errs.Add("domain_id", "invalid domain_id format")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (wp *WalletParams) ValidateList() error {
+	var errs ValidationErrors
+	wp.validateOwner(&errs)
+	wp.Pagination.validate(&errs)
+
 	if wp.DomainId == nil || *wp.DomainId == "" {
-		return errors.New("DomainId is required")
+		errs.Add("domain_id", "domain_id is required")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (wp *WalletParams) ValidateDerive() error {
+	var errs ValidationErrors
+	wp.validateOwner(&errs)
+	wp.validateWalletID(&errs)
+
+	if len(wp.Chains) == 0 {
+		errs.Add("chains", "at least one chain is required")
+	}
+
+	if errs.HasErrors() {
+		return errs
 	}
-	if _, err := uuid.Parse(*wp.MerchantId); err != nil {
-		return errors.New("invalid MerchantId format")
+	return nil
+}
+
+func (wp *WalletParams) ValidateAddressLookup() error {
+	var errs ValidationErrors
+	wp.validateOwner(&errs)
+
+	if wp.Address == nil || *wp.Address == "" {
+		errs.Add("address", "address is required")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (wp *WalletParams) ValidateHistory() error {
+	var errs ValidationErrors
+	wp.validateOwner(&errs)
+	wp.Pagination.validate(&errs)
+	wp.validateWalletID(&errs)
+
+	if wp.Status != nil {
+		switch *wp.Status {
+		case constants.TX_STATUS_PENDING, constants.TX_STATUS_CONFIRMED, constants.TX_STATUS_FAILED, constants.TX_STATUS_ORPHANED:
+		default:
+			errs.Add("status", "status must be pending, confirmed, failed or orphaned")
+		}
 	}
-	if _, err := uuid.Parse(*wp.DomainId); err != nil {
-		return errors.New("invalid DomainId format")
+
+	if wp.From != nil && wp.To != nil && wp.From.After(*wp.To) {
+		errs.Add("from", "from must be before to")
 	}
 
+	if errs.HasErrors() {
+		return errs
+	}
 	return nil
 }
+
+// validateOwner checks the merchant and, when given, the domain the request
+// is restricted to.
+func (wp *WalletParams) validateOwner(errs *ValidationErrors) {
+	if wp.MerchantId == nil || *wp.MerchantId == "" {
+		errs.Add("merchant_id", "merchant_id is required")
+	} else if _, err := uuid.Parse(*wp.MerchantId); err != nil {
+		errs.Add("merchant_id", "invalid merchant_id format")
+	}
+
+	if wp.DomainId != nil && *wp.DomainId != "" {
+		if _, err := uuid.Parse(*wp.DomainId); err != nil {
+			errs.Add("domain_id", "invalid domain_id format")
+		}
+	}
+}
+
+func (wp *WalletParams) validateWalletID(errs *ValidationErrors) {
+	if wp.WalletId == nil || *wp.WalletId == "" {
+		errs.Add("wallet_id", "wallet_id is required")
+	} else if _, err := uuid.Parse(*wp.WalletId); err != nil {
+		errs.Add("wallet_id", "invalid wallet_id format")
+	}
+}
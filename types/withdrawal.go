@@ -0,0 +1,152 @@
+package types
+
+import (
+	"context"
+	"core/constants"
+
+	"github.com/google/uuid"
+)
+
+type WithdrawalParams struct {
+	Context    context.Context `json:"-"`
+	MerchantID *string         `json:"-"`
+	DomainID   *string         `json:"domain_id,omitempty"`
+
+	// Caller recorded in the audit log, set from the authenticated request.
+	Actor   string     `json:"-"`
+	ActorID *uuid.UUID `json:"-"`
+
+	WithdrawalID   *string `json:"withdrawal_id,omitempty"`
+	IdempotencyKey *string `json:"idempotency_key,omitempty"`
+
+	Chain       *string `json:"chain,omitempty"`  // chain name, e.g. "tron"
+	Asset       *string `json:"asset,omitempty"`  // symbol or token contract
+	Amount      *string `json:"amount,omitempty"` // decimal, in asset units
+	Destination *string `json:"destination,omitempty"`
+
+	Reason    *string `json:"reason,omitempty"`     // reject
+	MaxAmount *string `json:"max_amount,omitempty"` // auto-approval threshold, decimal
+
+	Status *string `json:"status,omitempty"`
+
+	Pagination
+}
+
+func (p *WithdrawalParams) validateOwner(errs *ValidationErrors) {
+	if p.Context == nil {
+		errs.Add("context", "context is required")
+	}
+	if p.MerchantID == nil || *p.MerchantID == "" {
+		errs.Add("merchant_id", "merchant_id is required")
+	} else if _, err := uuid.Parse(*p.MerchantID); err != nil {
+		errs.Add("merchant_id", "invalid merchant_id format")
+	}
+
+	if p.DomainID != nil && *p.DomainID != "" {
+		if _, err := uuid.Parse(*p.DomainID); err != nil {
+			errs.Add("domain_id", "invalid domain_id format")
+		}
+	}
+}
+
+func (p *WithdrawalParams) validateWithdrawalID(errs *ValidationErrors) {
+	if p.WithdrawalID == nil || *p.WithdrawalID == "" {
+		errs.Add("withdrawal_id", "withdrawal_id is required")
+	} else if _, err := uuid.Parse(*p.WithdrawalID); err != nil {
+		errs.Add("withdrawal_id", "invalid withdrawal_id format")
+	}
+}
+
+func (p *WithdrawalParams) validateAsset(errs *ValidationErrors) {
+	if p.Chain == nil || *p.Chain == "" {
+		errs.Add("chain", "chain is required")
+	}
+	if p.Asset == nil || *p.Asset == "" {
+		errs.Add("asset", "asset is required")
+	}
+}
+
+func (p *WithdrawalParams) ValidateCreate() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+	p.validateAsset(&errs)
+
+	if p.IdempotencyKey == nil || *p.IdempotencyKey == "" {
+		errs.Add("idempotency_key", "idempotency_key is required")
+	} else if len(*p.IdempotencyKey) > 128 {
+		errs.Add("idempotency_key", "idempotency_key must be at most 128 characters")
+	}
+	if p.Amount == nil || *p.Amount == "" {
+		errs.Add("amount", "amount is required")
+	}
+	if p.Destination == nil || *p.Destination == "" {
+		errs.Add("destination", "destination is required")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *WithdrawalParams) ValidateLookup() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+	p.validateWithdrawalID(&errs)
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *WithdrawalParams) ValidateReject() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+	p.validateWithdrawalID(&errs)
+
+	if p.Reason != nil && len(*p.Reason) > 255 {
+		errs.Add("reason", "reason must be at most 255 characters")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *WithdrawalParams) ValidateList() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+	p.Pagination.validate(&errs)
+
+	if p.Status != nil {
+		if _, ok := constants.WithdrawalTransitions[*p.Status]; !ok {
+			switch *p.Status {
+			case constants.WITHDRAWAL_STATUS_REJECTED, constants.WITHDRAWAL_STATUS_CONFIRMED, constants.WITHDRAWAL_STATUS_FAILED:
+			default:
+				errs.Add("status", "unknown status")
+			}
+		}
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
+
+func (p *WithdrawalParams) ValidateThreshold() error {
+	var errs ValidationErrors
+	p.validateOwner(&errs)
+	p.validateAsset(&errs)
+
+	if p.MaxAmount == nil || *p.MaxAmount == "" {
+		errs.Add("max_amount", "max_amount is required")
+	}
+
+	if errs.HasErrors() {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,134 @@
+package confirmations
+
+import (
+	"context"
+	"core/blockchain"
+	"core/constants"
+	"core/models"
+	"core/repositories"
+	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const confirmBatchSize = 100
+
+// Confirmer finalizes the transfers the listeners persist as pending. Once
+// the block of a transfer is TxConfirmations deep its transaction is looked
+// up again: still in that block it is confirmed and the deposit credited,
+// mined elsewhere or gone after a reorg it is orphaned, reverted it fails.
+type Confirmer struct {
+	repo     *repositories.TransactionRepo
+	chains   *blockchain.ChainFactory
+	interval time.Duration
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewConfirmer(repo *repositories.TransactionRepo, chains *blockchain.ChainFactory, interval time.Duration) *Confirmer {
+	if interval <= 0 {
+		interval = constants.TX_CONFIRM_INTERVAL
+	}
+	return &Confirmer{repo: repo, chains: chains, interval: interval}
+}
+
+func (c *Confirmer) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+
+		ticker := time.NewTicker(c.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.tick(ctx)
+			}
+		}
+	}()
+}
+
+func (c *Confirmer) Stop() {
+	if c.cancel != nil {
+		c.cancel()
+	}
+	c.wg.Wait()
+}
+
+func (c *Confirmer) tick(ctx context.Context) {
+	names := c.chains.ListChains()
+	sort.Strings(names)
+
+	for _, name := range names {
+		if ctx.Err() != nil {
+			return
+		}
+		chain, err := c.chains.GetChain(name)
+		if err != nil {
+			continue
+		}
+		if err := c.confirm(ctx, chain); err != nil {
+			log.Printf("[confirmations] %s: %v\n", name, err)
+		}
+	}
+}
+
+// confirm settles the pending transfers of chain that are deep enough.
+// Chains that cannot look up their head are skipped, their transfers stay
+// pending.
+func (c *Confirmer) confirm(ctx context.Context, chain blockchain.Chain) error {
+	head, err := chain.BlockNumber(ctx)
+	if errors.Is(err, blockchain.ErrNotImplemented) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	depth := constants.TxConfirmations(chain.ChainID())
+	if head+1 < depth {
+		return nil
+	}
+
+	transfers, err := c.repo.Pending(ctx, chain.ChainID(), head+1-depth, confirmBatchSize)
+	if err != nil {
+		return err
+	}
+
+	for i := range transfers {
+		if err := c.settle(ctx, chain, &transfers[i]); err != nil {
+			log.Printf("[confirmations] %s %s: %v\n", chain.Name(), transfers[i].Hash, err)
+		}
+	}
+	return nil
+}
+
+func (c *Confirmer) settle(ctx context.Context, chain blockchain.Chain, transfer *models.Transaction) error {
+	inclusion, err := chain.TxInclusion(ctx, transfer.Hash)
+	if err != nil {
+		return err
+	}
+
+	block, err := strconv.ParseUint(transfer.BlockNumber, 10, 64)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case inclusion == nil || inclusion.BlockNumber != block:
+		log.Printf("[confirmations] %s %s left block %d in a reorg\n", chain.Name(), transfer.Hash, block)
+		return c.repo.Orphan(ctx, transfer.ID)
+	case !inclusion.Success:
+		return c.repo.Fail(ctx, transfer.ID)
+	default:
+		return c.repo.Confirm(ctx, transfer.ID)
+	}
+}
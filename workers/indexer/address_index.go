@@ -13,6 +13,7 @@ import (
 )
 
 type WalletInfo struct {
+	WalletID   uuid.UUID
 	MerchantID uuid.UUID
 	DomainID   uuid.UUID
 }
@@ -24,49 +25,50 @@ type AddressIndex struct {
 	index map[constants.ChainID]map[string]WalletInfo
 }
 
-func NewAddressIndex() *AddressIndex {
+func NewAddressIndex(ctx context.Context, db *gorm.DB) *AddressIndex {
 	return &AddressIndex{
+		ctx:   ctx,
+		db:    db,
 		index: make(map[constants.ChainID]map[string]WalletInfo),
 	}
 }
 
+// Load rebuilds the index from wallet_addresses, so new chains need no change
+// here. Addresses of disabled and deleted domains stay indexed: they were
+// handed out and deposits to them still have to be credited.
 func (a *AddressIndex) Load() error {
-	var wallets []models.Wallet
+	var addresses []models.WalletAddress
 
 	err := a.db.WithContext(a.ctx).
 		Select(
-			"id",
-			"merchant_id",
-			"domain_id",
-			"bitcoin_address",
-			"ethereum_address",
-			"avalanche_address",
-			"tron_address",
-			"solana_address",
-			"chiliz_address",
+			"wallet_addresses.wallet_id",
+			"wallet_addresses.merchant_id",
+			"wallet_addresses.domain_id",
+			"wallet_addresses.chain_id",
+			"wallet_addresses.address",
 		).
-		Find(&wallets).Error
+		Find(&addresses).Error
 
 	if err != nil {
 		return err
 	}
 
-	a.mu.Lock()
-	defer a.mu.Unlock()
-
-	for _, w := range wallets {
-		info := WalletInfo{
+	index := make(map[constants.ChainID]map[string]WalletInfo)
+	for _, w := range addresses {
+		if index[w.ChainID] == nil {
+			index[w.ChainID] = make(map[string]WalletInfo)
+		}
+		index[w.ChainID][strings.ToLower(w.Address)] = WalletInfo{
+			WalletID:   w.WalletID,
 			MerchantID: w.MerchantID,
 			DomainID:   w.DomainID,
 		}
-		a.addUnsafe(constants.Bitcoin, w.BitcoinAddress, info)
-		a.addUnsafe(constants.Ethereum, w.EthereumAddress, info)
-		a.addUnsafe(constants.Avalanche, w.AvalancheAddress, info)
-		a.addUnsafe(constants.TRON, w.TronAddress, info)
-		a.addUnsafe(constants.Solana, w.SolanaAddress, info)
-		a.addUnsafe(constants.Chiliz, w.ChilizAddress, info)
 	}
 
+	a.mu.Lock()
+	a.index = index
+	a.mu.Unlock()
+
 	return nil
 }
 
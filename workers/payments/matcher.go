@@ -0,0 +1,64 @@
+package payments
+
+import (
+	"context"
+	"core/repositories"
+	"log"
+	"sync"
+	"time"
+)
+
+const DefaultMatchInterval = 15 * time.Second
+
+// Matcher periodically assigns persisted transfers to open payment requests
+// and expires the ones whose window has closed.
+type Matcher struct {
+	repo     *repositories.PaymentRequestRepo
+	interval time.Duration
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewMatcher(repo *repositories.PaymentRequestRepo, interval time.Duration) *Matcher {
+	if interval <= 0 {
+		interval = DefaultMatchInterval
+	}
+	return &Matcher{repo: repo, interval: interval}
+}
+
+func (m *Matcher) Start(ctx context.Context) {
+	ctx, m.cancel = context.WithCancel(ctx)
+
+	m.wg.Add(1)
+	go func() {
+		defer m.wg.Done()
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				m.tick(ctx)
+			}
+		}
+	}()
+}
+
+func (m *Matcher) Stop() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	m.wg.Wait()
+}
+
+func (m *Matcher) tick(ctx context.Context) {
+	if _, err := m.repo.MatchActive(ctx); err != nil {
+		log.Printf("[payments] match failed: %v\n", err)
+	}
+	if _, err := m.repo.ExpireDue(ctx); err != nil {
+		log.Printf("[payments] expiry failed: %v\n", err)
+	}
+}
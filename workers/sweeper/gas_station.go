@@ -0,0 +1,88 @@
+package sweeper
+
+import (
+	"context"
+	"core/blockchain"
+	"core/constants"
+	"errors"
+	"math/big"
+)
+
+// GasStation pays the network fee of token sweeps from deposit addresses
+// that hold no native coin, and takes back what is left afterwards.
+type GasStation struct{}
+
+func NewGasStation() *GasStation {
+	return &GasStation{}
+}
+
+// Wallet is the gas wallet of the chain.
+func (g *GasStation) Wallet(ctx context.Context, chain blockchain.Chain) (*blockchain.WalletDetails, error) {
+	return chain.CreateHDWallet(ctx, constants.GAS_WALLET_HD_ACCOUNT, constants.GAS_WALLET_HD_INDEX)
+}
+
+// Fund sends the exact shortfall of fee to address.
+func (g *GasStation) Fund(ctx context.Context, chain blockchain.Chain, address string, fee *blockchain.SweepFee) (*blockchain.TransactionResult, error) {
+	shortfall := fee.Shortfall()
+	if shortfall.Sign() <= 0 {
+		return nil, errors.New("address already holds enough gas")
+	}
+
+	wallet, err := g.Wallet(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := chain.Sweep(ctx, *wallet, blockchain.SweepRequest{
+		ToAddress: address,
+		Amount:    shortfall,
+	})
+	if err != nil {
+		// A top-up whose broadcast is unknown is returned with its result.
+		return result, err
+	}
+	if !result.Success {
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		return nil, errors.New("gas top-up not accepted by the chain")
+	}
+	return result, nil
+}
+
+// Recover sweeps the native coin left on a deposit wallet back to the gas
+// wallet. It returns a nil result when the leftover does not cover the fee
+// of moving it.
+func (g *GasStation) Recover(ctx context.Context, chain blockchain.Chain, wallet blockchain.WalletDetails) (*blockchain.TransactionResult, error) {
+	gasWallet, err := g.Wallet(ctx, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := chain.Sweep(ctx, wallet, blockchain.SweepRequest{ToAddress: gasWallet.Address})
+	if errors.Is(err, blockchain.ErrNothingToSweep) || errors.Is(err, blockchain.ErrInsufficientGas) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !result.Success {
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		return nil, errors.New("dust recovery not accepted by the chain")
+	}
+	return result, nil
+}
+
+// sweepFee is the fee a topped-up sweep is pinned to, nil for the others.
+func sweepFee(gasLimit uint64, gasPrice *string) *blockchain.SweepFee {
+	if gasLimit == 0 || gasPrice == nil {
+		return nil
+	}
+	price, ok := new(big.Int).SetString(*gasPrice, 10)
+	if !ok {
+		return nil
+	}
+	return &blockchain.SweepFee{GasLimit: gasLimit, GasPrice: price}
+}
@@ -0,0 +1,258 @@
+package sweeper
+
+import (
+	"context"
+	"core/blockchain"
+	"core/constants"
+	"core/models"
+	"core/repositories"
+	"core/types"
+	"errors"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Sweeper moves confirmed deposits from deposit addresses to the treasury
+// addresses configured per chain and asset. Each tick broadcasts at most
+// perChain sweeps per chain. Token sweeps from addresses without gas are
+// topped up by the gas station first.
+type Sweeper struct {
+	repo     *repositories.SweepRepo
+	outgoing *repositories.OutgoingTxRepo
+	chains   *blockchain.ChainFactory
+	gas      *GasStation
+	policies []types.SweepPolicy
+	interval time.Duration
+	perChain int
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewSweeper(repo *repositories.SweepRepo, outgoing *repositories.OutgoingTxRepo, chains *blockchain.ChainFactory, policies []types.SweepPolicy, interval time.Duration) *Sweeper {
+	if interval <= 0 {
+		interval = constants.SWEEP_INTERVAL
+	}
+	return &Sweeper{
+		repo:     repo,
+		outgoing: outgoing,
+		chains:   chains,
+		gas:      NewGasStation(),
+		policies: policies,
+		interval: interval,
+		perChain: constants.SWEEP_MAX_PER_CHAIN,
+	}
+}
+
+func (s *Sweeper) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+
+		ticker := time.NewTicker(s.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.tick(ctx)
+			}
+		}
+	}()
+}
+
+func (s *Sweeper) Stop() {
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+}
+
+func (s *Sweeper) tick(ctx context.Context) {
+	if n, err := s.repo.ReleaseStale(ctx, constants.SWEEP_STALE_AFTER); err != nil {
+		log.Printf("[sweeper] releasing stale sweeps failed: %v\n", err)
+	} else if n > 0 {
+		log.Printf("[sweeper] released %d stale sweeps\n", n)
+	}
+
+	sent := make(map[string]int)
+	for _, policy := range s.policies {
+		if ctx.Err() != nil {
+			return
+		}
+
+		planned, err := s.repo.Plan(ctx, policy, s.perChain-sent[policy.Chain])
+		if err != nil {
+			log.Printf("[sweeper] %s/%s planning failed: %v\n", policy.Chain, policy.Asset, err)
+		}
+		sent[policy.Chain] += len(planned)
+
+		for i := range planned {
+			s.send(ctx, &planned[i])
+		}
+	}
+
+	funded, err := s.repo.Funded(ctx)
+	if err != nil {
+		log.Printf("[sweeper] gas top-up check failed: %v\n", err)
+	}
+	for i := range funded {
+		s.send(ctx, &funded[i])
+	}
+
+	s.recoverDust(ctx)
+}
+
+func (s *Sweeper) send(ctx context.Context, sweep *models.Sweep) {
+	txHash, result, err := s.broadcast(ctx, sweep)
+	if errors.Is(err, errAwaitingGas) {
+		return
+	}
+	if errors.Is(err, blockchain.ErrSendUnknown) {
+		// The sweep may be on chain, failing it would sweep its deposits
+		// again. A signed transaction is followed like any broadcast one.
+		log.Printf("[sweeper] %s broadcast unknown: %v\n", sweep.ID, err)
+		if result == nil {
+			s.unrecorded(ctx, sweep, "sweep", err)
+			return
+		}
+	} else if err != nil {
+		log.Printf("[sweeper] %s send failed: %v\n", sweep.ID, err)
+		if err := s.repo.MarkFailed(ctx, sweep.ID, err.Error()); err != nil {
+			log.Printf("[sweeper] %s mark failed: %v\n", sweep.ID, err)
+		}
+		return
+	}
+
+	attempt, err := s.outgoing.Attempt(ctx, sweep.Chain, constants.OUTGOING_KIND_SWEEP, sweep.ID,
+		sweep.HDAccountID, sweep.HDAddressId, result)
+	if err == nil {
+		err = s.repo.MarkBroadcast(ctx, sweep.ID, result.Fee, attempt)
+	}
+	if err != nil {
+		s.unrecorded(ctx, sweep, "broadcast "+txHash, err)
+	}
+}
+
+// unrecorded parks a sweep whose transaction left but could not be
+// recorded, rather than let it go stale and its deposits be swept again.
+func (s *Sweeper) unrecorded(ctx context.Context, sweep *models.Sweep, what string, err error) {
+	log.Printf("[sweeper] %s %s not recorded: %v\n", sweep.ID, what, err)
+	if err := s.repo.MarkUnknown(ctx, sweep.ID, what+" not recorded: "+err.Error()); err != nil {
+		log.Printf("[sweeper] %s mark unknown failed: %v\n", sweep.ID, err)
+	}
+}
+
+// errAwaitingGas reports a sweep parked in funding until its gas top-up
+// confirms.
+var errAwaitingGas = errors.New("awaiting gas top-up")
+
+func (s *Sweeper) broadcast(ctx context.Context, sweep *models.Sweep) (string, *blockchain.TransactionResult, error) {
+	chain, wallet, err := s.wallet(ctx, sweep)
+	if err != nil {
+		return "", nil, err
+	}
+
+	request := blockchain.SweepRequest{
+		ToAddress: sweep.Destination,
+		Token:     sweep.Token,
+		Fee:       sweepFee(sweep.GasLimit, sweep.GasPrice),
+	}
+
+	if sweep.Token != nil && sweep.FundedAt == nil {
+		fee, err := chain.EstimateSweep(ctx, sweep.Address, request)
+		switch {
+		case errors.Is(err, blockchain.ErrNotImplemented):
+			// The chain cannot price the sweep, let Sweep report what it can.
+		case err != nil:
+			return "", nil, err
+		case fee.Shortfall().Sign() > 0:
+			funding, err := s.gas.Fund(ctx, chain, sweep.Address, fee)
+			if errors.Is(err, blockchain.ErrSendUnknown) && funding != nil {
+				log.Printf("[sweeper] %s gas top-up broadcast unknown: %v\n", sweep.ID, err)
+			} else if err != nil {
+				return "", nil, err
+			}
+			attempt, err := s.outgoing.Attempt(ctx, sweep.Chain, constants.OUTGOING_KIND_GAS_TOPUP, sweep.ID,
+				constants.GAS_WALLET_HD_ACCOUNT, constants.GAS_WALLET_HD_INDEX, funding)
+			if err == nil {
+				err = s.repo.MarkFunding(ctx, sweep.ID, fee, funding.Fee, attempt)
+			}
+			if err != nil {
+				s.unrecorded(ctx, sweep, "gas top-up "+funding.TxHash, err)
+			}
+			return "", nil, errAwaitingGas
+		default:
+			request.Fee = fee
+		}
+	}
+
+	result, err := chain.Sweep(ctx, *wallet, request)
+	if errors.Is(err, blockchain.ErrSendUnknown) && result != nil {
+		return result.TxHash, result, err
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	if !result.Success {
+		if result.Error != nil {
+			return "", nil, result.Error
+		}
+		return "", nil, errors.New("sweep not accepted by the chain")
+	}
+	return result.TxHash, result, nil
+}
+
+func (s *Sweeper) wallet(ctx context.Context, sweep *models.Sweep) (blockchain.Chain, *blockchain.WalletDetails, error) {
+	chain, err := s.chains.GetChain(sweep.Chain)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	wallet, err := chain.CreateHDWallet(ctx, int(sweep.HDAccountID), int(sweep.HDAddressId))
+	if err != nil {
+		return nil, nil, err
+	}
+	if !strings.EqualFold(wallet.Address, sweep.Address) {
+		return nil, nil, errors.New("derived key does not match deposit address " + sweep.Address)
+	}
+	return chain, wallet, nil
+}
+
+// recoverDust returns the gas left on topped-up addresses to the gas wallet.
+func (s *Sweeper) recoverDust(ctx context.Context) {
+	due, err := s.repo.DustDue(ctx, constants.SWEEP_DUST_RECOVERY_AFTER, s.perChain)
+	if err != nil {
+		log.Printf("[sweeper] dust lookup failed: %v\n", err)
+		return
+	}
+
+	for i := range due {
+		sweep := &due[i]
+
+		chain, wallet, err := s.wallet(ctx, sweep)
+		if err != nil {
+			log.Printf("[sweeper] %s dust recovery failed: %v\n", sweep.ID, err)
+			continue
+		}
+
+		result, err := s.gas.Recover(ctx, chain, *wallet)
+		if err != nil {
+			log.Printf("[sweeper] %s dust recovery failed: %v\n", sweep.ID, err)
+			continue
+		}
+
+		var txHash *string
+		if result != nil {
+			txHash = &result.TxHash
+		}
+		if err := s.repo.MarkRecovered(ctx, sweep.ID, txHash); err != nil {
+			log.Printf("[sweeper] %s dust recovery not recorded: %v\n", sweep.ID, err)
+		}
+	}
+}
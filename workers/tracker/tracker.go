@@ -0,0 +1,291 @@
+package tracker
+
+import (
+	"context"
+	"core/blockchain"
+	"core/constants"
+	"core/models"
+	"core/repositories"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+)
+
+const trackBatchSize = 50
+
+// Tracker follows the transactions the gateway broadcast until one of
+// their attempts is mined. An attempt not mined within a few blocks is
+// replaced with the same nonce and higher fees so it stops holding up the
+// nonces after it; speed-ups and cancellations asked for through the API
+// are sent the same way. The withdrawal or sweep of a transaction is
+// settled with whichever attempt was mined once its block is
+// TxConfirmations deep, and the transaction is followed again when a reorg
+// drops that block first.
+type Tracker struct {
+	repo        *repositories.OutgoingTxRepo
+	withdrawals *repositories.WithdrawalRepo
+	sweeps      *repositories.SweepRepo
+	chains      *blockchain.ChainFactory
+	interval    time.Duration
+	cancel      context.CancelFunc
+	wg          sync.WaitGroup
+}
+
+func NewTracker(repo *repositories.OutgoingTxRepo, withdrawals *repositories.WithdrawalRepo, sweeps *repositories.SweepRepo, chains *blockchain.ChainFactory, interval time.Duration) *Tracker {
+	if interval <= 0 {
+		interval = constants.TX_TRACK_INTERVAL
+	}
+	return &Tracker{repo: repo, withdrawals: withdrawals, sweeps: sweeps, chains: chains, interval: interval}
+}
+
+func (t *Tracker) Start(ctx context.Context) {
+	ctx, t.cancel = context.WithCancel(ctx)
+
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+
+		ticker := time.NewTicker(t.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				t.tick(ctx)
+			}
+		}
+	}()
+}
+
+func (t *Tracker) Stop() {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+}
+
+func (t *Tracker) tick(ctx context.Context) {
+	inFlight, err := t.repo.InFlight(ctx, trackBatchSize)
+	if err != nil {
+		log.Printf("[tracker] in-flight lookup failed: %v\n", err)
+		return
+	}
+
+	heads := make(map[string]uint64)
+	for i := range inFlight {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := t.check(ctx, &inFlight[i], heads); err != nil {
+			log.Printf("[tracker] %s %s: %v\n", inFlight[i].Chain, inFlight[i].TxHash, err)
+		}
+	}
+
+	mined, err := t.repo.Unsettled(ctx, trackBatchSize)
+	if err != nil {
+		log.Printf("[tracker] unsettled lookup failed: %v\n", err)
+		return
+	}
+	for i := range mined {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := t.finalize(ctx, &mined[i], heads); err != nil {
+			log.Printf("[tracker] %s %s: %v\n", mined[i].Chain, mined[i].TxHash, err)
+		}
+	}
+}
+
+// head is the latest block of the chain, looked up once per tick.
+func (t *Tracker) head(ctx context.Context, chain blockchain.Chain, heads map[string]uint64) (uint64, error) {
+	if head, ok := heads[chain.Name()]; ok {
+		return head, nil
+	}
+	head, err := chain.BlockNumber(ctx)
+	if err != nil {
+		return 0, err
+	}
+	heads[chain.Name()] = head
+	return head, nil
+}
+
+// finalize settles the withdrawal or sweep of a mined attempt once its
+// block is deep enough and the attempt is still in it.
+func (t *Tracker) finalize(ctx context.Context, mined *models.OutgoingTx, heads map[string]uint64) error {
+	chain, err := t.chains.GetChain(mined.Chain)
+	if err != nil {
+		return err
+	}
+	head, err := t.head(ctx, chain, heads)
+	if err != nil {
+		return err
+	}
+	if mined.MinedBlock == nil || head+1 < *mined.MinedBlock+constants.TxConfirmations(mined.ChainID) {
+		return nil
+	}
+
+	inclusion, err := chain.TxInclusion(ctx, mined.TxHash)
+	if err != nil {
+		return err
+	}
+	if inclusion == nil || inclusion.BlockNumber != *mined.MinedBlock {
+		log.Printf("[tracker] %s %s left block %d in a reorg\n", mined.Chain, mined.TxHash, *mined.MinedBlock)
+		return t.repo.Unmined(ctx, mined)
+	}
+
+	if err := t.settle(ctx, mined); err != nil {
+		return err
+	}
+	return t.repo.Settled(ctx, mined.ID)
+}
+
+// check records the attempt of latest's transaction that was mined, and
+// otherwise replaces latest when it is stuck or a speed-up or cancellation
+// was asked for.
+func (t *Tracker) check(ctx context.Context, latest *models.OutgoingTx, heads map[string]uint64) error {
+	chain, err := t.chains.GetChain(latest.Chain)
+	if err != nil {
+		return err
+	}
+
+	attempts, err := t.repo.Attempts(ctx, latest.RootID)
+	if err != nil {
+		return err
+	}
+	if mined, err := t.recordMined(ctx, chain, attempts); err != nil || mined {
+		return err
+	}
+	if !latest.Replaceable() {
+		return nil
+	}
+
+	head, err := t.head(ctx, chain, heads)
+	if err != nil {
+		return err
+	}
+
+	stuck := head >= latest.BroadcastBlock+constants.TxStuckAfter(latest.ChainID) &&
+		len(attempts)-1 < constants.TX_MAX_REPLACEMENTS
+	if latest.Action == nil && !stuck {
+		return nil
+	}
+	cancel := latest.Action != nil && *latest.Action == constants.OUTGOING_ACTION_CANCEL
+
+	wallet, err := chain.CreateHDWallet(ctx, int(latest.HDAccountID), int(latest.HDAddressId))
+	if err != nil {
+		return err
+	}
+	if !strings.EqualFold(wallet.Address, latest.FromAddress) {
+		return errors.New("derived key does not match sender " + latest.FromAddress)
+	}
+
+	result, err := chain.ReplaceTx(ctx, *wallet, sentTx(latest), cancel)
+	if errors.Is(err, blockchain.ErrNonceUsed) {
+		// One of the attempts may have been mined since it was looked up.
+		if mined, err := t.recordMined(ctx, chain, attempts); err != nil || mined {
+			return err
+		}
+		log.Printf("[tracker] %s %s: nonce %d taken by another transaction, giving up\n", latest.Chain, latest.TxHash, latest.Nonce)
+		if err := t.repo.Dropped(ctx, latest.RootID); err != nil {
+			return err
+		}
+		return t.abandon(ctx, latest)
+	}
+	if err != nil && (result == nil || !errors.Is(err, blockchain.ErrSendUnknown)) {
+		return err
+	}
+
+	// A replacement whose broadcast is unknown is followed like one that
+	// left, it may be the attempt that gets mined.
+	if _, err := t.repo.Replaced(ctx, latest, result, head, cancel); err != nil {
+		return err
+	}
+	log.Printf("[tracker] %s %s replaced by %s\n", latest.Chain, latest.TxHash, result.TxHash)
+	return err
+}
+
+// recordMined records the attempt that was mined, if any.
+func (t *Tracker) recordMined(ctx context.Context, chain blockchain.Chain, attempts []models.OutgoingTx) (bool, error) {
+	for i := range attempts {
+		inclusion, err := chain.TxInclusion(ctx, attempts[i].TxHash)
+		if err != nil {
+			return false, err
+		}
+		if inclusion != nil {
+			return true, t.repo.Mined(ctx, &attempts[i], inclusion)
+		}
+	}
+	return false, nil
+}
+
+// abandon handles a transaction whose nonce another transaction took
+// while none of its attempts was mined. The withdrawal is flagged for
+// manual review, it may have been paid by a transaction the gateway did
+// not record. Sweeps and gas top-ups are failed, the deposits are swept
+// again from what is left on chain.
+func (t *Tracker) abandon(ctx context.Context, latest *models.OutgoingTx) error {
+	reason := fmt.Sprintf("nonce %d of %s taken by another transaction", latest.Nonce, latest.TxHash)
+	switch latest.Kind {
+	case constants.OUTGOING_KIND_WITHDRAWAL:
+		return t.withdrawals.FlagForReview(ctx, latest.ReferenceID, reason)
+	case constants.OUTGOING_KIND_SWEEP, constants.OUTGOING_KIND_GAS_TOPUP:
+		return t.sweeps.MarkFailed(ctx, latest.ReferenceID, reason)
+	}
+	return nil
+}
+
+// settle confirms the withdrawal, sweep or gas top-up of the mined attempt
+// with it, or fails it when the attempt reverted or was a cancellation.
+func (t *Tracker) settle(ctx context.Context, mined *models.OutgoingTx) error {
+	switch mined.Kind {
+	case constants.OUTGOING_KIND_WITHDRAWAL:
+		if mined.Cancel {
+			return t.withdrawals.MarkFailed(ctx, mined.ReferenceID, "cancelled on chain")
+		}
+		if mined.Status == constants.OUTGOING_STATUS_FAILED {
+			return t.withdrawals.MarkFailed(ctx, mined.ReferenceID, "transaction reverted on chain")
+		}
+		return t.withdrawals.Confirm(ctx, mined.ReferenceID, mined.TxHash)
+	case constants.OUTGOING_KIND_SWEEP:
+		if mined.Cancel {
+			return t.sweeps.MarkFailed(ctx, mined.ReferenceID, "cancelled on chain")
+		}
+		if mined.Status == constants.OUTGOING_STATUS_FAILED {
+			return t.sweeps.MarkFailed(ctx, mined.ReferenceID, "transaction reverted on chain")
+		}
+		return t.sweeps.Confirm(ctx, mined.ReferenceID, mined.TxHash)
+	case constants.OUTGOING_KIND_GAS_TOPUP:
+		if mined.Cancel {
+			return t.sweeps.MarkFailed(ctx, mined.ReferenceID, "gas top-up cancelled on chain")
+		}
+		if mined.Status == constants.OUTGOING_STATUS_FAILED {
+			return t.sweeps.MarkFailed(ctx, mined.ReferenceID, "gas top-up reverted on chain")
+		}
+		return t.sweeps.MarkFunded(ctx, mined.ReferenceID, mined.TxHash)
+	}
+	return nil
+}
+
+func sentTx(attempt *models.OutgoingTx) blockchain.SentTx {
+	sent := blockchain.SentTx{
+		From:     attempt.FromAddress,
+		To:       attempt.ToAddress,
+		Nonce:    attempt.Nonce,
+		Value:    new(big.Int),
+		Data:     attempt.Data,
+		GasLimit: attempt.GasLimit,
+		GasPrice: new(big.Int),
+	}
+	sent.Value.SetString(attempt.Value, 10)
+	sent.GasPrice.SetString(attempt.GasPrice, 10)
+	if attempt.GasTipCap != nil {
+		sent.GasTipCap, _ = new(big.Int).SetString(*attempt.GasTipCap, 10)
+	}
+	return sent
+}
@@ -0,0 +1,171 @@
+package withdrawals
+
+import (
+	"context"
+	"core/blockchain"
+	"core/constants"
+	"core/models"
+	"core/repositories"
+	"errors"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultProcessInterval = 10 * time.Second
+	claimBatchSize         = 20
+)
+
+// Sender signs and broadcasts a withdrawal from the hot wallet. Errors of a
+// withdrawal that was signed and may have been broadcast wrap
+// blockchain.ErrSendUnknown, any other error means nothing was sent.
+type Sender interface {
+	Send(ctx context.Context, withdrawal *models.Withdrawal) (*blockchain.TransactionResult, error)
+}
+
+// ChainSender pays withdrawals from the gateway hot wallet through the
+// chain implementation.
+type ChainSender struct {
+	chains *blockchain.ChainFactory
+}
+
+func NewChainSender(chains *blockchain.ChainFactory) *ChainSender {
+	return &ChainSender{chains: chains}
+}
+
+func (s *ChainSender) Send(ctx context.Context, withdrawal *models.Withdrawal) (*blockchain.TransactionResult, error) {
+	chain, err := s.chains.GetChain(withdrawal.Chain)
+	if err != nil {
+		return nil, err
+	}
+	if !chain.CanSend() {
+		return nil, errors.New("withdrawals are not supported on " + withdrawal.Chain)
+	}
+
+	amount, ok := new(big.Int).SetString(withdrawal.Amount, 10)
+	if !ok {
+		return nil, errors.New("invalid withdrawal amount " + withdrawal.Amount)
+	}
+
+	hotWallet, err := chain.CreateHDWallet(ctx, constants.HOT_WALLET_HD_ACCOUNT, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := chain.Withdraw(ctx, *hotWallet, amount, withdrawal.Token, withdrawal.Destination)
+	if err != nil {
+		return result, err
+	}
+	if !result.Success {
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		return nil, errors.New("withdrawal not accepted by the chain")
+	}
+	return result, nil
+}
+
+// Processor signs approved withdrawals and hands their transactions to the
+// tracker, which settles them once mined deep enough.
+type Processor struct {
+	repo     *repositories.WithdrawalRepo
+	outgoing *repositories.OutgoingTxRepo
+	sender   Sender
+	interval time.Duration
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+func NewProcessor(repo *repositories.WithdrawalRepo, outgoing *repositories.OutgoingTxRepo, sender Sender, interval time.Duration) *Processor {
+	if interval <= 0 {
+		interval = DefaultProcessInterval
+	}
+	return &Processor{repo: repo, outgoing: outgoing, sender: sender, interval: interval}
+}
+
+func (p *Processor) Start(ctx context.Context) {
+	ctx, p.cancel = context.WithCancel(ctx)
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+
+		ticker := time.NewTicker(p.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				p.tick(ctx)
+			}
+		}
+	}()
+}
+
+func (p *Processor) Stop() {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	p.wg.Wait()
+}
+
+func (p *Processor) tick(ctx context.Context) {
+	claimed, err := p.repo.ClaimApproved(ctx, claimBatchSize)
+	if err != nil {
+		log.Printf("[withdrawals] claim failed: %v\n", err)
+	}
+
+	for i := range claimed {
+		withdrawal := &claimed[i]
+
+		result, err := p.sender.Send(ctx, withdrawal)
+		if errors.Is(err, blockchain.ErrSendUnknown) {
+			// Failing it would release the held funds of a payout that
+			// may be on chain. A signed transaction is followed like any
+			// broadcast one: the tracker sees it mined, or replaces it so
+			// its nonce does not hold up the ones after it.
+			log.Printf("[withdrawals] %s broadcast unknown: %v\n", withdrawal.ID, err)
+			if err := p.repo.FlagForReview(ctx, withdrawal.ID, err.Error()); err != nil {
+				log.Printf("[withdrawals] %s flag failed: %v\n", withdrawal.ID, err)
+			}
+			if result != nil {
+				p.broadcast(ctx, withdrawal, result)
+			}
+			continue
+		}
+		if err != nil {
+			log.Printf("[withdrawals] %s send failed: %v\n", withdrawal.ID, err)
+			if err := p.repo.MarkFailed(ctx, withdrawal.ID, err.Error()); err != nil {
+				log.Printf("[withdrawals] %s mark failed: %v\n", withdrawal.ID, err)
+			}
+			continue
+		}
+
+		p.broadcast(ctx, withdrawal, result)
+	}
+}
+
+// broadcast records the transaction sent for the withdrawal and has the
+// tracker follow it. A withdrawal whose transaction could not be recorded
+// stays in signing, flagged for review: it is paid on chain but nothing
+// would ever settle it.
+func (p *Processor) broadcast(ctx context.Context, withdrawal *models.Withdrawal, result *blockchain.TransactionResult) {
+	attempt, err := p.outgoing.Attempt(ctx, withdrawal.Chain, constants.OUTGOING_KIND_WITHDRAWAL, withdrawal.ID,
+		constants.HOT_WALLET_HD_ACCOUNT, 0, result)
+	if err == nil {
+		err = p.repo.MarkBroadcast(ctx, withdrawal.ID, attempt)
+	}
+	if err == nil {
+		return
+	}
+
+	log.Printf("[withdrawals] %s broadcast %s not recorded: %v\n", withdrawal.ID, result.TxHash, err)
+	reason := "broadcast " + result.TxHash + " not recorded: " + err.Error()
+	if err := p.repo.FlagForReview(ctx, withdrawal.ID, reason); err != nil {
+		log.Printf("[withdrawals] %s flag failed: %v\n", withdrawal.ID, err)
+	}
+}